@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/home-assistant/hab/config"
+)
+
+// ContextIndex lists the named contexts and the one in use.
+// It is stored unencrypted; credentials live in each context's directory.
+type ContextIndex struct {
+	Current  string                  `json:"current,omitempty"`
+	Contexts map[string]*ContextInfo `json:"contexts,omitempty"`
+}
+
+// ContextInfo is the public information kept about a context
+type ContextInfo struct {
+	URL string `json:"url,omitempty"`
+	ContextTransport
+}
+
+// ContextTransport holds the TLS and proxy settings of a context's instance.
+// Flags and environment variables override them.
+type ContextTransport struct {
+	CAFile     string `json:"ca-file,omitempty"`
+	ClientCert string `json:"client-cert,omitempty"`
+	ClientKey  string `json:"client-key,omitempty"`
+	Insecure   bool   `json:"insecure,omitempty"`
+	Proxy      string `json:"proxy,omitempty"`
+}
+
+// ContextSummary describes a context for listing
+type ContextSummary struct {
+	Name    string `json:"name"`
+	URL     string `json:"url,omitempty"`
+	Current bool   `json:"current"`
+	// Authenticated is true when the context has stored credentials
+	Authenticated bool `json:"authenticated"`
+}
+
+var contextNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateContextName returns an error if name cannot be used as a context name
+func ValidateContextName(name string) error {
+	if !contextNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid context name %q (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// LoadContextIndex reads the contexts file; a missing file gives an empty index
+func LoadContextIndex(configDir string) (*ContextIndex, error) {
+	idx := &ContextIndex{Contexts: make(map[string]*ContextInfo)}
+
+	data, err := os.ReadFile(config.GetContextsPath(configDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return idx, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("invalid contexts file: %w", err)
+	}
+	if idx.Contexts == nil {
+		idx.Contexts = make(map[string]*ContextInfo)
+	}
+	return idx, nil
+}
+
+// Save writes the contexts file
+func (idx *ContextIndex) Save(configDir string) error {
+	if err := config.EnsureConfigDir(configDir); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(config.GetContextsPath(configDir), data, 0600)
+}
+
+// exists reports whether a context is known. The default context always exists.
+func (idx *ContextIndex) exists(name string) bool {
+	_, ok := idx.Contexts[name]
+	return ok || name == config.DefaultContext
+}
+
+// ResolveContext returns the context to use: name when set (from --context
+// or HAB_CONTEXT), else the current context, else the default context.
+// A name that is set must be a known context unless allowNew is true; a
+// typo would otherwise silently use a new, empty context.
+func ResolveContext(configDir, name string, allowNew bool) (string, error) {
+	idx, err := LoadContextIndex(configDir)
+	if name == "" {
+		if err != nil || idx.Current == "" {
+			return config.DefaultContext, nil
+		}
+		name = idx.Current
+	} else if err != nil {
+		return "", err
+	}
+	// The name becomes a directory, so it must not be able to leave the config directory
+	if err := ValidateContextName(name); err != nil {
+		return "", err
+	}
+	if !allowNew && !idx.exists(name) {
+		return "", fmt.Errorf("context %q not found (create it with 'hab context add %s')", name, name)
+	}
+	return name, nil
+}
+
+// AddContext registers a new context without credentials
+func AddContext(configDir, name string) error {
+	if err := ValidateContextName(name); err != nil {
+		return err
+	}
+	idx, err := LoadContextIndex(configDir)
+	if err != nil {
+		return err
+	}
+	if idx.exists(name) {
+		return fmt.Errorf("context %q already exists", name)
+	}
+	idx.Contexts[name] = &ContextInfo{}
+	return idx.Save(configDir)
+}
+
+// UseContext makes name the current context
+func UseContext(configDir, name string) error {
+	idx, err := LoadContextIndex(configDir)
+	if err != nil {
+		return err
+	}
+	if !idx.exists(name) {
+		return fmt.Errorf("context %q not found", name)
+	}
+	idx.Current = name
+	return idx.Save(configDir)
+}
+
+// RenameContext renames a context and moves its credentials within store
+func RenameContext(configDir string, store CredentialStore, oldName, newName string) error {
+	if err := ValidateContextName(newName); err != nil {
+		return err
+	}
+	idx, err := LoadContextIndex(configDir)
+	if err != nil {
+		return err
+	}
+	if !idx.exists(oldName) {
+		return fmt.Errorf("context %q not found", oldName)
+	}
+	if idx.exists(newName) {
+		return fmt.Errorf("context %q already exists", newName)
+	}
+
+	creds, err := store.Get(oldName)
+	if err != nil {
+		return fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if creds != nil {
+		if err := store.Store(newName, creds); err != nil {
+			return fmt.Errorf("failed to move credentials: %w", err)
+		}
+		if err := store.Erase(oldName); err != nil {
+			return fmt.Errorf("failed to move credentials: %w", err)
+		}
+	}
+	removeContextDir(configDir, oldName)
+
+	info := idx.Contexts[oldName]
+	if info == nil {
+		info = &ContextInfo{}
+	}
+	delete(idx.Contexts, oldName)
+	idx.Contexts[newName] = info
+	if idx.Current == oldName {
+		idx.Current = newName
+	}
+	return idx.Save(configDir)
+}
+
+// DeleteContext removes a context and its credentials in store
+func DeleteContext(configDir string, store CredentialStore, name string) error {
+	idx, err := LoadContextIndex(configDir)
+	if err != nil {
+		return err
+	}
+	if !idx.exists(name) {
+		return fmt.Errorf("context %q not found", name)
+	}
+
+	if err := store.Erase(name); err != nil {
+		return fmt.Errorf("failed to delete credentials: %w", err)
+	}
+	removeContextDir(configDir, name)
+
+	delete(idx.Contexts, name)
+	if idx.Current == name {
+		idx.Current = ""
+	}
+	return idx.Save(configDir)
+}
+
+// removeContextDir removes the directory of a named context if it is empty
+func removeContextDir(configDir, name string) {
+	if name != config.DefaultContext {
+		os.Remove(config.GetContextDir(configDir, name))
+	}
+}
+
+// ListContexts returns all contexts sorted by name. The default context is
+// included when it is current or has credentials in store.
+func ListContexts(configDir string, store CredentialStore, current string) ([]ContextSummary, error) {
+	idx, err := LoadContextIndex(configDir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(idx.Contexts)+1)
+	for name := range idx.Contexts {
+		names = append(names, name)
+	}
+	stored := make(map[string]*Credentials)
+	lookup := func(name string) *Credentials {
+		if _, ok := stored[name]; !ok {
+			stored[name], _ = store.Get(name)
+		}
+		return stored[name]
+	}
+	if _, ok := idx.Contexts[config.DefaultContext]; !ok {
+		if current == config.DefaultContext || lookup(config.DefaultContext) != nil {
+			names = append(names, config.DefaultContext)
+		}
+	}
+	sort.Strings(names)
+
+	contexts := make([]ContextSummary, 0, len(names))
+	for _, name := range names {
+		summary := ContextSummary{Name: name, Current: name == current}
+		if info := idx.Contexts[name]; info != nil {
+			summary.URL = info.URL
+		}
+		if creds := lookup(name); creds != nil {
+			summary.Authenticated = true
+			// Contexts saved before they were indexed have no URL yet
+			if summary.URL == "" {
+				summary.URL = creds.URL
+			}
+		}
+		contexts = append(contexts, summary)
+	}
+	return contexts, nil
+}
+
+// recordContextURL stores the URL of a context in the index
+func recordContextURL(configDir, name, url string) error {
+	idx, err := LoadContextIndex(configDir)
+	if err != nil {
+		return err
+	}
+	info := idx.Contexts[name]
+	if info == nil {
+		info = &ContextInfo{}
+		idx.Contexts[name] = info
+	} else if info.URL == url {
+		return nil
+	}
+	info.URL = url
+	return idx.Save(configDir)
+}
+
+// GetContextTransport returns the TLS and proxy settings stored for a
+// context; they are empty for a context without settings
+func GetContextTransport(configDir, name string) (ContextTransport, error) {
+	idx, err := LoadContextIndex(configDir)
+	if err != nil {
+		return ContextTransport{}, err
+	}
+	if info := idx.Contexts[name]; info != nil {
+		return info.ContextTransport, nil
+	}
+	return ContextTransport{}, nil
+}
+
+// SetContextTransport stores the TLS and proxy settings of a context
+func SetContextTransport(configDir, name string, transport ContextTransport) error {
+	idx, err := LoadContextIndex(configDir)
+	if err != nil {
+		return err
+	}
+	if !idx.exists(name) {
+		return fmt.Errorf("context %q not found", name)
+	}
+	info := idx.Contexts[name]
+	if info == nil {
+		info = &ContextInfo{}
+		idx.Contexts[name] = info
+	}
+	info.ContextTransport = transport
+	return idx.Save(configDir)
+}
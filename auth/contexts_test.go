@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveContext(t *testing.T) {
+	configDir := t.TempDir()
+	if err := AddContext(configDir, "staging"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		context  string
+		allowNew bool
+		want     string
+		wantErr  string
+	}{
+		{name: "default", context: "", want: "default"},
+		{name: "known", context: "staging", want: "staging"},
+		{name: "default by name", context: "default", want: "default"},
+		{name: "unknown", context: "stagign", wantErr: `context "stagign" not found`},
+		{name: "unknown allowed", context: "production", allowNew: true, want: "production"},
+		{name: "parent directory", context: "../../etc", allowNew: true, wantErr: "invalid context name"},
+		{name: "path separator", context: "a/b", allowNew: true, wantErr: "invalid context name"},
+		{name: "dot", context: ".", allowNew: true, wantErr: "invalid context name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveContext(configDir, tt.context, tt.allowNew)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("context = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveContextCurrent(t *testing.T) {
+	configDir := t.TempDir()
+	if err := AddContext(configDir, "staging"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseContext(configDir, "staging"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ResolveContext(configDir, "", false); err != nil || got != "staging" {
+		t.Errorf("context = %q, %v, want staging", got, err)
+	}
+
+	// A current context edited into the index is checked like a flag
+	idx, _ := LoadContextIndex(configDir)
+	idx.Current = "../outside"
+	if err := idx.Save(configDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ResolveContext(configDir, "", false); err == nil {
+		t.Error("expected an error for an invalid current context")
+	}
+}
@@ -1,11 +1,10 @@
 package auth
 
 import (
-	"encoding/json"
 	"os"
 	"time"
 
-	"github.com/home-assistant/hab/config"
+	"github.com/home-assistant/hab/client"
 )
 
 // Credentials stores authentication information
@@ -22,6 +21,11 @@ func (c *Credentials) IsOAuth() bool {
 	return c.RefreshToken != ""
 }
 
+// IsSupervisor returns true if using the Supervisor token of an add-on
+func (c *Credentials) IsSupervisor() bool {
+	return client.IsSupervisorURL(c.URL) && c.AccessToken == client.SupervisorToken()
+}
+
 // HasValidToken returns true if there is an access token
 func (c *Credentials) HasValidToken() bool {
 	return c.AccessToken != ""
@@ -38,21 +42,64 @@ func (c *Credentials) IsExpired() bool {
 	return time.Now().Unix() >= int64(c.TokenExpiry)
 }
 
+// refreshMargin is how long before expiry OAuth tokens are refreshed
+const refreshMargin = 5 * time.Minute
+
 // NeedsRefresh returns true if the token needs to be refreshed
 func (c *Credentials) NeedsRefresh() bool {
-	if !c.IsOAuth() {
-		return false
+	return c.IsOAuth() && c.RefreshIn() == 0
+}
+
+// RefreshIn returns how long until an OAuth token should be refreshed, or 0
+// if it should be refreshed now
+func (c *Credentials) RefreshIn() time.Duration {
+	if !c.IsOAuth() || c.TokenExpiry == 0 {
+		return 0
 	}
-	if c.TokenExpiry == 0 {
-		return true
+	// Refresh within 5 minutes of expiry
+	refreshAt := time.Unix(int64(c.TokenExpiry), 0).Add(-refreshMargin)
+	if wait := time.Until(refreshAt); wait > 0 {
+		return wait
 	}
-	// Refresh if within 5 minutes of expiry
-	return float64(time.Now().Unix()) >= (c.TokenExpiry - 300)
+	return 0
 }
 
-// LoadCredentials loads credentials from storage
-func LoadCredentials(configDir string) (*Credentials, error) {
-	// First check environment variables
+// LoadCredentials loads the credentials of a context from the environment
+// or the encrypted file store. An empty context is the default context.
+func LoadCredentials(configDir, context string) (*Credentials, error) {
+	return LoadCredentialsFrom(&FileStore{ConfigDir: configDir}, context)
+}
+
+// LoadCredentialsFrom loads the credentials of a context from the environment
+// or store. Credentials in the environment take precedence over the store.
+// Without either, the Supervisor token is used when running in an add-on.
+func LoadCredentialsFrom(store CredentialStore, context string) (*Credentials, error) {
+	if creds := credentialsFromEnv(); creds != nil {
+		return creds, nil
+	}
+	creds, err := store.Get(context)
+	if err != nil || creds != nil {
+		return creds, err
+	}
+	return supervisorCredentials(), nil
+}
+
+// supervisorCredentials returns credentials for Core behind the Supervisor
+// proxy when SUPERVISOR_TOKEN is set, or nil otherwise
+func supervisorCredentials() *Credentials {
+	token := client.SupervisorToken()
+	if token == "" {
+		return nil
+	}
+	return &Credentials{
+		URL:         client.SupervisorURL,
+		AccessToken: token,
+	}
+}
+
+// credentialsFromEnv returns the credentials set by HAB_URL with HAB_TOKEN
+// or HAB_REFRESH_TOKEN, or nil when they are not set
+func credentialsFromEnv() *Credentials {
 	envURL := os.Getenv("HAB_URL")
 	envToken := os.Getenv("HAB_TOKEN")
 
@@ -60,7 +107,7 @@ func LoadCredentials(configDir string) (*Credentials, error) {
 		return &Credentials{
 			URL:         envURL,
 			AccessToken: envToken,
-		}, nil
+		}
 	}
 
 	// Check for refresh token in environment
@@ -69,65 +116,8 @@ func LoadCredentials(configDir string) (*Credentials, error) {
 		return &Credentials{
 			URL:          envURL,
 			RefreshToken: envRefresh,
-		}, nil
-	}
-
-	// Load from encrypted file
-	credsPath := config.GetCredentialsPath(configDir)
-
-	data, err := os.ReadFile(credsPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
 		}
-		return nil, err
-	}
-
-	// Decrypt
-	key := deriveKey()
-	decrypted, err := decrypt(data, key)
-	if err != nil {
-		return nil, err
-	}
-
-	var creds Credentials
-	if err := json.Unmarshal(decrypted, &creds); err != nil {
-		return nil, err
-	}
-
-	return &creds, nil
-}
-
-// SaveCredentials saves credentials to encrypted storage
-func SaveCredentials(creds *Credentials, configDir string) error {
-	if err := config.EnsureConfigDir(configDir); err != nil {
-		return err
-	}
-
-	data, err := json.Marshal(creds)
-	if err != nil {
-		return err
-	}
-
-	key := deriveKey()
-	encrypted, err := encrypt(data, key)
-	if err != nil {
-		return err
-	}
-
-	credsPath := config.GetCredentialsPath(configDir)
-	if err := os.WriteFile(credsPath, encrypted, 0600); err != nil {
-		return err
 	}
 
 	return nil
 }
-
-// DeleteCredentials removes stored credentials
-func DeleteCredentials(configDir string) bool {
-	credsPath := config.GetCredentialsPath(configDir)
-	if err := os.Remove(credsPath); err != nil {
-		return false
-	}
-	return true
-}
@@ -11,7 +11,7 @@ import (
 	"github.com/grandcat/zeroconf"
 )
 
-// DiscoveredServer represents a Home Assistant server found via mDNS
+// DiscoveredServer represents a Home Assistant server found via mDNS or probing
 type DiscoveredServer struct {
 	Name     string   // Instance name (e.g., "Home" or location name)
 	Host     string   // Hostname
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
@@ -13,6 +14,8 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+
+	"golang.org/x/crypto/scrypt"
 )
 
 // getMachineID returns a machine-specific identifier for key derivation
@@ -152,3 +155,149 @@ func decrypt(ciphertext, key []byte) ([]byte, error) {
 
 	return plaintext, nil
 }
+
+// Encrypted credential files start with a header naming the format version
+// and the key derivation, so the key can be derived again on any machine:
+//
+//	"HABC" | version | kdf | kdf parameters | nonce | ciphertext
+//
+// Files written before the header was introduced hold only the nonce and
+// ciphertext and are encrypted with the machine key.
+const (
+	sealMagic   = "HABC"
+	sealVersion = 1
+
+	// kdfMachine derives the key from machine identifiers (deriveKey)
+	kdfMachine byte = 1
+	// kdfScrypt derives the key from a passphrase; its parameters are
+	// log2(N), r, p and a 16-byte salt
+	kdfScrypt byte = 2
+
+	scryptLogN     = 15
+	scryptR        = 8
+	scryptP        = 1
+	scryptSaltSize = 16
+
+	// Parameters read from a file are bounded, since a crafted file could
+	// otherwise make scrypt allocate more memory than the machine has
+	scryptMaxLogN   = 20
+	scryptMaxR      = 32
+	scryptMaxP      = 16
+	scryptMaxMemory = 1 << 30
+)
+
+var (
+	// ErrPassphraseRequired is returned when reading passphrase-protected
+	// credentials without a passphrase
+	ErrPassphraseRequired = errors.New("credentials are protected by a passphrase")
+	// ErrWrongPassphrase is returned when a passphrase does not decrypt the credentials
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+)
+
+// PassphraseFunc returns the passphrase protecting credentials. It is called
+// only when a passphrase is needed, so it may prompt for one.
+type PassphraseFunc func() (string, error)
+
+// scryptKey derives an AES-256 key from a passphrase
+func scryptKey(passphrase string, salt []byte, logN, r, p int) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<logN, r, p, 32)
+}
+
+// checkScryptParams returns an error if scrypt parameters read from a
+// credentials file are out of range
+func checkScryptParams(logN, r, p int) error {
+	if logN < 1 || logN > scryptMaxLogN || r < 1 || r > scryptMaxR || p < 1 || p > scryptMaxP ||
+		128*r*(1<<logN) > scryptMaxMemory {
+		return fmt.Errorf("unsupported scrypt parameters N=2^%d, r=%d, p=%d in credentials file", logN, r, p)
+	}
+	return nil
+}
+
+// seal encrypts data behind a versioned header, with a key derived from
+// passphrase or, when passphrase is empty, with the machine key
+func seal(data []byte, passphrase string) ([]byte, error) {
+	header := []byte(sealMagic)
+	header = append(header, sealVersion)
+
+	var key []byte
+	if passphrase == "" {
+		header = append(header, kdfMachine)
+		key = deriveKey()
+	} else {
+		salt := make([]byte, scryptSaltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		var err error
+		key, err = scryptKey(passphrase, salt, scryptLogN, scryptR, scryptP)
+		if err != nil {
+			return nil, err
+		}
+		header = append(header, kdfScrypt, scryptLogN, scryptR, scryptP)
+		header = append(header, salt...)
+	}
+
+	ciphertext, err := encrypt(data, key)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, ciphertext...), nil
+}
+
+// unseal decrypts data written by seal or by earlier versions. passphrase
+// is called only for passphrase-protected data and may be nil.
+func unseal(data []byte, passphrase PassphraseFunc) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(sealMagic)) {
+		return decrypt(data, deriveKey())
+	}
+	rest := data[len(sealMagic):]
+	if len(rest) < 2 {
+		return nil, errors.New("credentials file is truncated")
+	}
+	if rest[0] != sealVersion {
+		return nil, fmt.Errorf("unsupported credentials format version %d", rest[0])
+	}
+
+	switch kdf, rest := rest[1], rest[2:]; kdf {
+	case kdfMachine:
+		return decrypt(rest, deriveKey())
+
+	case kdfScrypt:
+		if len(rest) < 3+scryptSaltSize {
+			return nil, errors.New("credentials file is truncated")
+		}
+		logN, r, p := int(rest[0]), int(rest[1]), int(rest[2])
+		if err := checkScryptParams(logN, r, p); err != nil {
+			return nil, err
+		}
+		salt, ciphertext := rest[3:3+scryptSaltSize], rest[3+scryptSaltSize:]
+		if passphrase == nil {
+			return nil, ErrPassphraseRequired
+		}
+		pass, err := passphrase()
+		if err != nil {
+			return nil, err
+		}
+		if pass == "" {
+			return nil, ErrPassphraseRequired
+		}
+		key, err := scryptKey(pass, salt, logN, r, p)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := decrypt(ciphertext, key)
+		if err != nil {
+			return nil, ErrWrongPassphrase
+		}
+		return plaintext, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key derivation %d in credentials file", kdf)
+	}
+}
+
+// isPassphraseProtected reports whether data was sealed with a passphrase
+func isPassphraseProtected(data []byte) bool {
+	n := len(sealMagic)
+	return bytes.HasPrefix(data, []byte(sealMagic)) && len(data) > n+1 && data[n+1] == kdfScrypt
+}
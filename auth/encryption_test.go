@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// passphrase returns a PassphraseFunc answering pass
+func passphrase(pass string) PassphraseFunc {
+	return func() (string, error) { return pass, nil }
+}
+
+func TestSealUnseal(t *testing.T) {
+	plaintext := []byte(`{"access_token": "secret"}`)
+	machine, err := seal(plaintext, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	protected, err := seal(plaintext, "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Files written before the header existed hold only nonce and ciphertext
+	legacy, err := encrypt(plaintext, deriveKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase PassphraseFunc
+		protected  bool
+		wantErr    error
+		errText    string
+	}{
+		{name: "machine key", data: machine},
+		{name: "machine key ignores passphrase", data: machine, passphrase: passphrase("unused")},
+		{name: "passphrase", data: protected, passphrase: passphrase("hunter2"), protected: true},
+		{name: "wrong passphrase", data: protected, passphrase: passphrase("hunter3"), protected: true, wantErr: ErrWrongPassphrase},
+		{name: "no passphrase func", data: protected, protected: true, wantErr: ErrPassphraseRequired},
+		{name: "empty passphrase", data: protected, passphrase: passphrase(""), protected: true, wantErr: ErrPassphraseRequired},
+		{name: "legacy format", data: legacy},
+		{name: "unsupported version", data: []byte(sealMagic + "\x02\x01"), errText: "unsupported credentials format version 2"},
+		{name: "unknown kdf", data: []byte(sealMagic + "\x01\x09"), errText: "unsupported key derivation 9"},
+		{name: "truncated header", data: []byte(sealMagic + "\x01"), errText: "truncated"},
+		{name: "truncated salt", data: protected[:len(sealMagic)+8], protected: true, errText: "truncated"},
+		{name: "truncated ciphertext", data: machine[:len(sealMagic)+4], errText: "ciphertext too short"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPassphraseProtected(tt.data); got != tt.protected {
+				t.Errorf("isPassphraseProtected = %v, want %v", got, tt.protected)
+			}
+			got, err := unseal(tt.data, tt.passphrase)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.errText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Errorf("err = %v, want %q", err, tt.errText)
+				}
+			case err != nil:
+				t.Errorf("unseal: %v", err)
+			case !bytes.Equal(got, plaintext):
+				t.Errorf("unseal = %q, want %q", got, plaintext)
+			}
+		})
+	}
+}
+
+func TestSealUsesFreshSalt(t *testing.T) {
+	a, err := seal([]byte("x"), "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := seal([]byte("x"), "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a, b) {
+		t.Error("sealing twice gave the same output")
+	}
+	if a[len(sealMagic)] != sealVersion || a[len(sealMagic)+1] != kdfScrypt {
+		t.Errorf("header = %v", a[:len(sealMagic)+2])
+	}
+}
+
+func TestUnsealRejectsOversizedScryptParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		logN, r, p byte
+	}{
+		{name: "huge N", logN: 40, r: 8, p: 1},
+		{name: "N above limit", logN: scryptMaxLogN + 1, r: 1, p: 1},
+		{name: "r above limit", logN: 10, r: scryptMaxR + 1, p: 1},
+		{name: "p above limit", logN: 10, r: 8, p: scryptMaxP + 1},
+		{name: "too much memory", logN: scryptMaxLogN, r: scryptMaxR, p: 1},
+		{name: "zero N", logN: 0, r: 8, p: 1},
+		{name: "zero r", logN: 10, r: 0, p: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(sealMagic)
+			data = append(data, sealVersion, kdfScrypt, tt.logN, tt.r, tt.p)
+			data = append(data, make([]byte, scryptSaltSize+32)...)
+
+			_, err := unseal(data, func() (string, error) {
+				t.Fatal("asked for the passphrase of an invalid file")
+				return "", nil
+			})
+			if err == nil || !strings.Contains(err.Error(), "unsupported scrypt parameters") {
+				t.Errorf("err = %v, want unsupported scrypt parameters", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/home-assistant/hab/config"
+)
+
+// exportPrefix marks exported credentials, which are base64 text so they can
+// be pasted between terminals
+const exportPrefix = "hab-credentials:"
+
+// ExportCredentials encrypts credentials with a passphrase for moving them
+// to another machine
+func ExportCredentials(creds *Credentials, passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", ErrPassphraseRequired
+	}
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(data, passphrase)
+	if err != nil {
+		return "", err
+	}
+	return exportPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// ImportCredentials decrypts credentials written by ExportCredentials. A
+// passphrase-protected credentials file is accepted as well.
+func ImportCredentials(data []byte, passphrase PassphraseFunc) (*Credentials, error) {
+	sealed := data
+	if text := strings.TrimSpace(string(data)); strings.HasPrefix(text, exportPrefix) {
+		var err error
+		sealed, err = base64.StdEncoding.DecodeString(strings.TrimPrefix(text, exportPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid exported credentials: %w", err)
+		}
+	}
+	if !isPassphraseProtected(sealed) {
+		return nil, errors.New("not exported hab credentials")
+	}
+
+	plaintext, err := unseal(sealed, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var creds Credentials
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return nil, fmt.Errorf("invalid exported credentials: %w", err)
+	}
+	return &creds, nil
+}
+
+// RotateKey re-encrypts the credentials files of every context. Files are
+// read with the passphrase from current when they need one, and written with
+// newPassphrase, or with the machine key when newPassphrase is empty. All
+// files are decrypted before any is written, so a wrong passphrase changes
+// nothing. It returns the names of the re-encrypted contexts.
+func RotateKey(configDir string, current PassphraseFunc, newPassphrase string) ([]string, error) {
+	idx, err := LoadContextIndex(configDir)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{config.DefaultContext}
+	for name := range idx.Contexts {
+		if name != config.DefaultContext {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	plaintexts := make(map[string][]byte)
+	for _, name := range names {
+		data, err := os.ReadFile(config.GetContextCredentialsPath(configDir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := unseal(data, current)
+		if err != nil {
+			return nil, fmt.Errorf("context %s: %w", name, err)
+		}
+		plaintexts[name] = plaintext
+	}
+
+	rotated := make([]string, 0, len(plaintexts))
+	for _, name := range names {
+		plaintext, ok := plaintexts[name]
+		if !ok {
+			continue
+		}
+		sealed, err := seal(plaintext, newPassphrase)
+		if err != nil {
+			return rotated, err
+		}
+		if err := writeFileAtomic(config.GetContextCredentialsPath(configDir, name), sealed); err != nil {
+			return rotated, fmt.Errorf("context %s: %w", name, err)
+		}
+		rotated = append(rotated, name)
+	}
+	return rotated, nil
+}
+
+// writeFileAtomic replaces a private file so that readers see either the
+// old or the new content
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// HelperPrefix is prepended to a helper name to find its executable
+const HelperPrefix = "hab-credential-"
+
+// HelperStore delegates credential storage to an external program, in the
+// style of git credential helpers. The helper is run as
+//
+//	hab-credential-<name> get|store|erase
+//
+// with a JSON object on stdin holding the "context" and, for store, the
+// credential fields ("url", "access_token", "refresh_token", ...). For get it
+// prints the stored credentials as a JSON object on stdout, or nothing when
+// the context has none. A non-zero exit status is an error, reported with
+// the helper's stderr.
+type HelperStore struct {
+	// Helper is a helper name, or a path to the helper executable when it
+	// contains a slash
+	Helper string
+}
+
+// helperRequest is the JSON object a helper reads on stdin
+type helperRequest struct {
+	Context string `json:"context"`
+	*Credentials
+}
+
+// command returns the executable of the helper
+func (h *HelperStore) command() string {
+	if strings.Contains(h.Helper, "/") {
+		return h.Helper
+	}
+	return HelperPrefix + h.Helper
+}
+
+// run runs the helper with an action and returns what it printed on stdout
+func (h *HelperStore) run(action string, req helperRequest) ([]byte, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(h.command(), action)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("credential helper %s %s failed: %s", h.command(), action, msg)
+		}
+		return nil, fmt.Errorf("credential helper %s %s failed: %w", h.command(), action, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Get asks the helper for the credentials of a context
+func (h *HelperStore) Get(context string) (*Credentials, error) {
+	output, err := h.run("get", helperRequest{Context: context})
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(output, &creds); err != nil {
+		return nil, fmt.Errorf("credential helper %s returned invalid JSON: %w", h.command(), err)
+	}
+	if creds.AccessToken == "" && creds.RefreshToken == "" {
+		return nil, nil
+	}
+	return &creds, nil
+}
+
+// Store passes the credentials of a context to the helper
+func (h *HelperStore) Store(context string, creds *Credentials) error {
+	_, err := h.run("store", helperRequest{Context: context, Credentials: creds})
+	return err
+}
+
+// Erase asks the helper to forget the credentials of a context
+func (h *HelperStore) Erase(context string) error {
+	_, err := h.run("erase", helperRequest{Context: context})
+	return err
+}
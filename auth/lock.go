@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// lockTimeout bounds how long a process waits for another one to finish
+	// refreshing the same credentials
+	lockTimeout = 30 * time.Second
+	lockPoll    = 50 * time.Millisecond
+)
+
+// lockFile takes an exclusive lock on the file at path, creating it if
+// needed, and returns the function releasing it. Locks are advisory and held
+// per open file, so they exclude other processes as well as other managers in
+// this one.
+func lockFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if locked {
+			return func() {
+				unlock(f)
+				f.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("timed out waiting for lock %s", path)
+		}
+		time.Sleep(lockPoll)
+	}
+}
@@ -1,32 +1,78 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/home-assistant/hab/client"
+	"github.com/home-assistant/hab/config"
+	log "github.com/sirupsen/logrus"
 )
 
 // ErrNotAuthenticated is returned when the user is not authenticated
 var ErrNotAuthenticated = errors.New("not authenticated")
 
-// Manager handles authentication state and token refresh
+// refreshRetryDelay is the wait after a failed background token refresh
+const refreshRetryDelay = 30 * time.Second
+
+// Manager handles authentication state and token refresh.
+// It is safe for concurrent use.
 type Manager struct {
-	ConfigDir   string
+	ConfigDir string
+	// Context is the named context whose credentials are managed;
+	// empty means the default context
+	Context string
+	// Store keeps the credentials; nil means the encrypted file store
+	Store       CredentialStore
+	mu          sync.Mutex
 	credentials *Credentials
 }
 
-// NewManager creates a new auth manager
+// NewManager creates a new auth manager for the default context
 func NewManager(configDir string) *Manager {
 	return &Manager{
 		ConfigDir: configDir,
 	}
 }
 
+// NewContextManager creates a new auth manager for a named context
+func NewContextManager(configDir, context string) *Manager {
+	return &Manager{
+		ConfigDir: configDir,
+		Context:   context,
+	}
+}
+
+// contextName returns the name of the managed context
+func (m *Manager) contextName() string {
+	if m.Context == "" {
+		return config.DefaultContext
+	}
+	return m.Context
+}
+
+// store returns the credential store of the manager
+func (m *Manager) store() CredentialStore {
+	if m.Store == nil {
+		return &FileStore{ConfigDir: m.ConfigDir}
+	}
+	return m.Store
+}
+
 // loadCredentials loads credentials without printing warnings (internal use)
 func (m *Manager) loadCredentials() (*Credentials, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.load()
+}
+
+// load loads credentials once; m.mu must be held
+func (m *Manager) load() (*Credentials, error) {
 	if m.credentials == nil {
-		creds, err := LoadCredentials(m.ConfigDir)
+		creds, err := LoadCredentialsFrom(m.store(), m.contextName())
 		if err != nil {
 			return nil, err
 		}
@@ -38,7 +84,10 @@ func (m *Manager) loadCredentials() (*Credentials, error) {
 // GetCredentials returns the current credentials, printing a warning if not authenticated.
 // It automatically refreshes OAuth tokens if they are expired or about to expire.
 func (m *Manager) GetCredentials() (*Credentials, error) {
-	creds, err := m.loadCredentials()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	creds, err := m.load()
 	if err != nil {
 		return nil, err
 	}
@@ -49,21 +98,97 @@ func (m *Manager) GetCredentials() (*Credentials, error) {
 
 	// Automatically refresh OAuth tokens if needed
 	if creds.NeedsRefresh() && creds.RefreshToken != "" {
-		newCreds, err := RefreshAccessToken(creds)
+		newCreds, err := m.refresh(creds, false)
 		if err != nil {
 			return nil, fmt.Errorf("token refresh failed: %w", err)
 		}
-		m.credentials = newCreds
-		if err := SaveCredentials(newCreds, m.ConfigDir); err != nil {
-			// Log warning but continue with refreshed credentials in memory
-			client.PrintWarning("Failed to save refreshed credentials: " + err.Error())
-		}
 		return newCreds, nil
 	}
 
 	return creds, nil
 }
 
+// Token returns a valid access token, refreshing it first when it is about
+// to expire. It is meant as the client.TokenSource of long-lived clients.
+func (m *Manager) Token() (string, error) {
+	creds, err := m.GetCredentials()
+	if err != nil {
+		return "", err
+	}
+	return creds.AccessToken, nil
+}
+
+// KeepFresh refreshes OAuth tokens in the background shortly before they
+// expire, until ctx is done, so long-running sessions always find a valid
+// token. It does nothing for long-lived access tokens or tokens without an
+// expiry, and waits at least refreshRetryDelay between refreshes so tokens
+// that expire within the refresh margin are not refreshed in a tight loop.
+func (m *Manager) KeepFresh(ctx context.Context) {
+	go func() {
+		for {
+			creds, err := m.loadCredentials()
+			if err != nil || creds == nil || !creds.IsOAuth() ||
+				creds.TokenExpiry == 0 || creds.RefreshToken == "" {
+				return
+			}
+
+			wait := creds.RefreshIn()
+			if wait < refreshRetryDelay {
+				wait = refreshRetryDelay
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+
+			if _, err := m.GetCredentials(); err != nil {
+				log.WithError(err).Debug("Background token refresh failed")
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(refreshRetryDelay):
+				}
+			}
+		}
+	}()
+}
+
+// refresh renews the access token of creds and saves the result; m.mu must
+// be held. Concurrent hab processes coordinate through the context's lock
+// file: with the lock held, the stored credentials are read again and used
+// if another process refreshed them in the meantime, so no process saves
+// credentials older than those already stored. force refreshes even then.
+func (m *Manager) refresh(creds *Credentials, force bool) (*Credentials, error) {
+	unlock, err := lockFile(config.GetContextLockPath(m.ConfigDir, m.contextName()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock credentials: %w", err)
+	}
+	defer unlock()
+
+	stored, err := m.store().Get(m.contextName())
+	if err == nil && stored != nil && stored.URL == creds.URL &&
+		stored.AccessToken != creds.AccessToken && !stored.NeedsRefresh() {
+		log.Debug("Using credentials refreshed by another process")
+		if !force {
+			m.credentials = stored
+			return stored, nil
+		}
+		creds = stored
+	}
+
+	newCreds, err := RefreshAccessToken(creds)
+	if err != nil {
+		return nil, err
+	}
+	m.credentials = newCreds
+	if err := m.save(newCreds); err != nil {
+		// Log warning but continue with refreshed credentials in memory
+		client.PrintWarning("Failed to save refreshed credentials: " + err.Error())
+	}
+	return newCreds, nil
+}
+
 // IsAuthenticated returns true if authenticated
 func (m *Manager) IsAuthenticated() bool {
 	creds, err := m.loadCredentials()
@@ -102,41 +227,70 @@ func (m *Manager) NeedsRefresh() bool {
 
 // RefreshToken refreshes an OAuth token
 func (m *Manager) RefreshToken() error {
-	creds, err := m.GetCredentials()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	creds, err := m.load()
 	if err != nil {
 		return err
 	}
-	if creds == nil || creds.RefreshToken == "" {
-		return fmt.Errorf("no refresh token available")
+	if creds == nil {
+		client.PrintWarning("Not authenticated. Run 'hab auth login' to authenticate.")
+		return ErrNotAuthenticated
 	}
-
-	newCreds, err := RefreshAccessToken(creds)
-	if err != nil {
-		return err
+	if creds.RefreshToken == "" {
+		return fmt.Errorf("no refresh token available")
 	}
 
-	m.credentials = newCreds
-	return SaveCredentials(newCreds, m.ConfigDir)
+	_, err = m.refresh(creds, true)
+	return err
 }
 
 // Save saves new credentials
 func (m *Manager) Save(creds *Credentials) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.credentials = creds
-	return SaveCredentials(creds, m.ConfigDir)
+	return m.save(creds)
+}
+
+// save stores credentials in the managed context and records its URL
+func (m *Manager) save(creds *Credentials) error {
+	if err := m.store().Store(m.contextName(), creds); err != nil {
+		return err
+	}
+	return recordContextURL(m.ConfigDir, m.contextName(), creds.URL)
 }
 
-// Logout removes stored credentials
-func (m *Manager) Logout() bool {
+// Logout removes stored credentials. It returns false when there were none.
+func (m *Manager) Logout() (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.credentials = nil
-	return DeleteCredentials(m.ConfigDir)
+	creds, err := m.store().Get(m.contextName())
+	if err != nil {
+		return false, err
+	}
+	if creds == nil {
+		return false, nil
+	}
+	return true, m.store().Erase(m.contextName())
 }
 
 // GetAuthStatus returns authentication status as a map
 func (m *Manager) GetAuthStatus() map[string]interface{} {
-	creds, _ := m.loadCredentials()
+	creds, err := m.loadCredentials()
+	if err != nil {
+		return map[string]interface{}{
+			"authenticated": false,
+			"context":       m.contextName(),
+			"message":       "Failed to load credentials: " + err.Error(),
+		}
+	}
 	if creds == nil {
 		return map[string]interface{}{
 			"authenticated": false,
+			"context":       m.contextName(),
 			"message":       "Not authenticated. Run 'hab auth login' to authenticate.",
 		}
 	}
@@ -144,10 +298,13 @@ func (m *Manager) GetAuthStatus() map[string]interface{} {
 	authType := "token"
 	if creds.IsOAuth() {
 		authType = "oauth"
+	} else if creds.IsSupervisor() {
+		authType = "supervisor"
 	}
 
 	return map[string]interface{}{
 		"authenticated": true,
+		"context":       m.contextName(),
 		"url":           creds.URL,
 		"auth_type":     authType,
 		"token_expiry":  creds.TokenExpiry,
@@ -1,12 +1,15 @@
 package auth
 
 import (
+	"bufio"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -20,6 +23,10 @@ const (
 	TokenPath = "/auth/token"
 )
 
+// HTTPClient sends the OAuth token requests. Replace it to apply TLS and
+// proxy settings.
+var HTTPClient = http.DefaultClient
+
 // TokenResponse represents the OAuth token response
 type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
@@ -48,7 +55,7 @@ func ExchangeCodeForTokens(haURL, code, redirectURI string) (*Credentials, error
 	data.Set("client_id", clientID)
 	data.Set("redirect_uri", redirectURI)
 
-	resp, err := http.PostForm(tokenURL, data)
+	resp, err := HTTPClient.PostForm(tokenURL, data)
 	if err != nil {
 		return nil, fmt.Errorf("token request failed: %w", err)
 	}
@@ -87,7 +94,7 @@ func RefreshAccessToken(creds *Credentials) (*Credentials, error) {
 	data.Set("refresh_token", creds.RefreshToken)
 	data.Set("client_id", creds.ClientID)
 
-	resp, err := http.PostForm(tokenURL, data)
+	resp, err := HTTPClient.PostForm(tokenURL, data)
 	if err != nil {
 		return nil, fmt.Errorf("refresh request failed: %w", err)
 	}
@@ -123,16 +130,46 @@ func RefreshAccessToken(creds *Credentials) (*Credentials, error) {
 	}, nil
 }
 
+// HeadlessRedirectURI is the redirect URI of the headless flow. Nothing
+// listens there; the browser fails to load it and the user copies the URL,
+// which carries the authorization code, from the address bar.
+const HeadlessRedirectURI = "http://127.0.0.1/callback"
+
+// OAuthOptions configures RunOAuthFlowWithOptions
+type OAuthOptions struct {
+	// CallbackAddr is the host:port the callback server listens on. Empty
+	// picks the local network address and a free port.
+	CallbackAddr string
+	// Headless skips the callback server and the browser: the authorize URL
+	// is printed and the redirect URL is read from Input
+	Headless bool
+	// AllowBareCode accepts just the authorization code in headless mode.
+	// Its state cannot be checked, so a warning is printed.
+	AllowBareCode bool
+	// Input is read in headless mode; nil means stdin
+	Input io.Reader
+}
+
 // RunOAuthFlow runs the full OAuth flow
 func RunOAuthFlow(haURL string) (*Credentials, error) {
+	return RunOAuthFlowWithOptions(haURL, OAuthOptions{})
+}
+
+// RunOAuthFlowWithOptions runs the full OAuth flow with custom options
+func RunOAuthFlowWithOptions(haURL string, opts OAuthOptions) (*Credentials, error) {
 	// Generate state for CSRF protection
 	state, err := generateState()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate state: %w", err)
 	}
 
+	if opts.Headless {
+		return runHeadlessOAuthFlow(haURL, state, opts)
+	}
+
 	// Start callback server
 	server := NewOAuthCallbackServer()
+	server.Addr = opts.CallbackAddr
 	redirectURI, err := server.Start()
 	if err != nil {
 		return nil, fmt.Errorf("failed to start callback server: %w", err)
@@ -156,6 +193,76 @@ func RunOAuthFlow(haURL string) (*Credentials, error) {
 		return nil, err
 	}
 
+	return exchangeCallback(haURL, result, state, redirectURI)
+}
+
+// runHeadlessOAuthFlow runs the OAuth flow without a callback server, for
+// sessions without a local browser such as SSH
+func runHeadlessOAuthFlow(haURL, state string, opts OAuthOptions) (*Credentials, error) {
+	authURL := BuildAuthorizeURL(strings.TrimRight(haURL, "/"), HeadlessRedirectURI, state)
+
+	fmt.Println("\nOpen this URL in a browser on any machine and log in:")
+	fmt.Printf("\n  %s\n\n", authURL)
+	fmt.Println("The browser is then redirected to a page that fails to load.")
+	if opts.AllowBareCode {
+		fmt.Print("Paste its URL from the address bar (or just the code): ")
+	} else {
+		fmt.Print("Paste its URL from the address bar: ")
+	}
+
+	input := opts.Input
+	if input == nil {
+		input = os.Stdin
+	}
+	line, err := bufio.NewReader(input).ReadString('\n')
+	if err != nil && line == "" {
+		return nil, fmt.Errorf("failed to read redirect URL: %w", err)
+	}
+
+	result, err := parseCallbackInput(line)
+	if err != nil {
+		return nil, err
+	}
+	// A bare code carries no state to check, so it is only accepted on request
+	if !strings.Contains(line, "=") {
+		if !opts.AllowBareCode {
+			return nil, fmt.Errorf("paste the whole redirect URL: its state parameter is needed to verify the login (use --allow-bare-code to accept just the code)")
+		}
+		fmt.Fprintln(os.Stderr, "Warning: the state of a bare code cannot be verified; only accept codes from a login you started.")
+		result.State = state
+	}
+
+	return exchangeCallback(haURL, result, state, HeadlessRedirectURI)
+}
+
+// parseCallbackInput reads the callback parameters from a pasted redirect
+// URL, its query string, or a bare authorization code
+func parseCallbackInput(input string) (*CallbackResult, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("no redirect URL or code entered")
+	}
+	if !strings.Contains(input, "=") {
+		return &CallbackResult{Code: input}, nil
+	}
+
+	query := input
+	if i := strings.Index(input, "?"); i >= 0 {
+		query = input[i+1:]
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect URL: %w", err)
+	}
+	return &CallbackResult{
+		Code:  values.Get("code"),
+		State: values.Get("state"),
+		Error: values.Get("error"),
+	}, nil
+}
+
+// exchangeCallback validates the callback parameters and exchanges the code for tokens
+func exchangeCallback(haURL string, result *CallbackResult, state, redirectURI string) (*Credentials, error) {
 	if result.Error != "" {
 		return nil, fmt.Errorf("OAuth error: %s", result.Error)
 	}
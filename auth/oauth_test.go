@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// tokenServer is a Home Assistant token endpoint accepting the code "good"
+func tokenServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != TokenPath || r.FormValue("code") != "good" {
+			http.Error(w, "invalid code", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"access_token": "access", "refresh_token": "refresh", "expires_in": 1800}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHeadlessOAuthFlow(t *testing.T) {
+	srv := tokenServer(t)
+	const state = "expected-state"
+
+	tests := []struct {
+		name          string
+		input         string
+		allowBareCode bool
+		wantErr       string
+	}{
+		{name: "redirect URL", input: HeadlessRedirectURI + "?code=good&state=" + state},
+		{name: "query string", input: "code=good&state=" + url.QueryEscape(state)},
+		{name: "wrong state", input: HeadlessRedirectURI + "?code=good&state=other", wantErr: "state mismatch"},
+		{name: "missing state", input: HeadlessRedirectURI + "?code=good", wantErr: "state mismatch"},
+		{name: "bare code", input: "good", wantErr: "paste the whole redirect URL"},
+		{name: "bare code allowed", input: "good", allowBareCode: true},
+		{name: "error", input: HeadlessRedirectURI + "?error=access_denied&state=" + state, wantErr: "OAuth error: access_denied"},
+		{name: "empty", input: "\n", wantErr: "no redirect URL or code entered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := runHeadlessOAuthFlow(srv.URL, state, OAuthOptions{
+				Headless:      true,
+				AllowBareCode: tt.allowBareCode,
+				Input:         strings.NewReader(tt.input + "\n"),
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if creds.AccessToken != "access" || creds.RefreshToken != "refresh" {
+				t.Errorf("credentials = %+v", creds)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultProbePort is the port probed when a target does not name one
+	DefaultProbePort = 8123
+	// DefaultProbeWorkers is the number of hosts probed at the same time
+	DefaultProbeWorkers = 64
+	// DefaultProbeTimeout is how long each probe request may take
+	DefaultProbeTimeout = 2 * time.Second
+
+	// maxProbeHosts bounds the addresses a probe expands to (a /16)
+	maxProbeHosts = 65536
+)
+
+// ProbeOptions configures ProbeServers
+type ProbeOptions struct {
+	// Targets are CIDR ranges, IP addresses, host names, host:port pairs or URLs
+	Targets []string
+	// Port is used for targets without one; zero means DefaultProbePort
+	Port int
+	// Workers bounds the concurrent probes; zero means DefaultProbeWorkers
+	Workers int
+	// Timeout applies to each request; zero means DefaultProbeTimeout
+	Timeout time.Duration
+	// Transport sends the probe requests; nil means http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// discoveryInfo is the unauthenticated /api/discovery_info response
+type discoveryInfo struct {
+	LocationName string `json:"location_name"`
+	Version      string `json:"version"`
+	UUID         string `json:"uuid"`
+	InternalURL  string `json:"internal_url"`
+	ExternalURL  string `json:"external_url"`
+}
+
+// webManifest is the part of /manifest.json identifying the frontend
+type webManifest struct {
+	Name      string `json:"name"`
+	ShortName string `json:"short_name"`
+}
+
+// ProbeServers looks for Home Assistant instances by requesting
+// /api/discovery_info, and /manifest.json when that fails, from every
+// target address. It works where mDNS does not reach, such as across VLANs
+// or from inside Docker networks.
+func ProbeServers(ctx context.Context, opts ProbeOptions) ([]DiscoveredServer, error) {
+	port := opts.Port
+	if port == 0 {
+		port = DefaultProbePort
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultProbeWorkers
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultProbeTimeout
+	}
+
+	urls, err := ExpandProbeTargets(opts.Targets, port)
+	if err != nil {
+		return nil, err
+	}
+	if workers > len(urls) {
+		workers = len(urls)
+	}
+
+	httpClient := &http.Client{
+		Transport: opts.Transport,
+		Timeout:   timeout,
+		// A redirect means something other than Home Assistant answers
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	jobs := make(chan string)
+	var (
+		mu      sync.Mutex
+		servers []DiscoveredServer
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for baseURL := range jobs {
+				if server, ok := probeServer(ctx, httpClient, baseURL); ok {
+					mu.Lock()
+					servers = append(servers, server)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, u := range urls {
+		select {
+		case jobs <- u:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return MergeServers(servers), nil
+}
+
+// ExpandProbeTargets turns probe targets into the base URLs to probe
+func ExpandProbeTargets(targets []string, port int) ([]string, error) {
+	var urls []string
+	seen := make(map[string]bool)
+	add := func(u string) {
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+
+		switch {
+		case strings.Contains(target, "://"):
+			u, err := url.Parse(target)
+			if err != nil || u.Host == "" {
+				return nil, fmt.Errorf("invalid probe target %q", target)
+			}
+			add(u.Scheme + "://" + u.Host)
+
+		case strings.Contains(target, "/"):
+			prefix, err := netip.ParsePrefix(target)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR range %q", target)
+			}
+			addrs, err := prefixHosts(prefix.Masked())
+			if err != nil {
+				return nil, err
+			}
+			if len(urls)+len(addrs) > maxProbeHosts {
+				return nil, fmt.Errorf("too many addresses to probe (at most %d)", maxProbeHosts)
+			}
+			for _, addr := range addrs {
+				add(probeURL(addr.String(), port))
+			}
+
+		default:
+			host, hostPort := target, port
+			if h, p, err := net.SplitHostPort(target); err == nil {
+				n, err := strconv.Atoi(p)
+				if err != nil || n <= 0 || n > 65535 {
+					return nil, fmt.Errorf("invalid port in probe target %q", target)
+				}
+				host, hostPort = h, n
+			}
+			add(probeURL(strings.Trim(host, "[]"), hostPort))
+		}
+	}
+
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no probe targets given")
+	}
+	return urls, nil
+}
+
+// prefixHosts returns the host addresses of a range. IPv4 network and
+// broadcast addresses are skipped, except in /31 and /32 ranges.
+func prefixHosts(prefix netip.Prefix) ([]netip.Addr, error) {
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits > 16 {
+		return nil, fmt.Errorf("CIDR range %s is too large to probe (at most /%d)", prefix, prefix.Addr().BitLen()-16)
+	}
+
+	var addrs []netip.Addr
+	for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+		addrs = append(addrs, addr)
+		if !addr.Next().IsValid() {
+			break
+		}
+	}
+	if prefix.Addr().Is4() && hostBits > 1 {
+		addrs = addrs[1 : len(addrs)-1]
+	}
+	return addrs, nil
+}
+
+// probeURL returns the base URL of a host, using HTTPS on the usual TLS ports
+func probeURL(host string, port int) string {
+	protocol := "http"
+	if port == 443 || port == 8443 {
+		protocol = "https"
+	}
+	return fmt.Sprintf("%s://%s", protocol, net.JoinHostPort(host, strconv.Itoa(port)))
+}
+
+// probeServer checks whether Home Assistant answers at baseURL
+func probeServer(ctx context.Context, httpClient *http.Client, baseURL string) (DiscoveredServer, bool) {
+	u, _ := url.Parse(baseURL)
+	server := DiscoveredServer{URL: baseURL, Host: u.Hostname()}
+	server.Port, _ = strconv.Atoi(u.Port())
+	if ip := net.ParseIP(server.Host); ip != nil {
+		if ip.To4() != nil {
+			server.IPv4 = []net.IP{ip}
+		} else {
+			server.IPv6 = []net.IP{ip}
+		}
+	}
+
+	var info discoveryInfo
+	if getJSON(ctx, httpClient, baseURL+"/api/discovery_info", &info) && (info.Version != "" || info.UUID != "") {
+		server.Name = info.LocationName
+		server.Version = info.Version
+		server.UUID = info.UUID
+		server.Internal = info.InternalURL
+		server.External = info.ExternalURL
+		if server.Name == "" {
+			server.Name = server.Host
+		}
+		return server, true
+	}
+
+	// Newer releases no longer serve discovery_info; the frontend manifest
+	// still identifies the instance
+	var manifest webManifest
+	if getJSON(ctx, httpClient, baseURL+"/manifest.json", &manifest) &&
+		strings.Contains(manifest.Name+manifest.ShortName, "Home Assistant") {
+		server.Name = server.Host
+		return server, true
+	}
+
+	return server, false
+}
+
+// getJSON decodes the response to a GET request into v, reporting success
+func getJSON(ctx context.Context, httpClient *http.Client, u string, v interface{}) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	return json.NewDecoder(resp.Body).Decode(v) == nil
+}
+
+// MergeServers combines discovery results, treating servers with the same
+// UUID or URL as one. Earlier entries win; their empty fields are filled
+// from later ones. The result is sorted by name.
+func MergeServers(lists ...[]DiscoveredServer) []DiscoveredServer {
+	var merged []DiscoveredServer
+	find := func(s DiscoveredServer) int {
+		for i, m := range merged {
+			if (s.UUID != "" && m.UUID == s.UUID) || m.URL == s.URL {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for _, list := range lists {
+		for _, s := range list {
+			i := find(s)
+			if i < 0 {
+				merged = append(merged, s)
+				continue
+			}
+			m := &merged[i]
+			if m.Version == "" {
+				m.Version = s.Version
+			}
+			if m.UUID == "" {
+				m.UUID = s.UUID
+			}
+			if m.Internal == "" {
+				m.Internal = s.Internal
+			}
+			if m.External == "" {
+				m.External = s.External
+			}
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Name < merged[j].Name
+	})
+	return merged
+}
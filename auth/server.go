@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -19,6 +20,10 @@ type CallbackResult struct {
 
 // OAuthCallbackServer handles OAuth callbacks
 type OAuthCallbackServer struct {
+	// Addr, when set, is the host:port to listen on, e.g. a fixed port to
+	// forward over SSH. The redirect URI uses its host, or the local network
+	// address when the host is empty or unspecified (0.0.0.0).
+	Addr     string
 	IP       string
 	Port     int
 	server   *http.Server
@@ -39,8 +44,20 @@ func (s *OAuthCallbackServer) Start() (string, error) {
 	// Get local IP
 	s.IP = getLocalIP()
 
-	// Find available port
-	listener, err := net.Listen("tcp", fmt.Sprintf("%s:0", s.IP))
+	// Find available port, unless one was chosen
+	listenAddr := net.JoinHostPort(s.IP, "0")
+	if s.Addr != "" {
+		host, _, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return "", fmt.Errorf("invalid callback address %q: %w", s.Addr, err)
+		}
+		if ip := net.ParseIP(host); host != "" && (ip == nil || !ip.IsUnspecified()) {
+			s.IP = host
+		}
+		listenAddr = s.Addr
+	}
+
+	listener, err := net.Listen("tcp", listenAddr)
 	if err != nil {
 		return "", fmt.Errorf("failed to listen: %w", err)
 	}
@@ -57,7 +74,7 @@ func (s *OAuthCallbackServer) Start() (string, error) {
 		s.server.Serve(listener)
 	}()
 
-	return fmt.Sprintf("http://%s:%d/callback", s.IP, s.Port), nil
+	return fmt.Sprintf("http://%s/callback", net.JoinHostPort(s.IP, strconv.Itoa(s.Port))), nil
 }
 
 func (s *OAuthCallbackServer) handleCallback(w http.ResponseWriter, r *http.Request) {
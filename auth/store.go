@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/home-assistant/hab/config"
+)
+
+// Credential store names accepted by NewCredentialStore. Any other name
+// selects an external credential helper.
+const (
+	StoreFile       = "file"
+	StorePassphrase = "passphrase"
+	StorePlaintext  = "plaintext"
+	StoreEnv        = "env"
+)
+
+// ErrReadOnlyStore is returned when saving to a store that cannot keep credentials
+var ErrReadOnlyStore = errors.New("credential store is read-only")
+
+// CredentialStore keeps the credentials of each context
+type CredentialStore interface {
+	// Get returns the credentials of a context, or nil without error when
+	// there are none
+	Get(context string) (*Credentials, error)
+	// Store saves the credentials of a context, replacing any stored before
+	Store(context string, creds *Credentials) error
+	// Erase removes the credentials of a context. Erasing a context without
+	// credentials is not an error.
+	Erase(context string) error
+}
+
+// NewCredentialStore returns the store with the given name: "file" (the
+// default) for the file encrypted with the machine key, "passphrase" for the
+// file encrypted with a key derived from passphrase, "plaintext" for an
+// unencrypted file, "env" for environment variables only, or the name of a
+// credential helper
+func NewCredentialStore(configDir, name string, passphrase PassphraseFunc) CredentialStore {
+	switch name {
+	case "", StoreFile:
+		return &FileStore{ConfigDir: configDir}
+	case StorePassphrase:
+		return &FileStore{ConfigDir: configDir, Passphrase: passphrase}
+	case StorePlaintext:
+		return &FileStore{ConfigDir: configDir, Plaintext: true}
+	case StoreEnv:
+		return EnvStore{}
+	default:
+		return &HelperStore{Helper: name}
+	}
+}
+
+// FileStore keeps credentials in a file in each context's directory.
+// The file is encrypted unless Plaintext is set.
+type FileStore struct {
+	ConfigDir string
+	Plaintext bool
+	// Passphrase, when set, encrypts files with a key derived from the
+	// passphrase instead of the machine key. Without it, files protected by
+	// a passphrase cannot be read.
+	Passphrase PassphraseFunc
+}
+
+func (s *FileStore) path(context string) string {
+	if s.Plaintext {
+		return config.GetContextPlaintextCredentialsPath(s.ConfigDir, context)
+	}
+	return config.GetContextCredentialsPath(s.ConfigDir, context)
+}
+
+// Get reads the credentials file of a context
+func (s *FileStore) Get(context string) (*Credentials, error) {
+	data, err := os.ReadFile(s.path(context))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if !s.Plaintext {
+		data, err = unseal(data, s.Passphrase)
+		if errors.Is(err, ErrPassphraseRequired) && s.Passphrase == nil {
+			return nil, fmt.Errorf("%w; use --credential-store passphrase", err)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+// Store writes the credentials file of a context
+func (s *FileStore) Store(context string, creds *Credentials) error {
+	if err := os.MkdirAll(config.GetContextDir(s.ConfigDir, context), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+
+	if !s.Plaintext {
+		passphrase := ""
+		if s.Passphrase != nil {
+			if passphrase, err = s.Passphrase(); err != nil {
+				return err
+			}
+			if passphrase == "" {
+				return ErrPassphraseRequired
+			}
+		}
+		data, err = seal(data, passphrase)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(s.path(context), data, 0600)
+}
+
+// Erase removes the credentials file of a context
+func (s *FileStore) Erase(context string) error {
+	if err := os.Remove(s.path(context)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// EnvStore reads credentials from HAB_URL, HAB_TOKEN and HAB_REFRESH_TOKEN
+// only and never writes them anywhere. Every context has the same credentials.
+type EnvStore struct{}
+
+// Get returns the credentials set in the environment
+func (EnvStore) Get(context string) (*Credentials, error) {
+	return credentialsFromEnv(), nil
+}
+
+// Store fails; set the environment variables instead
+func (EnvStore) Store(context string, creds *Credentials) error {
+	return fmt.Errorf("%w: set HAB_URL and HAB_TOKEN instead", ErrReadOnlyStore)
+}
+
+// Erase does nothing; the environment is left as it is
+func (EnvStore) Erase(context string) error {
+	return nil
+}
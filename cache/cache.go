@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/home-assistant/hab/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// CacheDir is the cache directory name inside the config directory
+	CacheDir = "cache"
+)
+
+// Store is an on-disk cache of command results for one Home Assistant instance.
+// Entries live in <config dir>/cache/<instance hash>/ and expire after TTL.
+type Store struct {
+	URL string
+	TTL time.Duration
+	dir string
+}
+
+// entry is the file format of a cached result
+type entry struct {
+	StoredAt time.Time       `json:"stored_at"`
+	URL      string          `json:"url"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// New returns the cache of the instance at instanceURL
+func New(configDir, instanceURL string, ttl time.Duration) *Store {
+	return &Store{
+		URL: instanceURL,
+		TTL: ttl,
+		dir: filepath.Join(GetCacheDir(configDir), instanceKey(instanceURL)),
+	}
+}
+
+// GetCacheDir returns the directory holding the caches of all instances
+func GetCacheDir(configDir string) string {
+	return filepath.Join(config.GetConfigDir(configDir), CacheDir)
+}
+
+// ClearAll removes the caches of all instances
+func ClearAll(configDir string) error {
+	return os.RemoveAll(GetCacheDir(configDir))
+}
+
+// instanceKey derives the directory name for an instance URL
+func instanceKey(instanceURL string) string {
+	sum := sha256.Sum256([]byte(strings.TrimRight(instanceURL, "/")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// path returns the file of a cache key
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, strings.ReplaceAll(key, "/", "_")+".json")
+}
+
+// Get returns the cached value of key if it is younger than the TTL
+func (s *Store) Get(key string) (interface{}, bool) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		log.WithError(err).WithField("key", key).Debug("Ignoring corrupt cache entry")
+		return nil, false
+	}
+	if e.URL != s.URL || time.Since(e.StoredAt) > s.TTL {
+		return nil, false
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(e.Data, &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Put stores value under key. Failures are logged and otherwise ignored,
+// the cache is only an optimization.
+func (s *Store) Put(key string, value interface{}) {
+	if err := s.put(key, value); err != nil {
+		log.WithError(err).WithField("key", key).Debug("Failed to write cache entry")
+	}
+}
+
+func (s *Store) put(key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry{StoredAt: time.Now(), URL: s.URL, Data: raw})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+// Invalidate removes every entry of the instance
+func (s *Store) Invalidate() {
+	if err := os.RemoveAll(s.dir); err != nil {
+		log.WithError(err).Debug("Failed to invalidate cache")
+	}
+}
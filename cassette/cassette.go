@@ -0,0 +1,437 @@
+// Package cassette records the REST and WebSocket traffic of hab commands to
+// a file and replays it in place of a Home Assistant instance.
+//
+// REST requests are matched by method and endpoint, WebSocket commands by
+// type and parameters. Repeated requests are answered with successive
+// recordings, and the last one is reused once they are exhausted.
+// Access tokens are never written to a cassette.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/home-assistant/hab/client"
+)
+
+// Version is the cassette file format version
+const Version = 1
+
+// redacted replaces credentials in recorded data
+const redacted = "REDACTED"
+
+// File is the on-disk format of a cassette
+type File struct {
+	Version    int               `json:"version"`
+	URL        string            `json:"url,omitempty"`
+	RecordedAt time.Time         `json:"recorded_at"`
+	REST       []RESTInteraction `json:"rest"`
+	WebSocket  []Frame           `json:"websocket"`
+}
+
+// RESTInteraction is one REST request and its response
+type RESTInteraction struct {
+	Method      string          `json:"method"`
+	Endpoint    string          `json:"endpoint"`
+	RequestBody json.RawMessage `json:"request_body,omitempty"`
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	// Body holds JSON responses, Text all others
+	Body json.RawMessage `json:"body,omitempty"`
+	Text string          `json:"text,omitempty"`
+}
+
+// Frame is one WebSocket message after authentication
+type Frame struct {
+	Session   int             `json:"session"`
+	Direction string          `json:"direction"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// Cassette is a client.Cassette backed by a File
+type Cassette struct {
+	path      string
+	replaying bool
+
+	mu       sync.Mutex
+	file     File
+	sessions int
+
+	// Replay state
+	commands []*command
+	restUsed map[int]bool
+}
+
+// command is a recorded WebSocket command with the frames answering it
+type command struct {
+	cmdType string
+	params  string
+	frames  [][]byte
+	used    bool
+}
+
+var _ client.Cassette = (*Cassette)(nil)
+
+// NewRecorder returns a cassette recording traffic with the instance at
+// instanceURL. Call Save to write it to path.
+func NewRecorder(path, instanceURL string) *Cassette {
+	return &Cassette{
+		path: path,
+		file: File{
+			Version:    Version,
+			URL:        instanceURL,
+			RecordedAt: time.Now().UTC(),
+			REST:       []RESTInteraction{},
+			WebSocket:  []Frame{},
+		},
+	}
+}
+
+// Load reads the cassette at path for replay
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	if file.Version != Version {
+		return nil, fmt.Errorf("unsupported cassette version %d", file.Version)
+	}
+
+	c := &Cassette{
+		path:      path,
+		replaying: true,
+		file:      file,
+		restUsed:  make(map[int]bool),
+	}
+	c.indexCommands()
+	return c, nil
+}
+
+// URL returns the instance URL the cassette was recorded against
+func (c *Cassette) URL() string {
+	return c.file.URL
+}
+
+// Replaying reports whether the cassette answers requests
+func (c *Cassette) Replaying() bool {
+	return c.replaying
+}
+
+// Save writes a recording cassette to its file
+func (c *Cassette) Save() error {
+	if c.replaying {
+		return nil
+	}
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.file, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0600)
+}
+
+// OpenSession starts a new WebSocket connection in the recording
+func (c *Cassette) OpenSession() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessions++
+	return c.sessions
+}
+
+// RecordFrame records a WebSocket frame with credentials redacted
+func (c *Cassette) RecordFrame(session int, direction string, frame []byte) {
+	if c.replaying {
+		return
+	}
+	message, err := redactJSON(frame)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.file.WebSocket = append(c.file.WebSocket, Frame{
+		Session:   session,
+		Direction: direction,
+		Message:   message,
+	})
+}
+
+// indexCommands pairs every sent command with the frames received under its ID
+func (c *Cassette) indexCommands() {
+	type key struct {
+		session int
+		id      int
+	}
+	byID := make(map[key]*command)
+
+	for _, frame := range c.file.WebSocket {
+		var msg map[string]interface{}
+		if err := json.Unmarshal(frame.Message, &msg); err != nil {
+			continue
+		}
+		id, _ := msg["id"].(float64)
+		k := key{frame.Session, int(id)}
+
+		switch frame.Direction {
+		case "send":
+			cmdType, _ := msg["type"].(string)
+			cmd := &command{cmdType: cmdType, params: paramsKey(withoutEnvelope(msg))}
+			byID[k] = cmd
+			c.commands = append(c.commands, cmd)
+		case "recv":
+			if cmd, ok := byID[k]; ok {
+				cmd.frames = append(cmd.frames, frame.Message)
+			}
+		}
+	}
+}
+
+// ReplayCommand returns the recorded answer to a WebSocket command
+func (c *Cassette) ReplayCommand(cmdType string, params map[string]interface{}) ([][]byte, error) {
+	key := paramsKey(params)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	match := c.nextCommand(func(cmd *command) bool {
+		return cmd.cmdType == cmdType && cmd.params == key
+	})
+	// Subscription IDs differ when the command sequence differs from the
+	// recording; any recorded unsubscribe will do
+	if match == nil && cmdType == "unsubscribe_events" {
+		match = c.nextCommand(func(cmd *command) bool {
+			return cmd.cmdType == cmdType
+		})
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w for WebSocket command %s", client.ErrNotRecorded, cmdType)
+	}
+	return match.frames, nil
+}
+
+// nextCommand returns the first unused matching command, or the last
+// matching one when all were used
+func (c *Cassette) nextCommand(matches func(*command) bool) *command {
+	var last *command
+	for _, cmd := range c.commands {
+		if !matches(cmd) {
+			continue
+		}
+		if !cmd.used {
+			cmd.used = true
+			return cmd
+		}
+		last = cmd
+	}
+	return last
+}
+
+// WrapTransport returns a round tripper recording to or replaying from the cassette
+func (c *Cassette) WrapTransport(next http.RoundTripper) http.RoundTripper {
+	return &roundTripper{cassette: c, next: next}
+}
+
+type roundTripper struct {
+	cassette *Cassette
+	next     http.RoundTripper
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.cassette.replaying {
+		return rt.cassette.replayREST(req)
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	rt.cassette.recordREST(req, reqBody, resp, body)
+	return resp, nil
+}
+
+// recordREST adds a REST interaction with credentials redacted
+func (c *Cassette) recordREST(req *http.Request, reqBody []byte, resp *http.Response, body []byte) {
+	interaction := RESTInteraction{
+		Method:      req.Method,
+		Endpoint:    endpoint(req),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if len(reqBody) > 0 {
+		if redactedBody, err := redactJSON(reqBody); err == nil {
+			interaction.RequestBody = redactedBody
+		}
+	}
+	if redactedBody, err := redactJSON(body); err == nil && len(body) > 0 {
+		interaction.Body = redactedBody
+	} else {
+		interaction.Text = string(body)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.file.REST = append(c.file.REST, interaction)
+}
+
+// replayREST answers a request with the next recorded response for it
+func (c *Cassette) replayREST(req *http.Request) (*http.Response, error) {
+	method, ep := req.Method, endpoint(req)
+
+	c.mu.Lock()
+	match := -1
+	for i, interaction := range c.file.REST {
+		if interaction.Method != method || interaction.Endpoint != ep {
+			continue
+		}
+		match = i
+		if !c.restUsed[i] {
+			break
+		}
+	}
+	if match >= 0 {
+		c.restUsed[match] = true
+	}
+	c.mu.Unlock()
+
+	if match < 0 {
+		return nil, fmt.Errorf("%w for %s %s", client.ErrNotRecorded, method, ep)
+	}
+
+	interaction := c.file.REST[match]
+	body := []byte(interaction.Text)
+	if interaction.Body != nil {
+		body = interaction.Body
+	}
+	header := make(http.Header)
+	if interaction.ContentType != "" {
+		header.Set("Content-Type", interaction.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// endpoint returns the path of a request below /api/, with its query
+func endpoint(req *http.Request) string {
+	ep := strings.TrimPrefix(req.URL.Path, "/api/")
+	if req.URL.RawQuery != "" {
+		ep += "?" + req.URL.RawQuery
+	}
+	return ep
+}
+
+// withoutEnvelope returns the parameters of a command message
+func withoutEnvelope(msg map[string]interface{}) map[string]interface{} {
+	params := make(map[string]interface{}, len(msg))
+	for k, v := range msg {
+		if k != "id" && k != "type" {
+			params[k] = v
+		}
+	}
+	return params
+}
+
+// paramsKey returns a canonical form of command parameters for matching
+func paramsKey(params map[string]interface{}) string {
+	if len(params) == 0 {
+		return "{}"
+	}
+	// Round-trip through JSON so recorded and live values compare equal
+	data, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return ""
+	}
+	data, _ = json.Marshal(redact(normalized))
+	return string(data)
+}
+
+// sensitiveKeys are object keys whose values are replaced when recording
+var sensitiveKeys = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"token":         true,
+	"password":      true,
+}
+
+// redactJSON returns data with sensitive values replaced
+func redactJSON(data []byte) (json.RawMessage, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(redact(v))
+}
+
+func redact(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if sensitiveKeys[k] {
+				if _, ok := item.(string); ok {
+					val[k] = redacted
+					continue
+				}
+			}
+			val[k] = redact(item)
+		}
+		// A new long-lived access token is the bare result of its command
+		if val["type"] == "result" {
+			if _, ok := val["result"].(string); ok && isTokenResult(val) {
+				val["result"] = redacted
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redact(item)
+		}
+	}
+	return v
+}
+
+// isTokenResult reports whether a string result looks like an access token (a JWT)
+func isTokenResult(msg map[string]interface{}) bool {
+	s, _ := msg["result"].(string)
+	return strings.Count(s, ".") == 2 && strings.HasPrefix(s, "eyJ")
+}
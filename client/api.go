@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"net"
+)
+
+// RestAPI is the set of Home Assistant REST operations used by commands
+type RestAPI interface {
+	Get(endpoint string) (interface{}, error)
+	Post(endpoint string, body interface{}) (interface{}, error)
+	Put(endpoint string, body interface{}) (interface{}, error)
+	Delete(endpoint string) (interface{}, error)
+
+	GetConfig() (map[string]interface{}, error)
+	GetStates() ([]interface{}, error)
+	GetState(entityID string) (map[string]interface{}, error)
+	GetServices() ([]interface{}, error)
+	CallService(domain, service string, data map[string]interface{}) (interface{}, error)
+	CheckConfig() (map[string]interface{}, error)
+	Restart() error
+	GetErrorLog() (string, error)
+	GetHistory(entityID string, startTime, endTime string) ([]interface{}, error)
+
+	ConfigFlowCreate(handler string) (map[string]interface{}, error)
+	ConfigFlowStep(flowID string, data map[string]interface{}) (map[string]interface{}, error)
+	ConfigEntryDelete(entryID string) error
+}
+
+// WebSocketAPI is the set of Home Assistant WebSocket operations used by commands
+type WebSocketAPI interface {
+	Close() error
+
+	SendCommand(cmdType string, params map[string]interface{}) (interface{}, error)
+	SendCommandContext(ctx context.Context, cmdType string, params map[string]interface{}) (interface{}, error)
+	Subscribe(cmdType string, params map[string]interface{}) (<-chan Event, func() error, error)
+	SubscribeEvents(eventType string) (<-chan Event, func() error, error)
+	SubscribeEntities(entityIDs []string) (<-chan Event, func() error, error)
+
+	GetStates() ([]interface{}, error)
+	GetConfig() (map[string]interface{}, error)
+	GetServices() (map[string]interface{}, error)
+	CallService(domain, service string, data, target map[string]interface{}, returnResponse bool) (interface{}, error)
+	Ping() error
+
+	AreaRegistryList() ([]interface{}, error)
+	AreaRegistryCreate(name string, params map[string]interface{}) (map[string]interface{}, error)
+	AreaRegistryUpdate(areaID string, params map[string]interface{}) (map[string]interface{}, error)
+	AreaRegistryDelete(areaID string) error
+	FloorRegistryList() ([]interface{}, error)
+	FloorRegistryCreate(name string, params map[string]interface{}) (map[string]interface{}, error)
+	FloorRegistryUpdate(floorID string, params map[string]interface{}) (map[string]interface{}, error)
+	FloorRegistryDelete(floorID string) error
+	LabelRegistryList() ([]interface{}, error)
+	LabelRegistryCreate(name string, params map[string]interface{}) (map[string]interface{}, error)
+	LabelRegistryUpdate(labelID string, params map[string]interface{}) (map[string]interface{}, error)
+	LabelRegistryDelete(labelID string) error
+	DeviceRegistryList() ([]interface{}, error)
+	DeviceRegistryUpdate(deviceID string, params map[string]interface{}) (map[string]interface{}, error)
+	EntityRegistryList() ([]interface{}, error)
+	EntityRegistryGet(entityID string) (map[string]interface{}, error)
+	EntityRegistryUpdate(entityID string, params map[string]interface{}) (map[string]interface{}, error)
+
+	ZoneList() ([]interface{}, error)
+	ZoneCreate(name string, latitude, longitude, radius float64, params map[string]interface{}) (map[string]interface{}, error)
+	ZoneUpdate(zoneID string, params map[string]interface{}) (map[string]interface{}, error)
+	ZoneDelete(zoneID string) error
+
+	AuthCurrentUser() (map[string]interface{}, error)
+	AuthRefreshTokenList() ([]interface{}, error)
+	AuthLongLivedTokenCreate(clientName string, lifespanDays int) (string, error)
+	AuthRefreshTokenDelete(tokenID string) error
+
+	SystemHealthInfo() (map[string]interface{}, error)
+	SearchRelated(itemType, itemID string) (map[string][]string, error)
+
+	HelperList(helperType string) ([]interface{}, error)
+	HelperCreate(helperType string, params map[string]interface{}) (map[string]interface{}, error)
+	HelperUpdate(helperType, helperID string, params map[string]interface{}) (map[string]interface{}, error)
+	HelperDelete(helperType, helperID string) error
+
+	ListStates() ([]State, error)
+	ListEntityEntries() ([]EntityRegistryEntry, error)
+	GetEntityEntry(entityID string) (*EntityRegistryEntry, error)
+	ListDevices() ([]DeviceEntry, error)
+	ListAreas() ([]Area, error)
+	ListFloors() ([]Floor, error)
+	ListLabels() ([]Label, error)
+	ListZones() ([]Zone, error)
+	ListConfigEntries(domain string) ([]ConfigEntry, error)
+	ListDashboards() ([]Dashboard, error)
+	ListBackups() ([]Backup, error)
+
+	ConfigFlowInit(handler string, context map[string]interface{}) (map[string]interface{}, error)
+	ConfigFlowConfigure(flowID string, data map[string]interface{}) (map[string]interface{}, error)
+	ConfigEntriesList(domain string) ([]interface{}, error)
+	ConfigEntryDelete(entryID string) error
+	ResolveEntityToConfigEntry(entityID string) (string, error)
+	DeleteHelperByEntityOrEntryID(id string, helperType string) error
+}
+
+// HAClient hands out the REST and WebSocket APIs of one Home Assistant instance
+type HAClient interface {
+	REST() (RestAPI, error)
+	WebSocket() (WebSocketAPI, error)
+}
+
+var (
+	_ RestAPI      = (*RestClient)(nil)
+	_ WebSocketAPI = (*WebSocketClient)(nil)
+	_ HAClient     = (*Instance)(nil)
+)
+
+// TokenSource returns a valid access token, refreshing it when needed.
+// It must be safe for concurrent use.
+type TokenSource func() (string, error)
+
+// Instance is an HAClient for a Home Assistant instance reached over the network.
+// Requests and connections it creates are bound to its context.
+type Instance struct {
+	URL   string
+	Token string
+	// TokenSource, when set, is shared by the clients the instance creates
+	// and supplies their access tokens in place of Token
+	TokenSource TokenSource
+	// Cache, when set, is shared by the clients the instance creates
+	Cache Cache
+	// Dial, when set, replaces the network dialer of the clients the
+	// instance creates, e.g. to reach a hab daemon over its Unix socket
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+	// Retry and Limiter, when set, are shared by the clients the instance creates
+	Retry   *RetryPolicy
+	Limiter *RateLimiter
+	// Transport, when set, applies TLS and proxy settings
+	Transport *TransportConfig
+	// Cassette, when set, records or replays the traffic of the clients
+	Cassette Cassette
+	ctx      context.Context
+}
+
+// NewInstance creates an HAClient for the instance at baseURL
+func NewInstance(ctx context.Context, baseURL, token string) *Instance {
+	return &Instance{
+		URL:   baseURL,
+		Token: token,
+		ctx:   ctx,
+	}
+}
+
+// REST returns a REST client for the instance
+func (i *Instance) REST() (RestAPI, error) {
+	rc := NewRestClient(i.URL, i.Token)
+	rc.TokenSource = i.TokenSource
+	rc.Cache = i.Cache
+	rc.Dial = i.Dial
+	rc.Retry = i.Retry
+	rc.Limiter = i.Limiter
+	rc.Transport = i.Transport
+	rc.Cassette = i.Cassette
+	return rc.WithContext(i.context()), nil
+}
+
+// WebSocket returns an authenticated WebSocket connection to the instance.
+// Callers must Close it.
+func (i *Instance) WebSocket() (WebSocketAPI, error) {
+	ws := NewWebSocketClient(i.URL, i.Token)
+	ws.TokenSource = i.TokenSource
+	ws.Cache = i.Cache
+	ws.Dial = i.Dial
+	ws.Retry = i.Retry
+	ws.Limiter = i.Limiter
+	ws.Transport = i.Transport
+	ws.Cassette = i.Cassette
+	if err := ws.ConnectContext(i.context()); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+func (i *Instance) context() context.Context {
+	if i.ctx == nil {
+		return context.Background()
+	}
+	return i.ctx
+}
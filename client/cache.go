@@ -0,0 +1,77 @@
+package client
+
+import "strings"
+
+// Cache stores command results between hab invocations.
+// Implementations decide how long entries stay valid.
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Put(key string, value interface{})
+	// Invalidate drops every entry; it is called after a command that may
+	// have changed a cached registry
+	Invalidate()
+}
+
+// cachedCommands are the parameterless WebSocket commands whose results are cached
+var cachedCommands = map[string]bool{
+	"config/entity_registry/list": true,
+	"config/device_registry/list": true,
+	"config/area_registry/list":   true,
+	"config/floor_registry/list":  true,
+	"config/label_registry/list":  true,
+}
+
+// readOnlyCommands are WebSocket commands that never change a registry
+var readOnlyCommands = map[string]bool{
+	"ping":                true,
+	"render_template":     true,
+	"unsubscribe_events":  true,
+	"search/related":      true,
+	"lovelace/config":     true,
+	"system_health/info":  true,
+	"validate_config":     true,
+	"auth/current_user":   true,
+	"auth/refresh_tokens": true,
+}
+
+// commandMutates reports whether a WebSocket command may change a cached registry
+func commandMutates(cmdType string, params map[string]interface{}) bool {
+	if cmdType == "call_service" {
+		domain, _ := params["domain"].(string)
+		service, _ := params["service"].(string)
+		return serviceMutates(domain, service)
+	}
+	if readOnlyCommands[cmdType] {
+		return false
+	}
+	if strings.HasPrefix(cmdType, "get_") || strings.HasPrefix(cmdType, "subscribe_") {
+		return false
+	}
+	switch cmdType[strings.LastIndex(cmdType, "/")+1:] {
+	case "list", "get", "info":
+		return false
+	}
+	return true
+}
+
+// requestMutates reports whether a REST request may change a cached registry
+func requestMutates(method, endpoint string) bool {
+	if method == "GET" {
+		return false
+	}
+	if parts := strings.Split(endpoint, "/"); len(parts) == 3 && parts[0] == "services" {
+		return serviceMutates(parts[1], parts[2])
+	}
+	switch endpoint {
+	case "config/core/check_config", "template":
+		return false
+	}
+	return true
+}
+
+// serviceMutates reports whether a service call may add, remove or rename
+// registry entries. Reloads can create or drop entities; ordinary device
+// services only change states.
+func serviceMutates(domain, service string) bool {
+	return domain == "homeassistant" || strings.Contains(service, "reload")
+}
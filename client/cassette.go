@@ -0,0 +1,26 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrNotRecorded is matched by errors for requests a replayed cassette has
+// no response for
+var ErrNotRecorded = errors.New("no recorded response")
+
+// Cassette records REST and WebSocket traffic, or replays it in place of a
+// Home Assistant instance. Implementations redact credentials.
+type Cassette interface {
+	// Replaying reports whether responses come from the cassette
+	Replaying() bool
+	// WrapTransport returns a round tripper that records or replays REST traffic
+	WrapTransport(next http.RoundTripper) http.RoundTripper
+	// OpenSession starts recording a WebSocket connection and returns its number
+	OpenSession() int
+	// RecordFrame records a frame sent ("send") or received ("recv") on a session
+	RecordFrame(session int, direction string, frame []byte)
+	// ReplayCommand returns the frames recorded in answer to a command:
+	// its result followed by any events
+	ReplayCommand(cmdType string, params map[string]interface{}) ([][]byte, error)
+}
@@ -0,0 +1,322 @@
+package clienttest
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// storageHelperTypes are the helpers managed with <type>/{list,create,update,delete}
+var storageHelperTypes = map[string]bool{
+	"input_boolean":  true,
+	"input_number":   true,
+	"input_text":     true,
+	"input_select":   true,
+	"input_datetime": true,
+	"input_button":   true,
+	"counter":        true,
+	"timer":          true,
+	"schedule":       true,
+}
+
+// registry describes a collection managed with list/create/update/delete commands
+type registry struct {
+	name string
+	// idField is the item's ID field, paramField the command parameter addressing it
+	idField    string
+	paramField string
+	list       func(d *Data) *[]map[string]interface{}
+}
+
+var registries = map[string]registry{
+	"config/area_registry":  {"Area", "area_id", "area_id", func(d *Data) *[]map[string]interface{} { return &d.Areas }},
+	"config/floor_registry": {"Floor", "floor_id", "floor_id", func(d *Data) *[]map[string]interface{} { return &d.Floors }},
+	"config/label_registry": {"Label", "label_id", "label_id", func(d *Data) *[]map[string]interface{} { return &d.Labels }},
+	"zone":                  {"Zone", "id", "zone_id", func(d *Data) *[]map[string]interface{} { return &d.Zones }},
+	"lovelace/dashboards":   {"Dashboard", "id", "dashboard_id", func(d *Data) *[]map[string]interface{} { return &d.Dashboards }},
+}
+
+// builtinHandler returns the handler for a command the server implements, or nil
+func (s *Server) builtinHandler(cmdType string) CommandHandler {
+	switch cmdType {
+	case "get_states":
+		return s.locked(func(d *Data, _ map[string]interface{}) (interface{}, error) {
+			return d.stateList(), nil
+		})
+	case "get_config":
+		return s.locked(func(d *Data, _ map[string]interface{}) (interface{}, error) {
+			return d.Config, nil
+		})
+	case "get_services":
+		return s.locked(func(d *Data, _ map[string]interface{}) (interface{}, error) {
+			return d.Services, nil
+		})
+	case "call_service":
+		return s.callService
+	case "search/related":
+		return func(map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{}, nil
+		}
+	case "config/device_registry/list":
+		return s.locked(func(d *Data, _ map[string]interface{}) (interface{}, error) {
+			return items(d.Devices), nil
+		})
+	case "config/device_registry/update":
+		return s.locked(func(d *Data, p map[string]interface{}) (interface{}, error) {
+			return update(d.Devices, "Device", "id", "device_id", p)
+		})
+	case "config/entity_registry/list":
+		return s.locked(func(d *Data, _ map[string]interface{}) (interface{}, error) {
+			return items(d.Entities), nil
+		})
+	case "config/entity_registry/get":
+		return s.locked(entityRegistryGet)
+	case "config/entity_registry/update":
+		return s.locked(entityRegistryUpdate)
+	case "lovelace/config":
+		return s.locked(lovelaceConfig)
+	case "lovelace/config/save":
+		return s.locked(lovelaceConfigSave)
+	case "config_entries/get":
+		return s.locked(configEntriesGet)
+	case "config_entries/delete":
+		return s.locked(func(d *Data, p map[string]interface{}) (interface{}, error) {
+			entryID, _ := p["entry_id"].(string)
+			if err := d.deleteConfigEntry(entryID); err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"require_restart": false}, nil
+		})
+	}
+
+	i := strings.LastIndex(cmdType, "/")
+	if i < 0 {
+		return nil
+	}
+	prefix, op := cmdType[:i], cmdType[i+1:]
+
+	if reg, ok := registries[prefix]; ok {
+		return s.registryHandler(reg, op)
+	}
+	if storageHelperTypes[prefix] {
+		return s.helperHandler(prefix, op)
+	}
+	return nil
+}
+
+// locked adapts fn to a CommandHandler that holds the state lock. The result
+// is encoded under the lock so later updates cannot race with sending it.
+func (s *Server) locked(fn func(d *Data, params map[string]interface{}) (interface{}, error)) CommandHandler {
+	return func(params map[string]interface{}) (interface{}, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		result, err := fn(s.data, params)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(data), nil
+	}
+}
+
+func (s *Server) callService(params map[string]interface{}) (interface{}, error) {
+	call := ServiceCall{}
+	call.Domain, _ = params["domain"].(string)
+	call.Service, _ = params["service"].(string)
+	call.Data, _ = params["service_data"].(map[string]interface{})
+	call.Target, _ = params["target"].(map[string]interface{})
+
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	s.mu.Unlock()
+
+	return map[string]interface{}{
+		"context":  map[string]interface{}{"id": "fake-context"},
+		"response": nil,
+	}, nil
+}
+
+func (s *Server) registryHandler(reg registry, op string) CommandHandler {
+	switch op {
+	case "list":
+		return s.locked(func(d *Data, _ map[string]interface{}) (interface{}, error) {
+			return items(*reg.list(d)), nil
+		})
+	case "create":
+		return s.locked(func(d *Data, p map[string]interface{}) (interface{}, error) {
+			list := reg.list(d)
+			base, _ := p["name"].(string)
+			if urlPath, ok := p["url_path"].(string); ok && reg.name == "Dashboard" {
+				base = urlPath
+			}
+			item := copyMap(p)
+			item[reg.idField] = uniqueID(*list, reg.idField, slugify(base))
+			*list = append(*list, item)
+			return item, nil
+		})
+	case "update":
+		return s.locked(func(d *Data, p map[string]interface{}) (interface{}, error) {
+			return update(*reg.list(d), reg.name, reg.idField, reg.paramField, p)
+		})
+	case "delete":
+		return s.locked(func(d *Data, p map[string]interface{}) (interface{}, error) {
+			list := reg.list(d)
+			id, _ := p[reg.paramField].(string)
+			i := find(*list, reg.idField, id)
+			if i < 0 {
+				return nil, notFound(reg.name, id)
+			}
+			*list = append((*list)[:i], (*list)[i+1:]...)
+			return nil, nil
+		})
+	}
+	return nil
+}
+
+func (s *Server) helperHandler(helperType string, op string) CommandHandler {
+	paramField := helperType + "_id"
+
+	switch op {
+	case "list":
+		return s.locked(func(d *Data, _ map[string]interface{}) (interface{}, error) {
+			return items(d.Helpers[helperType]), nil
+		})
+	case "create":
+		return s.locked(func(d *Data, p map[string]interface{}) (interface{}, error) {
+			list := d.Helpers[helperType]
+			name, _ := p["name"].(string)
+			item := copyMap(p)
+			item["id"] = uniqueID(list, "id", slugify(name))
+			d.Helpers[helperType] = append(list, item)
+
+			entityID := helperType + "." + item["id"].(string)
+			d.Entities = append(d.Entities, map[string]interface{}{
+				"entity_id": entityID,
+				"platform":  helperType,
+				"unique_id": item["id"],
+				"name":      nil,
+			})
+			return item, nil
+		})
+	case "update":
+		return s.locked(func(d *Data, p map[string]interface{}) (interface{}, error) {
+			return update(d.Helpers[helperType], helperType, "id", paramField, p)
+		})
+	case "delete":
+		return s.locked(func(d *Data, p map[string]interface{}) (interface{}, error) {
+			list := d.Helpers[helperType]
+			id, _ := p[paramField].(string)
+			i := find(list, "id", id)
+			if i < 0 {
+				return nil, notFound(helperType, id)
+			}
+			d.Helpers[helperType] = append(list[:i], list[i+1:]...)
+			if j := find(d.Entities, "entity_id", helperType+"."+id); j >= 0 {
+				d.Entities = append(d.Entities[:j], d.Entities[j+1:]...)
+			}
+			return nil, nil
+		})
+	}
+	return nil
+}
+
+// update merges params (minus the ID parameter) into the item addressed by paramField
+func update(list []map[string]interface{}, name, idField, paramField string, params map[string]interface{}) (interface{}, error) {
+	id, _ := params[paramField].(string)
+	i := find(list, idField, id)
+	if i < 0 {
+		return nil, notFound(name, id)
+	}
+	for k, v := range params {
+		if k != paramField {
+			list[i][k] = v
+		}
+	}
+	return list[i], nil
+}
+
+func entityRegistryGet(d *Data, p map[string]interface{}) (interface{}, error) {
+	entityID, _ := p["entity_id"].(string)
+	i := find(d.Entities, "entity_id", entityID)
+	if i < 0 {
+		return nil, notFound("Entity", entityID)
+	}
+	return d.Entities[i], nil
+}
+
+func entityRegistryUpdate(d *Data, p map[string]interface{}) (interface{}, error) {
+	entityID, _ := p["entity_id"].(string)
+	i := find(d.Entities, "entity_id", entityID)
+	if i < 0 {
+		return nil, notFound("Entity", entityID)
+	}
+
+	entry := d.Entities[i]
+	for k, v := range p {
+		switch k {
+		case "entity_id":
+		case "new_entity_id":
+			newID, _ := v.(string)
+			entry["entity_id"] = newID
+			if state, ok := d.States[entityID]; ok {
+				delete(d.States, entityID)
+				state["entity_id"] = newID
+				d.States[newID] = state
+			}
+		default:
+			entry[k] = v
+		}
+	}
+	return map[string]interface{}{"entity_entry": entry}, nil
+}
+
+func lovelaceConfig(d *Data, p map[string]interface{}) (interface{}, error) {
+	urlPath, _ := p["url_path"].(string)
+	config, ok := d.Lovelace[urlPath]
+	if !ok {
+		return nil, &Error{Code: "config_not_found", Message: "No config found."}
+	}
+	return config, nil
+}
+
+func lovelaceConfigSave(d *Data, p map[string]interface{}) (interface{}, error) {
+	urlPath, _ := p["url_path"].(string)
+	config, ok := p["config"].(map[string]interface{})
+	if !ok {
+		return nil, &Error{Code: "invalid_format", Message: "config must be an object"}
+	}
+	d.Lovelace[urlPath] = config
+	return nil, nil
+}
+
+func configEntriesGet(d *Data, p map[string]interface{}) (interface{}, error) {
+	domain, _ := p["domain"].(string)
+	result := make([]interface{}, 0, len(d.ConfigEntries))
+	for _, entry := range d.ConfigEntries {
+		if domain != "" && entry["domain"] != domain {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// deleteConfigEntry removes a config entry and the registry entries that belong to it
+func (d *Data) deleteConfigEntry(entryID string) error {
+	i := find(d.ConfigEntries, "entry_id", entryID)
+	if i < 0 {
+		return notFound("Config entry", entryID)
+	}
+	d.ConfigEntries = append(d.ConfigEntries[:i], d.ConfigEntries[i+1:]...)
+
+	entities := d.Entities[:0]
+	for _, entity := range d.Entities {
+		if entity["config_entry_id"] != entryID {
+			entities = append(entities, entity)
+		}
+	}
+	d.Entities = entities
+	return nil
+}
@@ -0,0 +1,128 @@
+package clienttest
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Data is the in-memory state served by a Server.
+// Seed it before running commands; use Server.Update while the server is in use.
+type Data struct {
+	// Config is returned by get_config and GET /api/config
+	Config map[string]interface{}
+	// States maps entity_id to a state object (entity_id, state, attributes, ...)
+	States map[string]map[string]interface{}
+	// Services maps domain to its services, as returned by get_services
+	Services map[string]interface{}
+
+	Areas    []map[string]interface{}
+	Floors   []map[string]interface{}
+	Labels   []map[string]interface{}
+	Devices  []map[string]interface{}
+	Entities []map[string]interface{}
+	Zones    []map[string]interface{}
+
+	// Dashboards is the lovelace/dashboards/list result
+	Dashboards []map[string]interface{}
+	// Lovelace maps a dashboard url_path to its config; "" is the default dashboard
+	Lovelace map[string]map[string]interface{}
+
+	// Automations and Scripts map an id to the config served under /api/config/{automation,script}/config/<id>
+	Automations map[string]map[string]interface{}
+	Scripts     map[string]map[string]interface{}
+
+	// Helpers maps a storage helper type (input_boolean, counter, ...) to its items
+	Helpers map[string][]map[string]interface{}
+
+	ConfigEntries []map[string]interface{}
+
+	// ErrorLog is returned by GET /api/error_log
+	ErrorLog string
+}
+
+// NewData returns empty state with a minimal core config
+func NewData() *Data {
+	return &Data{
+		Config: map[string]interface{}{
+			"location_name": "Test Home",
+			"version":       Version,
+			"state":         "RUNNING",
+			"time_zone":     "UTC",
+			"unit_system": map[string]interface{}{
+				"temperature": "°C",
+			},
+		},
+		States:      make(map[string]map[string]interface{}),
+		Services:    make(map[string]interface{}),
+		Lovelace:    make(map[string]map[string]interface{}),
+		Automations: make(map[string]map[string]interface{}),
+		Scripts:     make(map[string]map[string]interface{}),
+		Helpers:     make(map[string][]map[string]interface{}),
+	}
+}
+
+// stateList returns the states sorted by entity_id
+func (d *Data) stateList() []interface{} {
+	ids := make([]string, 0, len(d.States))
+	for id := range d.States {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	list := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, d.States[id])
+	}
+	return list
+}
+
+// items converts a collection to the []interface{} shape of a JSON array
+func items(list []map[string]interface{}) []interface{} {
+	result := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		result = append(result, item)
+	}
+	return result
+}
+
+// find returns the index of the item whose idField equals id, or -1
+func find(list []map[string]interface{}, idField, id string) int {
+	for i, item := range list {
+		if s, _ := item[idField].(string); s == id {
+			return i
+		}
+	}
+	return -1
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify derives an ID from a name the way Home Assistant does for registry entries
+func slugify(name string) string {
+	slug := nonSlugChars.ReplaceAllString(strings.ToLower(name), "_")
+	slug = strings.Trim(slug, "_")
+	if slug == "" {
+		slug = "unnamed"
+	}
+	return slug
+}
+
+// uniqueID returns base, or base_2, base_3, ... if base is already taken
+func uniqueID(list []map[string]interface{}, idField, base string) string {
+	id := base
+	for n := 2; find(list, idField, id) >= 0; n++ {
+		id = base + "_" + strconv.Itoa(n)
+	}
+	return id
+}
+
+// copyMap returns a shallow copy of m
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
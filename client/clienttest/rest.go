@@ -0,0 +1,219 @@
+package clienttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// handleREST serves the /api/ endpoints used by hab
+func (s *Server) handleREST(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"message": "Unauthorized"})
+		return
+	}
+
+	var body map[string]interface{}
+	if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+		if err := json.Unmarshal(data, &body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": "Invalid JSON specified."})
+			return
+		}
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case path == "":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"message": "API running."})
+	case path == "config" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		writeJSON(w, http.StatusOK, s.data.Config)
+	case path == "states" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		writeJSON(w, http.StatusOK, s.data.stateList())
+	case parts[0] == "states" && len(parts) == 2:
+		s.handleRESTState(w, r, parts[1], body)
+	case path == "services" && r.Method == http.MethodGet:
+		s.handleRESTServices(w)
+	case parts[0] == "services" && len(parts) == 3 && r.Method == http.MethodPost:
+		s.mu.Lock()
+		s.calls = append(s.calls, ServiceCall{Domain: parts[1], Service: parts[2], Data: body})
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, []interface{}{})
+	case path == "config/core/check_config" && r.Method == http.MethodPost:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"result": "valid", "errors": nil, "warnings": nil})
+	case path == "error_log" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, s.data.ErrorLog)
+	case parts[0] == "history" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, []interface{}{})
+	case len(parts) == 4 && parts[0] == "config" && parts[2] == "config" && (parts[1] == "automation" || parts[1] == "script"):
+		s.handleRESTConfig(w, r, parts[1], parts[3], body)
+	case path == "config/config_entries/flow" && r.Method == http.MethodPost:
+		s.handleRESTFlowCreate(w, body)
+	case len(parts) == 4 && strings.HasPrefix(path, "config/config_entries/flow/") && r.Method == http.MethodPost:
+		s.handleRESTFlowStep(w, parts[3], body)
+	case len(parts) == 4 && strings.HasPrefix(path, "config/config_entries/entry/") && r.Method == http.MethodDelete:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err := s.data.deleteConfigEntry(parts[3]); err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"require_restart": false})
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not found"})
+	}
+}
+
+func (s *Server) handleRESTState(w http.ResponseWriter, r *http.Request, entityID string, body map[string]interface{}) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		state, ok := s.data.States[entityID]
+		s.mu.Unlock()
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Entity not found."})
+			return
+		}
+		writeJSON(w, http.StatusOK, state)
+	case http.MethodPost:
+		state, _ := body["state"].(string)
+		attributes, _ := body["attributes"].(map[string]interface{})
+		s.SetState(entityID, state, attributes)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		writeJSON(w, http.StatusOK, s.data.States[entityID])
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"message": "Method not allowed"})
+	}
+}
+
+// handleRESTServices serves the services as the REST list of {domain, services}
+func (s *Server) handleRESTServices(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	domains := make([]string, 0, len(s.data.Services))
+	for domain := range s.data.Services {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	result := make([]interface{}, 0, len(domains))
+	for _, domain := range domains {
+		result = append(result, map[string]interface{}{
+			"domain":   domain,
+			"services": s.data.Services[domain],
+		})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleRESTConfig serves /api/config/{automation,script}/config/<id>
+func (s *Server) handleRESTConfig(w http.ResponseWriter, r *http.Request, kind, id string, body map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	configs := s.data.Automations
+	if kind == "script" {
+		configs = s.data.Scripts
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		config, ok := configs[id]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Resource not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, config)
+	case http.MethodPost:
+		if body == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": "Message malformed: expected a dictionary"})
+			return
+		}
+		configs[id] = body
+		writeJSON(w, http.StatusOK, map[string]interface{}{"result": "ok"})
+	case http.MethodDelete:
+		if _, ok := configs[id]; !ok {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Resource not found"})
+			return
+		}
+		delete(configs, id)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"result": "ok"})
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"message": "Method not allowed"})
+	}
+}
+
+// handleRESTFlowCreate starts a single-step config flow for a helper integration
+func (s *Server) handleRESTFlowCreate(w http.ResponseWriter, body map[string]interface{}) {
+	handler, _ := body["handler"].(string)
+
+	s.mu.Lock()
+	s.nextID++
+	flowID := fmt.Sprintf("flow_%d", s.nextID)
+	s.flows[flowID] = handler
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"type":        "form",
+		"flow_id":     flowID,
+		"handler":     handler,
+		"step_id":     "user",
+		"data_schema": []interface{}{},
+		"errors":      map[string]interface{}{},
+	})
+}
+
+// handleRESTFlowStep finishes a config flow, creating a config entry titled by the submitted name
+func (s *Server) handleRESTFlowStep(w http.ResponseWriter, flowID string, body map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handler, ok := s.flows[flowID]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Invalid flow specified"})
+		return
+	}
+	delete(s.flows, flowID)
+
+	title, _ := body["name"].(string)
+	if title == "" {
+		title = handler
+	}
+	s.nextID++
+	entry := map[string]interface{}{
+		"entry_id": fmt.Sprintf("entry_%d", s.nextID),
+		"domain":   handler,
+		"title":    title,
+		"source":   "user",
+		"state":    "loaded",
+		"options":  body,
+	}
+	s.data.ConfigEntries = append(s.data.ConfigEntries, entry)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"type":    "create_entry",
+		"flow_id": flowID,
+		"handler": handler,
+		"title":   title,
+		"result":  entry,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,396 @@
+// Package clienttest provides an in-process fake Home Assistant server.
+//
+// The server speaks the WebSocket auth handshake and the registry, lovelace,
+// helper and config entry commands, and serves the REST endpoints used by
+// hab, all from in-memory state. Point commands at it with Server.Client.
+package clienttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/home-assistant/hab/client"
+)
+
+// Version is the Home Assistant version the server reports
+const Version = "2024.12.0"
+
+// DefaultToken is the access token accepted by a new Server
+const DefaultToken = "test-token"
+
+// CommandHandler answers a WebSocket command. Returning an *Error sets the
+// error code sent to the client.
+type CommandHandler func(params map[string]interface{}) (interface{}, error)
+
+// Error is a command error with a Home Assistant error code
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ServiceCall is a service call received over WebSocket or REST
+type ServiceCall struct {
+	Domain  string
+	Service string
+	Data    map[string]interface{}
+	Target  map[string]interface{}
+}
+
+// Server is a fake Home Assistant instance backed by Data
+type Server struct {
+	URL   string
+	Token string
+
+	mu       sync.Mutex
+	data     *Data
+	handlers map[string]CommandHandler
+	calls    []ServiceCall
+	conns    map[*conn]struct{}
+	flows    map[string]string
+	nextID   int
+
+	http     *httptest.Server
+	upgrader websocket.Upgrader
+}
+
+// conn is one authenticated WebSocket connection
+type conn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+	subsMu  sync.Mutex
+	subs    map[int]subscriptionKind
+}
+
+// subscriptionKind is what a connection subscribed to
+type subscriptionKind struct {
+	cmdType   string
+	eventType string
+	entityIDs map[string]bool
+}
+
+// NewServer starts a server serving data; nil starts with NewData().
+// Callers must Close the server.
+func NewServer(data *Data) *Server {
+	if data == nil {
+		data = NewData()
+	}
+	s := &Server{
+		Token:    DefaultToken,
+		data:     data,
+		handlers: make(map[string]CommandHandler),
+		conns:    make(map[*conn]struct{}),
+		flows:    make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/websocket", s.handleWebSocket)
+	mux.HandleFunc("/api/", s.handleREST)
+	s.http = httptest.NewServer(mux)
+	s.URL = s.http.URL
+	return s
+}
+
+// Close drops all connections and shuts the server down
+func (s *Server) Close() {
+	s.DropConnections()
+	s.http.Close()
+}
+
+// Client returns an HAClient for the server bound to ctx
+func (s *Server) Client(ctx context.Context) client.HAClient {
+	return client.NewInstance(ctx, s.URL, s.Token)
+}
+
+// Update runs fn with exclusive access to the server state
+func (s *Server) Update(fn func(d *Data)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.data)
+}
+
+// Handle registers a handler for a WebSocket command type, replacing the
+// built-in one if there is one
+func (s *Server) Handle(cmdType string, handler CommandHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[cmdType] = handler
+}
+
+// Calls returns the service calls received so far
+func (s *Server) Calls() []ServiceCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ServiceCall(nil), s.calls...)
+}
+
+// DropConnections closes every open WebSocket connection without a close
+// frame, as a Home Assistant restart would
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		c.ws.Close()
+	}
+}
+
+// SetState sets the state of an entity and notifies subscribers with a
+// state_changed event and a subscribe_entities update
+func (s *Server) SetState(entityID, state string, attributes map[string]interface{}) {
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+	newState := map[string]interface{}{
+		"entity_id":  entityID,
+		"state":      state,
+		"attributes": attributes,
+	}
+
+	s.mu.Lock()
+	oldState := s.data.States[entityID]
+	s.data.States[entityID] = newState
+	s.mu.Unlock()
+
+	s.FireEvent("state_changed", map[string]interface{}{
+		"entity_id": entityID,
+		"old_state": oldState,
+		"new_state": newState,
+	})
+
+	compressed := map[string]interface{}{"s": state, "a": attributes}
+	var update map[string]interface{}
+	if oldState == nil {
+		update = map[string]interface{}{"a": map[string]interface{}{entityID: compressed}}
+	} else {
+		update = map[string]interface{}{"c": map[string]interface{}{entityID: map[string]interface{}{"+": compressed}}}
+	}
+	s.broadcast(func(sub subscriptionKind) interface{} {
+		if sub.cmdType != "subscribe_entities" || (sub.entityIDs != nil && !sub.entityIDs[entityID]) {
+			return nil
+		}
+		return update
+	})
+}
+
+// FireEvent sends an event to every subscribe_events subscriber of eventType
+func (s *Server) FireEvent(eventType string, data map[string]interface{}) {
+	event := map[string]interface{}{
+		"event_type": eventType,
+		"data":       data,
+		"origin":     "LOCAL",
+	}
+	s.broadcast(func(sub subscriptionKind) interface{} {
+		if sub.cmdType != "subscribe_events" || (sub.eventType != "" && sub.eventType != eventType) {
+			return nil
+		}
+		return event
+	})
+}
+
+// broadcast sends the event returned by fn to each matching subscription
+func (s *Server) broadcast(fn func(sub subscriptionKind) interface{}) {
+	s.mu.Lock()
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		c.subsMu.Lock()
+		var msgs []map[string]interface{}
+		for id, sub := range c.subs {
+			if event := fn(sub); event != nil {
+				msgs = append(msgs, map[string]interface{}{"id": id, "type": "event", "event": event})
+			}
+		}
+		c.subsMu.Unlock()
+
+		for _, msg := range msgs {
+			c.write(msg)
+		}
+	}
+}
+
+func (c *conn) write(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.WriteJSON(v)
+}
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &conn{ws: ws, subs: make(map[int]subscriptionKind)}
+	defer ws.Close()
+
+	if !s.authenticate(c) {
+		return
+	}
+
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+	}()
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return
+		}
+		s.handleCommand(c, msg)
+	}
+}
+
+// authenticate runs the auth_required / auth / auth_ok handshake
+func (s *Server) authenticate(c *conn) bool {
+	if err := c.write(map[string]interface{}{"type": "auth_required", "ha_version": Version}); err != nil {
+		return false
+	}
+
+	var msg struct {
+		Type        string `json:"type"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := c.ws.ReadJSON(&msg); err != nil {
+		return false
+	}
+	if msg.Type != "auth" || msg.AccessToken != s.Token {
+		c.write(map[string]interface{}{"type": "auth_invalid", "message": "Invalid access token or password"})
+		return false
+	}
+	return c.write(map[string]interface{}{"type": "auth_ok", "ha_version": Version}) == nil
+}
+
+func (s *Server) handleCommand(c *conn, msg map[string]interface{}) {
+	id := toInt(msg["id"])
+	cmdType, _ := msg["type"].(string)
+	params := copyMap(msg)
+	delete(params, "id")
+	delete(params, "type")
+
+	switch cmdType {
+	case "ping":
+		c.write(map[string]interface{}{"id": id, "type": "pong"})
+		return
+	case "subscribe_events", "subscribe_entities":
+		s.subscribe(c, id, cmdType, params)
+		return
+	case "unsubscribe_events":
+		c.subsMu.Lock()
+		subID := toInt(params["subscription"])
+		_, ok := c.subs[subID]
+		delete(c.subs, subID)
+		c.subsMu.Unlock()
+		if !ok {
+			c.write(errorResult(id, &Error{Code: "not_found", Message: "Subscription not found."}))
+			return
+		}
+		c.write(successResult(id, nil))
+		return
+	}
+
+	s.mu.Lock()
+	handler, ok := s.handlers[cmdType]
+	s.mu.Unlock()
+	if !ok {
+		handler = s.builtinHandler(cmdType)
+	}
+	if handler == nil {
+		c.write(errorResult(id, &Error{Code: "unknown_command", Message: "Unknown command."}))
+		return
+	}
+
+	result, err := handler(params)
+	if err != nil {
+		c.write(errorResult(id, err))
+		return
+	}
+	c.write(successResult(id, result))
+}
+
+// subscribe registers a subscription and sends its result and, for
+// subscribe_entities, the initial states
+func (s *Server) subscribe(c *conn, id int, cmdType string, params map[string]interface{}) {
+	sub := subscriptionKind{cmdType: cmdType}
+	sub.eventType, _ = params["event_type"].(string)
+	if ids, ok := params["entity_ids"].([]interface{}); ok {
+		sub.entityIDs = make(map[string]bool)
+		for _, v := range ids {
+			if entityID, ok := v.(string); ok {
+				sub.entityIDs[entityID] = true
+			}
+		}
+	}
+
+	c.subsMu.Lock()
+	c.subs[id] = sub
+	c.subsMu.Unlock()
+	c.write(successResult(id, nil))
+
+	if cmdType != "subscribe_entities" {
+		return
+	}
+
+	initial := make(map[string]interface{})
+	s.mu.Lock()
+	for entityID, state := range s.data.States {
+		if sub.entityIDs != nil && !sub.entityIDs[entityID] {
+			continue
+		}
+		initial[entityID] = map[string]interface{}{"s": state["state"], "a": state["attributes"]}
+	}
+	s.mu.Unlock()
+	c.write(map[string]interface{}{"id": id, "type": "event", "event": map[string]interface{}{"a": initial}})
+}
+
+func successResult(id int, result interface{}) map[string]interface{} {
+	return map[string]interface{}{"id": id, "type": "result", "success": true, "result": result}
+}
+
+func errorResult(id int, err error) map[string]interface{} {
+	code := "home_assistant_error"
+	if e, ok := err.(*Error); ok {
+		code = e.Code
+	}
+	return map[string]interface{}{
+		"id":      id,
+		"type":    "result",
+		"success": false,
+		"error":   map[string]interface{}{"code": code, "message": err.Error()},
+	}
+}
+
+func notFound(what, id string) error {
+	return &Error{Code: "not_found", Message: fmt.Sprintf("%s not found: %s", what, id)}
+}
+
+func toInt(v interface{}) int {
+	if f, ok := v.(float64); ok {
+		return int(f)
+	}
+	return 0
+}
@@ -0,0 +1,192 @@
+package client
+
+import (
+	"math"
+	"time"
+)
+
+// EntityChange describes how a single entity changed in a subscribe_entities event
+type EntityChange struct {
+	EntityID string
+	// Old is nil when the entity was added
+	Old map[string]interface{}
+	// New is nil when the entity was removed
+	New map[string]interface{}
+}
+
+// StateChanged reports whether the state value itself changed (as opposed to
+// only attributes or timestamps)
+func (ch EntityChange) StateChanged() bool {
+	if ch.Old == nil || ch.New == nil {
+		return true
+	}
+	return ch.Old["state"] != ch.New["state"]
+}
+
+// SubscribeEntities subscribes to compressed entity state updates.
+// When entityIDs is empty all entities are included. The first event
+// contains every current state as additions; use EntityStates to decode.
+func (c *WebSocketClient) SubscribeEntities(entityIDs []string) (<-chan Event, func() error, error) {
+	var params map[string]interface{}
+	if len(entityIDs) > 0 {
+		params = map[string]interface{}{"entity_ids": entityIDs}
+	}
+	return c.Subscribe("subscribe_entities", params)
+}
+
+// EntityStates tracks full entity states from the compressed diff format
+// used by subscribe_entities
+type EntityStates map[string]map[string]interface{}
+
+// Apply merges a subscribe_entities event and returns the resulting changes.
+// The event holds additions ("a"), changes ("c") and removals ("r").
+func (s EntityStates) Apply(event Event) []EntityChange {
+	var changes []EntityChange
+
+	if additions, ok := event["a"].(map[string]interface{}); ok {
+		for entityID, v := range additions {
+			compressed, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			old := s[entityID]
+			state := decompressState(entityID, compressed)
+			s[entityID] = state
+			changes = append(changes, EntityChange{EntityID: entityID, Old: old, New: state})
+		}
+	}
+
+	if diffs, ok := event["c"].(map[string]interface{}); ok {
+		for entityID, v := range diffs {
+			diff, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			old, exists := s[entityID]
+			if !exists {
+				continue
+			}
+			state := applyStateDiff(old, diff)
+			s[entityID] = state
+			changes = append(changes, EntityChange{EntityID: entityID, Old: old, New: state})
+		}
+	}
+
+	if removals, ok := event["r"].([]interface{}); ok {
+		for _, v := range removals {
+			entityID, ok := v.(string)
+			if !ok {
+				continue
+			}
+			old, exists := s[entityID]
+			if !exists {
+				continue
+			}
+			delete(s, entityID)
+			changes = append(changes, EntityChange{EntityID: entityID, Old: old})
+		}
+	}
+
+	return changes
+}
+
+// decompressState expands a compressed state ({s, a, c, lc, lu}) into the
+// regular state object format
+func decompressState(entityID string, compressed map[string]interface{}) map[string]interface{} {
+	attrs, _ := compressed["a"].(map[string]interface{})
+	if attrs == nil {
+		attrs = make(map[string]interface{})
+	}
+
+	lastChanged := formatCompressedTime(compressed["lc"])
+	lastUpdated := lastChanged
+	if _, ok := compressed["lu"]; ok {
+		lastUpdated = formatCompressedTime(compressed["lu"])
+	}
+
+	return map[string]interface{}{
+		"entity_id":    entityID,
+		"state":        compressed["s"],
+		"attributes":   attrs,
+		"context":      decompressContext(nil, compressed["c"]),
+		"last_changed": lastChanged,
+		"last_updated": lastUpdated,
+	}
+}
+
+// applyStateDiff returns a copy of old with a {"+": {...}, "-": {"a": [...]}} diff applied
+func applyStateDiff(old map[string]interface{}, diff map[string]interface{}) map[string]interface{} {
+	state := make(map[string]interface{}, len(old))
+	for k, v := range old {
+		state[k] = v
+	}
+	attrs := make(map[string]interface{})
+	if oldAttrs, ok := old["attributes"].(map[string]interface{}); ok {
+		for k, v := range oldAttrs {
+			attrs[k] = v
+		}
+	}
+
+	if add, ok := diff["+"].(map[string]interface{}); ok {
+		if s, ok := add["s"]; ok {
+			state["state"] = s
+		}
+		if c, ok := add["c"]; ok {
+			oldContext, _ := old["context"].(map[string]interface{})
+			state["context"] = decompressContext(oldContext, c)
+		}
+		// A new last_changed implies last_updated moved with it
+		if lc, ok := add["lc"]; ok {
+			state["last_changed"] = formatCompressedTime(lc)
+			state["last_updated"] = state["last_changed"]
+		} else if lu, ok := add["lu"]; ok {
+			state["last_updated"] = formatCompressedTime(lu)
+		}
+		if a, ok := add["a"].(map[string]interface{}); ok {
+			for k, v := range a {
+				attrs[k] = v
+			}
+		}
+	}
+
+	if remove, ok := diff["-"].(map[string]interface{}); ok {
+		if keys, ok := remove["a"].([]interface{}); ok {
+			for _, k := range keys {
+				if key, ok := k.(string); ok {
+					delete(attrs, key)
+				}
+			}
+		}
+	}
+
+	state["attributes"] = attrs
+	return state
+}
+
+// decompressContext expands a context that is either a bare context ID or a
+// partial context object, merged over the previous context
+func decompressContext(old map[string]interface{}, v interface{}) map[string]interface{} {
+	context := make(map[string]interface{})
+	for k, val := range old {
+		context[k] = val
+	}
+	switch c := v.(type) {
+	case string:
+		context["id"] = c
+	case map[string]interface{}:
+		for k, val := range c {
+			context[k] = val
+		}
+	}
+	return context
+}
+
+// formatCompressedTime converts a unix timestamp in seconds to RFC 3339
+func formatCompressedTime(v interface{}) string {
+	ts, ok := v.(float64)
+	if !ok {
+		return ""
+	}
+	sec, frac := math.Modf(ts)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestEntityStatesApply(t *testing.T) {
+	// 1700000000.5 is 2023-11-14T22:13:20.5Z
+	initial := Event{"a": map[string]interface{}{
+		"light.kitchen": map[string]interface{}{
+			"s": "off", "a": map[string]interface{}{"friendly_name": "Kitchen", "brightness": nil},
+			"c": "ctx1", "lc": 1700000000.5,
+		},
+		"sensor.power": map[string]interface{}{
+			"s": "12.5", "a": map[string]interface{}{"unit_of_measurement": "W"},
+			"c": map[string]interface{}{"id": "ctx2", "user_id": "u1"}, "lc": 1700000000.0, "lu": 1700000010.0,
+		},
+	}}
+
+	tests := []struct {
+		name    string
+		event   Event
+		changes []string
+		check   func(t *testing.T, s EntityStates)
+	}{
+		{
+			name:    "additions",
+			event:   initial,
+			changes: []string{"light.kitchen", "sensor.power"},
+			check: func(t *testing.T, s EntityStates) {
+				want := map[string]interface{}{
+					"entity_id":    "light.kitchen",
+					"state":        "off",
+					"attributes":   map[string]interface{}{"friendly_name": "Kitchen", "brightness": nil},
+					"context":      map[string]interface{}{"id": "ctx1"},
+					"last_changed": "2023-11-14T22:13:20.5Z",
+					"last_updated": "2023-11-14T22:13:20.5Z",
+				}
+				if !reflect.DeepEqual(s["light.kitchen"], want) {
+					t.Errorf("light.kitchen = %v, want %v", s["light.kitchen"], want)
+				}
+				if got := s["sensor.power"]["last_updated"]; got != "2023-11-14T22:13:30Z" {
+					t.Errorf("last_updated = %v, want lu", got)
+				}
+				if got := s["sensor.power"]["context"]; !reflect.DeepEqual(got, map[string]interface{}{"id": "ctx2", "user_id": "u1"}) {
+					t.Errorf("context = %v", got)
+				}
+			},
+		},
+		{
+			name: "state change",
+			event: Event{"c": map[string]interface{}{
+				"light.kitchen": map[string]interface{}{
+					"+": map[string]interface{}{"s": "on", "a": map[string]interface{}{"brightness": 255.0}, "c": "ctx3", "lc": 1700000100.0},
+				},
+			}},
+			changes: []string{"light.kitchen"},
+			check: func(t *testing.T, s EntityStates) {
+				state := s["light.kitchen"]
+				if state["state"] != "on" {
+					t.Errorf("state = %v", state["state"])
+				}
+				attrs := state["attributes"].(map[string]interface{})
+				if attrs["brightness"] != 255.0 || attrs["friendly_name"] != "Kitchen" {
+					t.Errorf("attributes = %v", attrs)
+				}
+				if state["last_changed"] != "2023-11-14T22:15:00Z" || state["last_updated"] != state["last_changed"] {
+					t.Errorf("times = %v, %v", state["last_changed"], state["last_updated"])
+				}
+				if state["context"].(map[string]interface{})["id"] != "ctx3" {
+					t.Errorf("context = %v", state["context"])
+				}
+			},
+		},
+		{
+			name: "attribute removal and partial context",
+			event: Event{"c": map[string]interface{}{
+				"sensor.power": map[string]interface{}{
+					"+": map[string]interface{}{"lu": 1700000200.0, "c": map[string]interface{}{"id": "ctx4"}},
+					"-": map[string]interface{}{"a": []interface{}{"unit_of_measurement"}},
+				},
+			}},
+			changes: []string{"sensor.power"},
+			check: func(t *testing.T, s EntityStates) {
+				state := s["sensor.power"]
+				if attrs := state["attributes"].(map[string]interface{}); len(attrs) != 0 {
+					t.Errorf("attributes = %v, want none", attrs)
+				}
+				if state["state"] != "12.5" || state["last_changed"] != "2023-11-14T22:13:20Z" || state["last_updated"] != "2023-11-14T22:16:40Z" {
+					t.Errorf("state = %v", state)
+				}
+				// The partial context is merged over the previous one
+				if got := state["context"]; !reflect.DeepEqual(got, map[string]interface{}{"id": "ctx4", "user_id": "u1"}) {
+					t.Errorf("context = %v", got)
+				}
+			},
+		},
+		{
+			name:    "change of an unknown entity",
+			event:   Event{"c": map[string]interface{}{"light.unknown": map[string]interface{}{"+": map[string]interface{}{"s": "on"}}}},
+			changes: nil,
+			check: func(t *testing.T, s EntityStates) {
+				if _, ok := s["light.unknown"]; ok {
+					t.Error("a change added an entity")
+				}
+			},
+		},
+		{
+			name:    "removal",
+			event:   Event{"r": []interface{}{"sensor.power", "sensor.unknown"}},
+			changes: []string{"sensor.power"},
+			check: func(t *testing.T, s EntityStates) {
+				if _, ok := s["sensor.power"]; ok {
+					t.Error("sensor.power was not removed")
+				}
+				if len(s) != 1 {
+					t.Errorf("states = %v", s)
+				}
+			},
+		},
+	}
+
+	// The events apply in order, each to the states the previous ones left
+	states := EntityStates{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changed []string
+			for _, ch := range states.Apply(tt.event) {
+				changed = append(changed, ch.EntityID)
+			}
+			sort.Strings(changed)
+			if !reflect.DeepEqual(changed, tt.changes) {
+				t.Errorf("changes = %v, want %v", changed, tt.changes)
+			}
+			tt.check(t, states)
+		})
+	}
+}
+
+func TestEntityChangeStateChanged(t *testing.T) {
+	on := map[string]interface{}{"state": "on"}
+	off := map[string]interface{}{"state": "off"}
+	tests := []struct {
+		name   string
+		change EntityChange
+		want   bool
+	}{
+		{name: "added", change: EntityChange{New: on}, want: true},
+		{name: "removed", change: EntityChange{Old: on}, want: true},
+		{name: "state", change: EntityChange{Old: off, New: on}, want: true},
+		{name: "attributes only", change: EntityChange{Old: on, New: map[string]interface{}{"state": "on", "attributes": map[string]interface{}{"x": 1}}}, want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.change.StateChanged(); got != tt.want {
+			t.Errorf("%s: StateChanged() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
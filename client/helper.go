@@ -10,8 +10,12 @@ import (
 	"golang.org/x/term"
 )
 
-// BuildURL constructs an API URL from base URL and endpoint
+// BuildURL constructs an API URL from base URL and endpoint.
+// An empty base URL means the Supervisor proxy when running in an add-on.
 func BuildURL(baseURL, endpoint string) (string, error) {
+	if baseURL == "" && SupervisorToken() != "" {
+		baseURL = SupervisorURL
+	}
 	base := strings.TrimRight(baseURL, "/")
 
 	// Ensure scheme
@@ -36,8 +40,18 @@ func BuildURL(baseURL, endpoint string) (string, error) {
 	return parsed.String(), nil
 }
 
-// BuildWebSocketURL converts an HTTP URL to WebSocket URL
+// BuildWebSocketURL converts an HTTP URL to WebSocket URL.
+// An empty base URL means the Supervisor proxy when running in an add-on.
 func BuildWebSocketURL(baseURL string) (string, error) {
+	if baseURL == "" && SupervisorToken() != "" {
+		baseURL = SupervisorURL
+	}
+	// The Supervisor proxies the WebSocket API at /core/websocket
+	if IsSupervisorURL(baseURL) {
+		log.WithField("websocket_url", SupervisorWebSocketURL).Debug("Built WebSocket URL")
+		return SupervisorWebSocketURL, nil
+	}
+
 	base := strings.TrimRight(baseURL, "/")
 
 	// Convert scheme
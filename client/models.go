@@ -0,0 +1,396 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Typed models for Home Assistant objects.
+//
+// Every model keeps the fields it does not know about in Extra, so an object
+// that is read, modified and sent back does not lose data. String fields
+// tagged "nullable" are encoded as null when empty, matching how Home
+// Assistant represents unset values; other fields tagged "nullable" have no
+// omitempty, so a nil value is encoded as null as well.
+
+// State is the state of an entity
+type State struct {
+	EntityID    string                 `json:"entity_id"`
+	State       string                 `json:"state"`
+	Attributes  map[string]interface{} `json:"attributes"`
+	LastChanged string                 `json:"last_changed,nullable"`
+	LastUpdated string                 `json:"last_updated,nullable"`
+	Context     map[string]interface{} `json:"context,nullable"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Domain returns the domain part of the entity ID
+func (s State) Domain() string {
+	return entityDomain(s.EntityID)
+}
+
+// FriendlyName returns the friendly_name attribute, if set
+func (s State) FriendlyName() string {
+	name, _ := s.Attributes["friendly_name"].(string)
+	return name
+}
+
+// EntityRegistryEntry is an entry of the entity registry
+type EntityRegistryEntry struct {
+	EntityID            string   `json:"entity_id"`
+	ID                  string   `json:"id,omitempty"`
+	UniqueID            string   `json:"unique_id,omitempty"`
+	Platform            string   `json:"platform"`
+	Name                string   `json:"name,nullable"`
+	OriginalName        string   `json:"original_name,nullable"`
+	Icon                string   `json:"icon,nullable"`
+	DeviceID            string   `json:"device_id,nullable"`
+	AreaID              string   `json:"area_id,nullable"`
+	ConfigEntryID       string   `json:"config_entry_id,nullable"`
+	DisabledBy          string   `json:"disabled_by,nullable"`
+	HiddenBy            string   `json:"hidden_by,nullable"`
+	EntityCategory      string   `json:"entity_category,nullable"`
+	DeviceClass         string   `json:"device_class,nullable"`
+	OriginalDeviceClass string   `json:"original_device_class,nullable"`
+	Labels              []string `json:"labels"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Domain returns the domain part of the entity ID
+func (e EntityRegistryEntry) Domain() string {
+	return entityDomain(e.EntityID)
+}
+
+// IsDisabled reports whether the entity is disabled
+func (e EntityRegistryEntry) IsDisabled() bool {
+	return e.DisabledBy != ""
+}
+
+// HasLabel reports whether the entity has the label
+func (e EntityRegistryEntry) HasLabel(labelID string) bool {
+	return containsString(e.Labels, labelID)
+}
+
+// DeviceEntry is an entry of the device registry
+type DeviceEntry struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name,nullable"`
+	NameByUser    string   `json:"name_by_user,nullable"`
+	Manufacturer  string   `json:"manufacturer,nullable"`
+	Model         string   `json:"model,nullable"`
+	SWVersion     string   `json:"sw_version,nullable"`
+	HWVersion     string   `json:"hw_version,nullable"`
+	SerialNumber  string   `json:"serial_number,nullable"`
+	AreaID        string   `json:"area_id,nullable"`
+	ViaDeviceID   string   `json:"via_device_id,nullable"`
+	DisabledBy    string   `json:"disabled_by,nullable"`
+	EntryType     string   `json:"entry_type,nullable"`
+	ConfigEntries []string `json:"config_entries"`
+	Labels        []string `json:"labels"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// DisplayName returns the user-assigned name, falling back to the device name
+func (d DeviceEntry) DisplayName() string {
+	if d.NameByUser != "" {
+		return d.NameByUser
+	}
+	return d.Name
+}
+
+// Area is an entry of the area registry
+type Area struct {
+	AreaID  string   `json:"area_id"`
+	Name    string   `json:"name"`
+	FloorID string   `json:"floor_id,nullable"`
+	Icon    string   `json:"icon,nullable"`
+	Picture string   `json:"picture,nullable"`
+	Aliases []string `json:"aliases"`
+	Labels  []string `json:"labels"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Floor is an entry of the floor registry
+type Floor struct {
+	FloorID string   `json:"floor_id"`
+	Name    string   `json:"name"`
+	Level   *int     `json:"level"`
+	Icon    string   `json:"icon,nullable"`
+	Aliases []string `json:"aliases"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Label is an entry of the label registry
+type Label struct {
+	LabelID     string `json:"label_id"`
+	Name        string `json:"name"`
+	Color       string `json:"color,nullable"`
+	Icon        string `json:"icon,nullable"`
+	Description string `json:"description,nullable"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Zone is a storage-managed zone
+type Zone struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Radius    float64 `json:"radius"`
+	Icon      string  `json:"icon,nullable"`
+	Passive   bool    `json:"passive"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// ConfigEntry is an integration config entry
+type ConfigEntry struct {
+	EntryID         string `json:"entry_id"`
+	Domain          string `json:"domain"`
+	Title           string `json:"title"`
+	Source          string `json:"source"`
+	State           string `json:"state"`
+	SupportsOptions bool   `json:"supports_options"`
+	DisabledBy      string `json:"disabled_by,nullable"`
+	Reason          string `json:"reason,nullable"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Dashboard is a lovelace dashboard
+type Dashboard struct {
+	ID            string `json:"id"`
+	URLPath       string `json:"url_path"`
+	Title         string `json:"title"`
+	Icon          string `json:"icon,nullable"`
+	Mode          string `json:"mode"`
+	ShowInSidebar bool   `json:"show_in_sidebar"`
+	RequireAdmin  bool   `json:"require_admin"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Backup is a backup known to the backup integration
+type Backup struct {
+	BackupID  string `json:"backup_id"`
+	Name      string `json:"name"`
+	Date      string `json:"date"`
+	Protected bool   `json:"protected"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// The plain types have the models' fields without their JSON methods
+
+type (
+	plainState               State
+	plainEntityRegistryEntry EntityRegistryEntry
+	plainDeviceEntry         DeviceEntry
+	plainArea                Area
+	plainFloor               Floor
+	plainLabel               Label
+	plainZone                Zone
+	plainConfigEntry         ConfigEntry
+	plainDashboard           Dashboard
+	plainBackup              Backup
+)
+
+func (s *State) UnmarshalJSON(data []byte) error {
+	return decodeModel(data, (*plainState)(s), &s.Extra)
+}
+
+func (s State) MarshalJSON() ([]byte, error) {
+	return encodeModel(plainState(s), s.Extra)
+}
+
+func (e *EntityRegistryEntry) UnmarshalJSON(data []byte) error {
+	return decodeModel(data, (*plainEntityRegistryEntry)(e), &e.Extra)
+}
+
+func (e EntityRegistryEntry) MarshalJSON() ([]byte, error) {
+	return encodeModel(plainEntityRegistryEntry(e), e.Extra)
+}
+
+func (d *DeviceEntry) UnmarshalJSON(data []byte) error {
+	return decodeModel(data, (*plainDeviceEntry)(d), &d.Extra)
+}
+
+func (d DeviceEntry) MarshalJSON() ([]byte, error) {
+	return encodeModel(plainDeviceEntry(d), d.Extra)
+}
+
+func (a *Area) UnmarshalJSON(data []byte) error {
+	return decodeModel(data, (*plainArea)(a), &a.Extra)
+}
+
+func (a Area) MarshalJSON() ([]byte, error) {
+	return encodeModel(plainArea(a), a.Extra)
+}
+
+func (f *Floor) UnmarshalJSON(data []byte) error {
+	return decodeModel(data, (*plainFloor)(f), &f.Extra)
+}
+
+func (f Floor) MarshalJSON() ([]byte, error) {
+	return encodeModel(plainFloor(f), f.Extra)
+}
+
+func (l *Label) UnmarshalJSON(data []byte) error {
+	return decodeModel(data, (*plainLabel)(l), &l.Extra)
+}
+
+func (l Label) MarshalJSON() ([]byte, error) {
+	return encodeModel(plainLabel(l), l.Extra)
+}
+
+func (z *Zone) UnmarshalJSON(data []byte) error {
+	return decodeModel(data, (*plainZone)(z), &z.Extra)
+}
+
+func (z Zone) MarshalJSON() ([]byte, error) {
+	return encodeModel(plainZone(z), z.Extra)
+}
+
+func (c *ConfigEntry) UnmarshalJSON(data []byte) error {
+	return decodeModel(data, (*plainConfigEntry)(c), &c.Extra)
+}
+
+func (c ConfigEntry) MarshalJSON() ([]byte, error) {
+	return encodeModel(plainConfigEntry(c), c.Extra)
+}
+
+func (d *Dashboard) UnmarshalJSON(data []byte) error {
+	return decodeModel(data, (*plainDashboard)(d), &d.Extra)
+}
+
+func (d Dashboard) MarshalJSON() ([]byte, error) {
+	return encodeModel(plainDashboard(d), d.Extra)
+}
+
+func (b *Backup) UnmarshalJSON(data []byte) error {
+	return decodeModel(data, (*plainBackup)(b), &b.Extra)
+}
+
+func (b Backup) MarshalJSON() ([]byte, error) {
+	return encodeModel(plainBackup(b), b.Extra)
+}
+
+// decodeModel unmarshals data into v, a pointer to a plain model type, and
+// collects the keys that v has no field for into extra
+func decodeModel(data []byte, v interface{}, extra *map[string]interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	fields, err := decodeObject(data)
+	if err != nil {
+		return err
+	}
+	for _, f := range modelFields(reflect.TypeOf(v).Elem()) {
+		delete(fields, f.name)
+	}
+	if len(fields) == 0 {
+		fields = nil
+	}
+	*extra = fields
+	return nil
+}
+
+// encodeModel marshals v, a plain model value, together with its extra fields
+func encodeModel(v interface{}, extra map[string]interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(v)
+	for _, f := range modelFields(rv.Type()) {
+		if f.nullable && rv.Field(f.index).String() == "" {
+			fields[f.name] = nil
+		}
+	}
+	for k, val := range extra {
+		if _, ok := fields[k]; !ok {
+			fields[k] = val
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// decodeObject decodes a JSON object, keeping numbers exact
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		fields = make(map[string]interface{})
+	}
+	return fields, nil
+}
+
+// modelField is a JSON-mapped field of a model struct
+type modelField struct {
+	index    int
+	name     string
+	nullable bool
+}
+
+// modelFields returns the JSON-mapped fields of a model struct type
+func modelFields(t reflect.Type) []modelField {
+	var fields []modelField
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		f := modelField{index: i, name: opts[0]}
+		for _, opt := range opts[1:] {
+			if opt == "nullable" {
+				f.nullable = t.Field(i).Type.Kind() == reflect.String
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// decodeResult converts a command result into the typed value v points to
+func decodeResult(result interface{}, v interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func entityDomain(entityID string) string {
+	if i := strings.Index(entityID, "."); i >= 0 {
+		return entityID[:i]
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
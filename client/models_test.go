@@ -0,0 +1,149 @@
+package client
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestModelsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{}
+		data  string
+	}{
+		{
+			name:  "state",
+			model: &State{},
+			data: `{"entity_id": "light.kitchen", "state": "on", "attributes": {"brightness": 255, "friendly_name": "Kitchen"},
+				"last_changed": "2024-01-01T00:00:00+00:00", "last_reported": "2024-01-01T00:00:00+00:00",
+				"last_updated": "2024-01-01T00:00:00+00:00", "context": {"id": "01H", "parent_id": null, "user_id": null}}`,
+		},
+		{
+			name:  "state with null context",
+			model: &State{},
+			data: `{"entity_id": "sensor.power", "state": "1.50", "attributes": {}, "last_changed": null,
+				"last_updated": null, "context": null}`,
+		},
+		{
+			name:  "entity registry entry",
+			model: &EntityRegistryEntry{},
+			data: `{"entity_id": "sensor.power", "id": "abc", "unique_id": "power_1", "platform": "mqtt",
+				"name": null, "original_name": "Power", "icon": null, "device_id": "dev1", "area_id": null,
+				"config_entry_id": "ce1", "disabled_by": null, "hidden_by": null, "entity_category": null,
+				"device_class": null, "original_device_class": "power", "labels": [], "has_entity_name": true,
+				"options": {"sensor": {"suggested_display_precision": 2}}}`,
+		},
+		{
+			name:  "device entry",
+			model: &DeviceEntry{},
+			data: `{"id": "dev1", "name": "Plug", "name_by_user": null, "manufacturer": "Acme", "model": null,
+				"sw_version": "1.0", "hw_version": null, "serial_number": null, "area_id": "kitchen",
+				"via_device_id": null, "disabled_by": null, "entry_type": null, "config_entries": ["ce1"],
+				"labels": ["power"], "connections": [["mac", "aa:bb"]], "modified_at": 1700000000.123456}`,
+		},
+		{
+			name:  "area",
+			model: &Area{},
+			data: `{"area_id": "kitchen", "name": "Kitchen", "floor_id": null, "icon": "mdi:silverware",
+				"picture": null, "aliases": [], "labels": [], "humidity_entity_id": null}`,
+		},
+		{
+			name:  "floor",
+			model: &Floor{},
+			data:  `{"floor_id": "ground", "name": "Ground", "level": null, "icon": null, "aliases": ["downstairs"], "created_at": 0}`,
+		},
+		{
+			name:  "label",
+			model: &Label{},
+			data:  `{"label_id": "power", "name": "Power", "color": null, "icon": "mdi:flash", "description": null, "created_at": 1}`,
+		},
+		{
+			name:  "zone",
+			model: &Zone{},
+			data:  `{"id": "work", "name": "Work", "latitude": 52.37, "longitude": 4.89, "radius": 100.0, "icon": null, "passive": false}`,
+		},
+		{
+			name:  "config entry",
+			model: &ConfigEntry{},
+			data: `{"entry_id": "ce1", "domain": "mqtt", "title": "MQTT", "source": "user", "state": "loaded",
+				"supports_options": true, "disabled_by": null, "reason": null, "pref_disable_polling": false}`,
+		},
+		{
+			name:  "dashboard",
+			model: &Dashboard{},
+			data: `{"id": "energy_view", "url_path": "energy-view", "title": "Energy", "icon": null, "mode": "storage",
+				"show_in_sidebar": true, "require_admin": false, "allow_single_word": true}`,
+		},
+		{
+			name:  "backup",
+			model: &Backup{},
+			data:  `{"backup_id": "b1", "name": "Nightly", "date": "2024-01-01", "protected": false, "size": 12.5, "addons": []}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.data), tt.model); err != nil {
+				t.Fatal(err)
+			}
+			encoded, err := json.Marshal(tt.model)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want, err := decodeObject([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := decodeObject(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(normalizeNumbers(got), normalizeNumbers(want)) {
+				t.Errorf("round trip changed the object\n got: %s\nwant: %s", encoded, tt.data)
+			}
+		})
+	}
+}
+
+func TestModelExtraFields(t *testing.T) {
+	var area Area
+	if err := json.Unmarshal([]byte(`{"area_id": "kitchen", "name": "Kitchen", "temperature_entity_id": "sensor.t", "icon": null}`), &area); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"temperature_entity_id": "sensor.t"}
+	if !reflect.DeepEqual(area.Extra, want) {
+		t.Errorf("Extra = %v, want %v", area.Extra, want)
+	}
+
+	// A field set on the model wins over an extra field of the same name
+	area.Name = "Cooking"
+	area.Extra["name"] = "Stale"
+	encoded, err := json.Marshal(area)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, _ := decodeObject(encoded)
+	if fields["name"] != "Cooking" || fields["temperature_entity_id"] != "sensor.t" {
+		t.Errorf("encoded = %s", encoded)
+	}
+}
+
+// normalizeNumbers converts json.Number values to float64, so 100 and 100.0
+// compare equal
+func normalizeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalizeNumbers(val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeNumbers(val)
+		}
+	}
+	return v
+}
@@ -5,8 +5,18 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/home-assistant/hab/query"
 )
 
+// OutputQuery is set from the global --query flag. PrintOutput and
+// PrintSuccess then print only what it selects from their data.
+var OutputQuery *query.Query
+
+// QueryError is set when OutputQuery could not be applied to the output.
+// Nothing is printed then, and the command fails with this error.
+var QueryError error
+
 // Response represents the standard JSON output format
 type Response struct {
 	Success  bool                   `json:"success"`
@@ -282,15 +292,78 @@ func getDisplayName(m map[string]interface{}) string {
 
 // PrintOutput prints formatted output to stdout
 func PrintOutput(data interface{}, textMode bool, message string) {
+	if printQueried(data, textMode, message) {
+		return
+	}
 	output := FormatOutput(data, textMode, message)
 	fmt.Println(output)
 }
 
 // PrintSuccess prints a successful response
 func PrintSuccess(data interface{}, textMode bool, message string) {
-	if textMode {
+	if printQueried(data, textMode, message) {
+		return
+	} else if textMode {
 		fmt.Println(formatText(data, message))
 	} else {
 		fmt.Println(FormatSuccess(data, message))
 	}
 }
+
+// printQueried prints what OutputQuery selects from data. It returns false,
+// printing nothing, if there is no query or no data to apply it to. If the
+// query cannot be applied it prints nothing either, sets QueryError and
+// returns true, so the unfiltered data is not printed instead.
+func printQueried(data interface{}, textMode bool, message string) bool {
+	if OutputQuery == nil || data == nil {
+		return false
+	}
+	result, err := OutputQuery.Apply(data)
+	if err != nil {
+		QueryError = fmt.Errorf("cannot apply --query: %w", err)
+		return true
+	}
+	if textMode {
+		// The message describes the whole result, so only the selection is printed
+		fmt.Println(formatQueryText(result))
+	} else {
+		fmt.Println(formatJSON(result, true, message, nil))
+	}
+	return true
+}
+
+// formatQueryText formats a query result for text output. Scalars are
+// printed raw, one per line for a list, so they can be used in scripts.
+func formatQueryText(result interface{}) string {
+	if list, ok := result.([]interface{}); ok {
+		lines := make([]string, 0, len(list))
+		for _, item := range list {
+			s, ok := formatScalar(item)
+			if !ok {
+				return formatText(list, "")
+			}
+			lines = append(lines, s)
+		}
+		return strings.Join(lines, "\n")
+	}
+	if s, ok := formatScalar(result); ok {
+		return s
+	}
+	return formatText(result, "")
+}
+
+// formatScalar formats a string raw and other scalars as JSON
+func formatScalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}, []interface{}:
+		return "", false
+	case string:
+		return v, true
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+}
@@ -1,9 +1,11 @@
 package client
 
 import (
-	"crypto/tls"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -22,7 +24,23 @@ type RestClient struct {
 	Token     string
 	Timeout   time.Duration
 	VerifySSL bool
-	client    *resty.Client
+	// TokenSource, when set, supplies the access token for every request
+	// in place of Token, so refreshed tokens are picked up
+	TokenSource TokenSource
+	// Cache, when set, is invalidated by requests that may change a registry
+	Cache Cache
+	// Dial, when set, opens the connections requests are sent over
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+	// Retry, when set, retries transient failures of safe requests
+	Retry *RetryPolicy
+	// Limiter, when set, spaces out requests
+	Limiter *RateLimiter
+	// Transport, when set, applies TLS and proxy settings
+	Transport *TransportConfig
+	// Cassette, when set, records requests or answers them from a recording
+	Cassette Cassette
+	client   *resty.Client
+	ctx      context.Context
 }
 
 // NewRestClient creates a new REST client
@@ -45,19 +63,33 @@ func NewRestClientWithOptions(baseURL, token string, timeout time.Duration, veri
 	}
 }
 
+// WithContext returns a shallow copy of the client whose requests are bound to ctx.
+// Cancelling ctx aborts in-flight requests.
+func (c *RestClient) WithContext(ctx context.Context) *RestClient {
+	c2 := *c
+	c2.client = c.getClient()
+	c2.ctx = ctx
+	return &c2
+}
+
+// context returns the context requests are bound to
+func (c *RestClient) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
 func (c *RestClient) getClient() *resty.Client {
 	if c.client == nil {
 		c.client = resty.New()
+		c.client.SetTransport(c.httpTransport())
 		c.client.SetTimeout(c.Timeout)
 		c.client.SetBaseURL(c.BaseURL)
 		c.client.SetHeader("Authorization", "Bearer "+c.Token)
 		c.client.SetHeader("Content-Type", "application/json")
 		c.client.SetHeader("Accept", "application/json")
 
-		if !c.VerifySSL {
-			c.client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
-		}
-
 		// Response logging
 		c.client.OnAfterResponse(func(client *resty.Client, resp *resty.Response) error {
 			log.WithFields(log.Fields{
@@ -73,30 +105,84 @@ func (c *RestClient) getClient() *resty.Client {
 	return c.client
 }
 
+// httpTransport returns the transport requests are sent over
+func (c *RestClient) httpTransport() http.RoundTripper {
+	t := NewHTTPTransport(c.Transport)
+	if c.Dial != nil {
+		t.DialContext = c.Dial
+		t.Proxy = nil
+	}
+	if !c.VerifySSL {
+		t.TLSClientConfig = insecureTLS(t.TLSClientConfig)
+	}
+	if c.Cassette != nil {
+		return c.Cassette.WrapTransport(t)
+	}
+	return t
+}
+
 // Get makes a GET request
 func (c *RestClient) Get(endpoint string) (interface{}, error) {
-	return c.request("GET", endpoint, nil)
+	return c.request(c.context(), "GET", endpoint, nil)
 }
 
 // Post makes a POST request
 func (c *RestClient) Post(endpoint string, body interface{}) (interface{}, error) {
-	return c.request("POST", endpoint, body)
+	return c.request(c.context(), "POST", endpoint, body)
 }
 
 // Put makes a PUT request
 func (c *RestClient) Put(endpoint string, body interface{}) (interface{}, error) {
-	return c.request("PUT", endpoint, body)
+	return c.request(c.context(), "PUT", endpoint, body)
 }
 
 // Delete makes a DELETE request
 func (c *RestClient) Delete(endpoint string) (interface{}, error) {
-	return c.request("DELETE", endpoint, nil)
+	return c.request(c.context(), "DELETE", endpoint, nil)
 }
 
-func (c *RestClient) request(method, endpoint string, body interface{}) (interface{}, error) {
+// request sends a request, retrying transient failures of requests that are
+// safe to repeat according to the client's retry policy
+func (c *RestClient) request(ctx context.Context, method, endpoint string, body interface{}) (interface{}, error) {
+	retries := 0
+	if requestRetrySafe(method, endpoint) {
+		retries = c.Retry.retries()
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, retryable, err := c.attempt(ctx, method, endpoint, body)
+		if !retryable || attempt > retries {
+			return result, err
+		}
+
+		log.WithError(err).WithFields(log.Fields{
+			"method":  method,
+			"url":     c.BaseURL + "/api/" + endpoint,
+			"attempt": attempt,
+			"retries": retries,
+		}).Debug("Retrying REST request")
+		if err := c.Retry.wait(ctx, attempt); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends a request once and reports whether its failure is transient
+func (c *RestClient) attempt(ctx context.Context, method, endpoint string, body interface{}) (interface{}, bool, error) {
+	if err := c.Limiter.Wait(ctx); err != nil {
+		return nil, false, err
+	}
+
 	url := fmt.Sprintf("/api/%s", endpoint)
 
-	req := c.getClient().R()
+	req := c.getClient().R().SetContext(ctx)
+	if c.TokenSource != nil {
+		token, err := c.TokenSource()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get access token: %w", err)
+		}
+		req.SetHeader("Authorization", "Bearer "+token)
+	}
 
 	if body != nil {
 		req.SetBody(body)
@@ -120,14 +206,26 @@ func (c *RestClient) request(method, endpoint string, body interface{}) (interfa
 	case "DELETE":
 		resp, err = req.Delete(url)
 	default:
-		return nil, fmt.Errorf("unsupported method: %s", method)
+		return nil, false, fmt.Errorf("unsupported method: %s", method)
+	}
+
+	if c.Cache != nil && requestMutates(method, endpoint) {
+		c.Cache.Invalidate()
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, false, ctxErr
+		}
+		return nil, !errors.Is(err, ErrNotRecorded), fmt.Errorf("request failed: %w", err)
+	}
+
+	if retryableStatus(resp.StatusCode()) {
+		return nil, true, c.handleError(resp)
 	}
 
-	return c.handleResponse(resp)
+	result, err := c.handleResponse(resp)
+	return result, false, err
 }
 
 func (c *RestClient) handleResponse(resp *resty.Response) (interface{}, error) {
@@ -319,7 +417,7 @@ func (c *RestClient) GetHistory(entityID string, startTime, endTime string) ([]i
 // ConfigFlowCreate starts a new config flow for an integration
 func (c *RestClient) ConfigFlowCreate(handler string) (map[string]interface{}, error) {
 	body := map[string]interface{}{
-		"handler":              handler,
+		"handler":               handler,
 		"show_advanced_options": false,
 	}
 	result, err := c.Post("config/config_entries/flow", body)
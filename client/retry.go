@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRetries is the number of retries after a failed first attempt
+	DefaultRetries = 3
+	// DefaultRetryDelay is the delay before the first retry
+	DefaultRetryDelay = 1 * time.Second
+	// DefaultMaxRetryDelay caps the exponential retry backoff
+	DefaultMaxRetryDelay = 10 * time.Second
+)
+
+// RetryPolicy decides how often and how long apart failed requests that are
+// safe to repeat are retried. Only idempotent reads and calls known to be
+// free of side effects are retried.
+type RetryPolicy struct {
+	// Retries is the number of retries after the first attempt; 0 disables retrying
+	Retries  int
+	Delay    time.Duration
+	MaxDelay time.Duration
+}
+
+// NewRetryPolicy returns a policy with the default delays
+func NewRetryPolicy(retries int) *RetryPolicy {
+	return &RetryPolicy{
+		Retries:  retries,
+		Delay:    DefaultRetryDelay,
+		MaxDelay: DefaultMaxRetryDelay,
+	}
+}
+
+// backoff returns the jittered delay before retry number attempt (1-based)
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.Delay
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxRetryDelay
+	}
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	// Add up to 20% jitter so parallel clients don't retry in lockstep
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
+
+// wait sleeps before retry number attempt, or returns early with ctx's error
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(p.backoff(attempt)):
+		return nil
+	}
+}
+
+// retries returns the number of retries allowed by a possibly nil policy
+func (p *RetryPolicy) retries() int {
+	if p == nil || p.Retries < 0 {
+		return 0
+	}
+	return p.Retries
+}
+
+// retryableStatus reports whether an HTTP status is a transient server
+// condition, as seen while Home Assistant or a proxy in front of it restarts
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// requestRetrySafe reports whether a REST request can be repeated without
+// side effects
+func requestRetrySafe(method, endpoint string) bool {
+	if method == "GET" {
+		return true
+	}
+	switch endpoint {
+	case "config/core/check_config", "template":
+		return true
+	}
+	return false
+}
+
+// commandRetrySafe reports whether a WebSocket command can be repeated
+// without side effects
+func commandRetrySafe(cmdType string, params map[string]interface{}) bool {
+	return cmdType != "call_service" && !commandMutates(cmdType, params)
+}
+
+// commandRetryable reports whether a WebSocket command failed because the
+// connection dropped, so a retry may reach the reconnected server
+func commandRetryable(err error) bool {
+	return errors.Is(err, ErrConnectionLost)
+}
+
+// RateLimiter spaces out requests to at most a fixed number per second.
+// It is safe for concurrent use and may be shared by several clients.
+type RateLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+// NewRateLimiter returns a limiter allowing perSecond requests per second,
+// or nil (no limit) when perSecond is not positive
+func NewRateLimiter(perSecond float64) *RateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &RateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// Wait blocks until the next request may be sent or ctx is done.
+// A nil limiter never blocks.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
@@ -0,0 +1,25 @@
+package client
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	// SupervisorURL is Home Assistant Core behind the Supervisor proxy, as
+	// reached from add-ons. REST requests go to /core/api/ below it.
+	SupervisorURL = "http://supervisor/core"
+	// SupervisorWebSocketURL is the WebSocket API behind the Supervisor proxy
+	SupervisorWebSocketURL = "ws://supervisor/core/websocket"
+)
+
+// SupervisorToken returns the token the Supervisor gives add-ons, or an
+// empty string when not running in an add-on
+func SupervisorToken() string {
+	return os.Getenv("SUPERVISOR_TOKEN")
+}
+
+// IsSupervisorURL reports whether baseURL is the Supervisor proxy to Core
+func IsSupervisorURL(baseURL string) bool {
+	return strings.TrimRight(baseURL, "/") == SupervisorURL
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// TransportOptions are the TLS and proxy settings for connections to Home Assistant
+type TransportOptions struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots
+	CAFile string
+	// ClientCert and ClientKey are PEM files presented for mutual TLS
+	ClientCert string
+	ClientKey  string
+	// Insecure skips verification of the server certificate
+	Insecure bool
+	// Proxy is the URL of an HTTP proxy. When empty, HTTPS_PROXY,
+	// HTTP_PROXY and NO_PROXY from the environment apply.
+	Proxy string
+}
+
+// TransportConfig is the loaded form of TransportOptions, shared by the
+// REST and WebSocket dialers
+type TransportConfig struct {
+	// TLS is nil when no TLS option is set
+	TLS   *tls.Config
+	Proxy func(*http.Request) (*url.URL, error)
+}
+
+// Load reads the CA bundle and client certificate and parses the proxy URL
+func (o TransportOptions) Load() (*TransportConfig, error) {
+	cfg := &TransportConfig{Proxy: http.ProxyFromEnvironment}
+
+	if o.CAFile != "" || o.ClientCert != "" || o.ClientKey != "" || o.Insecure {
+		cfg.TLS = &tls.Config{InsecureSkipVerify: o.Insecure}
+	}
+
+	if o.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", o.CAFile)
+		}
+		cfg.TLS.RootCAs = pool
+	}
+
+	if o.ClientCert != "" || o.ClientKey != "" {
+		if o.ClientCert == "" || o.ClientKey == "" {
+			return nil, fmt.Errorf("client certificate and client key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.TLS.Certificates = []tls.Certificate{cert}
+	}
+
+	if o.Proxy != "" {
+		proxyURL, err := url.Parse(o.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL: %s", o.Proxy)
+		}
+		cfg.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return cfg, nil
+}
+
+// NewHTTPTransport returns an HTTP transport applying cfg.
+// A nil cfg gives the defaults of http.DefaultTransport.
+func NewHTTPTransport(cfg *TransportConfig) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg != nil {
+		if cfg.TLS != nil {
+			t.TLSClientConfig = cfg.TLS.Clone()
+		}
+		if cfg.Proxy != nil {
+			t.Proxy = cfg.Proxy
+		}
+	}
+	return t
+}
+
+// insecureTLS returns a copy of cfg that skips server certificate verification
+func insecureTLS(cfg *tls.Config) *tls.Config {
+	if cfg == nil {
+		return &tls.Config{InsecureSkipVerify: true}
+	}
+	cfg = cfg.Clone()
+	cfg.InsecureSkipVerify = true
+	return cfg
+}
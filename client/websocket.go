@@ -1,9 +1,13 @@
 package client
 
 import (
-	"crypto/tls"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
 	"sync"
 	"time"
 
@@ -11,23 +15,110 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// DefaultReconnectDelay is the initial delay before reconnecting
+	DefaultReconnectDelay = 1 * time.Second
+	// DefaultMaxReconnectDelay caps the exponential reconnect backoff
+	DefaultMaxReconnectDelay = 30 * time.Second
+)
+
+// ErrConnectionLost is matched by errors returned for commands that were
+// in flight (or issued while reconnecting) when the connection dropped
+var ErrConnectionLost = errors.New("connection lost")
+
+// ConnectionLostError is returned when the WebSocket connection drops
+// before a command received its result
+type ConnectionLostError struct {
+	Err error
+}
+
+func (e *ConnectionLostError) Error() string {
+	if e.Err == nil {
+		return ErrConnectionLost.Error()
+	}
+	return fmt.Sprintf("%s: %v", ErrConnectionLost, e.Err)
+}
+
+func (e *ConnectionLostError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrConnectionLost
+func (e *ConnectionLostError) Is(target error) bool {
+	return target == ErrConnectionLost
+}
+
 // WebSocketClient is a WebSocket client for the Home Assistant API
 type WebSocketClient struct {
-	URL           string
-	Token         string
-	Timeout       time.Duration
-	VerifySSL     bool
+	URL       string
+	Token     string
+	Timeout   time.Duration
+	VerifySSL bool
+	// TokenSource, when set, supplies the access token for every
+	// authentication in place of Token, so reconnects use a refreshed token
+	TokenSource TokenSource
+
+	// AutoReconnect re-establishes the connection with exponential backoff
+	// when it drops, re-authenticates and resubscribes active subscriptions
+	AutoReconnect     bool
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+
+	// Cache, when set, serves registry list commands and is invalidated by
+	// commands that may change a registry
+	Cache Cache
+
+	// Dial, when set, opens the network connection the WebSocket runs over
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// Retry, when set, retries commands that are safe to repeat when the
+	// connection drops while they are in flight
+	Retry *RetryPolicy
+	// Limiter, when set, spaces out commands
+	Limiter *RateLimiter
+	// Transport, when set, applies TLS and proxy settings to the dialer
+	Transport *TransportConfig
+
+	// Cassette, when set, records the frames of the connection, or answers
+	// commands without connecting when it is replaying
+	Cassette Cassette
+
 	conn          *websocket.Conn
+	session       int
+	connMu        sync.RWMutex
+	writeMu       sync.Mutex
 	messageID     int
 	messageIDMu   sync.Mutex
-	pending       map[int]chan *WSMessage
+	pending       map[int]chan commandResult
 	pendingMu     sync.RWMutex
-	subscriptions map[int]func(map[string]interface{})
+	subscriptions map[int]*subscription
 	subsMu        sync.RWMutex
+	ctx           context.Context
 	done          chan struct{}
+	stateMu       sync.RWMutex
 	authenticated bool
+	reconnecting  bool
+	closed        bool
+}
+
+// commandResult carries either a result message or the reason none arrived
+type commandResult struct {
+	msg *WSMessage
+	err error
+}
+
+// subscription is an active subscription that is replayed after a reconnect
+type subscription struct {
+	id       int
+	cmdType  string
+	params   map[string]interface{}
+	callback func(map[string]interface{})
+	onClose  func()
 }
 
+// Event is a single event delivered on a subscription
+type Event map[string]interface{}
+
 // WSMessage represents a WebSocket message
 type WSMessage struct {
 	ID      int                    `json:"id,omitempty"`
@@ -44,126 +135,288 @@ type WSMessage struct {
 	Extra map[string]interface{} `json:"-"`
 }
 
-// WSError represents a WebSocket error
+// WSError represents a WebSocket error. A command that fails returns it as
+// its error, so callers can check the Code Home Assistant sent; other fields
+// of the error, such as translation keys, are kept in Extra.
 type WSError struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (e *WSError) Error() string {
+	if e.Message == "" {
+		return "unknown error"
+	}
+	return e.Message
+}
+
+type plainWSError WSError
+
+func (e *WSError) UnmarshalJSON(data []byte) error {
+	return decodeModel(data, (*plainWSError)(e), &e.Extra)
+}
+
+func (e WSError) MarshalJSON() ([]byte, error) {
+	return encodeModel(plainWSError(e), e.Extra)
 }
 
 // NewWebSocketClient creates a new WebSocket client
 func NewWebSocketClient(baseURL, token string) *WebSocketClient {
 	wsURL, _ := BuildWebSocketURL(baseURL)
 	return &WebSocketClient{
-		URL:           wsURL,
-		Token:         token,
-		Timeout:       30 * time.Second,
-		VerifySSL:     true,
-		pending:       make(map[int]chan *WSMessage),
-		subscriptions: make(map[int]func(map[string]interface{})),
+		URL:               wsURL,
+		Token:             token,
+		Timeout:           30 * time.Second,
+		VerifySSL:         true,
+		AutoReconnect:     true,
+		ReconnectDelay:    DefaultReconnectDelay,
+		MaxReconnectDelay: DefaultMaxReconnectDelay,
+		pending:           make(map[int]chan commandResult),
+		subscriptions:     make(map[int]*subscription),
 	}
 }
 
 // Connect establishes the WebSocket connection and authenticates
 func (c *WebSocketClient) Connect() error {
+	return c.ConnectContext(context.Background())
+}
+
+// ConnectContext establishes the WebSocket connection and authenticates.
+// The context bounds the whole connection: once it is cancelled the
+// connection is closed and commands without their own context fail.
+func (c *WebSocketClient) ConnectContext(ctx context.Context) error {
+	var conn *websocket.Conn
+	if !c.replaying() {
+		var err error
+		conn, err = c.dial(ctx)
+		if err != nil {
+			return err
+		}
+		c.setConn(conn)
+	}
+
+	done := make(chan struct{})
+	c.stateMu.Lock()
+	c.ctx = ctx
+	c.authenticated = true
+	c.closed = false
+	c.done = done
+	c.stateMu.Unlock()
+
+	// Close the connection when the context is cancelled
+	go func() {
+		select {
+		case <-ctx.Done():
+			log.WithError(ctx.Err()).Debug("Context done, closing WebSocket")
+			c.Close()
+		case <-done:
+		}
+	}()
+
+	// Start receive loop
+	if conn != nil {
+		go c.receiveLoop(conn)
+	}
+
+	return nil
+}
+
+// replaying reports whether commands are answered from a cassette
+func (c *WebSocketClient) replaying() bool {
+	return c.Cassette != nil && c.Cassette.Replaying()
+}
+
+// setConn makes conn the current connection, starting a new cassette
+// session for it when recording
+func (c *WebSocketClient) setConn(conn *websocket.Conn) {
+	session := 0
+	if c.Cassette != nil {
+		session = c.Cassette.OpenSession()
+	}
+	c.connMu.Lock()
+	c.conn = conn
+	c.session = session
+	c.connMu.Unlock()
+}
+
+// recordFrame adds a frame of the current connection to the cassette
+func (c *WebSocketClient) recordFrame(direction string, frame []byte) {
+	if c.Cassette == nil {
+		return
+	}
+	c.connMu.RLock()
+	session := c.session
+	c.connMu.RUnlock()
+	c.Cassette.RecordFrame(session, direction, frame)
+}
+
+// context returns the context the connection was established with
+func (c *WebSocketClient) context() context.Context {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// dial opens a new connection and performs the auth handshake on it
+func (c *WebSocketClient) dial(ctx context.Context) (*websocket.Conn, error) {
 	dialer := websocket.Dialer{
 		HandshakeTimeout: 10 * time.Second,
+		NetDialContext:   c.Dial,
+		Proxy:            http.ProxyFromEnvironment,
 	}
 
+	if c.Transport != nil {
+		dialer.TLSClientConfig = c.Transport.TLS
+		dialer.Proxy = c.Transport.Proxy
+	}
+	if c.Dial != nil {
+		dialer.Proxy = nil
+	}
 	if !c.VerifySSL {
-		dialer.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		dialer.TLSClientConfig = insecureTLS(dialer.TLSClientConfig)
 	}
 
 	log.WithField("url", c.URL).Debug("Connecting to WebSocket")
 
-	conn, resp, err := dialer.Dial(c.URL, nil)
+	conn, resp, err := dialer.DialContext(ctx, c.URL, nil)
 	if err != nil {
 		if resp != nil {
-			return fmt.Errorf("websocket connection failed (%d): %w", resp.StatusCode, err)
+			return nil, fmt.Errorf("websocket connection failed (%d): %w", resp.StatusCode, err)
 		}
-		return fmt.Errorf("websocket connection failed: %w", err)
+		return nil, fmt.Errorf("websocket connection failed: %w", err)
 	}
-	c.conn = conn
 
+	// Abort the handshake if the context is cancelled while waiting on the server
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	err = c.authenticate(conn)
+	if !stop() || ctx.Err() != nil {
+		conn.Close()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	log.Debug("WebSocket authenticated successfully")
+	return conn, nil
+}
+
+// authenticate runs the auth_required / auth / auth_ok handshake
+func (c *WebSocketClient) authenticate(conn *websocket.Conn) error {
 	// Read auth_required message
-	msg, err := c.readMessage()
+	msg, err := readMessage(conn)
 	if err != nil {
-		c.conn.Close()
 		return fmt.Errorf("failed to read auth_required: %w", err)
 	}
 	if msg.Type != "auth_required" {
-		c.conn.Close()
 		return fmt.Errorf("unexpected message type: %s", msg.Type)
 	}
 
 	log.Debug("Received auth_required, sending auth")
 
+	token := c.Token
+	if c.TokenSource != nil {
+		if token, err = c.TokenSource(); err != nil {
+			return fmt.Errorf("failed to get access token: %w", err)
+		}
+	}
+
 	// Send authentication
 	authMsg := map[string]string{
 		"type":         "auth",
-		"access_token": c.Token,
+		"access_token": token,
 	}
-	if err := c.conn.WriteJSON(authMsg); err != nil {
-		c.conn.Close()
+	if err := conn.WriteJSON(authMsg); err != nil {
 		return fmt.Errorf("failed to send auth: %w", err)
 	}
 
 	// Read auth result
-	msg, err = c.readMessage()
+	msg, err = readMessage(conn)
 	if err != nil {
-		c.conn.Close()
 		return fmt.Errorf("failed to read auth result: %w", err)
 	}
 
 	if msg.Type == "auth_invalid" {
-		c.conn.Close()
 		errMsg := "authentication failed"
 		if msg.Error != nil {
 			errMsg = msg.Error.Message
+		} else if m, ok := msg.Extra["message"].(string); ok && m != "" {
+			errMsg = m
 		}
 		return fmt.Errorf("%s", errMsg)
 	}
 	if msg.Type != "auth_ok" {
-		c.conn.Close()
 		return fmt.Errorf("unexpected auth response: %s", msg.Type)
 	}
-
-	log.Debug("WebSocket authenticated successfully")
-
-	c.authenticated = true
-	c.done = make(chan struct{})
-
-	// Start receive loop
-	go c.receiveLoop()
-
 	return nil
 }
 
-// Close closes the WebSocket connection
+// Close sends a normal-closure frame and closes the WebSocket connection
 func (c *WebSocketClient) Close() error {
+	c.stateMu.Lock()
+	if c.closed {
+		c.stateMu.Unlock()
+		return nil
+	}
+	c.closed = true
 	c.authenticated = false
-
 	if c.done != nil {
 		close(c.done)
 	}
+	c.stateMu.Unlock()
 
-	// Cancel pending requests
-	c.pendingMu.Lock()
-	for id, ch := range c.pending {
-		close(ch)
-		delete(c.pending, id)
+	// Cancel pending requests, reporting cancellation if that caused the close
+	closeErr := c.context().Err()
+	if closeErr == nil {
+		closeErr = fmt.Errorf("connection closed")
 	}
-	c.pendingMu.Unlock()
+	c.failPending(closeErr)
 
 	c.subsMu.Lock()
-	c.subscriptions = make(map[int]func(map[string]interface{}))
+	var closers []func()
+	for _, sub := range c.subscriptions {
+		if sub.onClose != nil {
+			closers = append(closers, sub.onClose)
+		}
+	}
+	c.subscriptions = make(map[int]*subscription)
 	c.subsMu.Unlock()
-
-	if c.conn != nil {
-		return c.conn.Close()
+	for _, onClose := range closers {
+		onClose()
+	}
+
+	c.connMu.RLock()
+	conn := c.conn
+	c.connMu.RUnlock()
+	if conn != nil {
+		c.writeMu.Lock()
+		err := conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			time.Now().Add(time.Second))
+		c.writeMu.Unlock()
+		if err != nil {
+			log.WithError(err).Debug("Failed to send close frame")
+		}
+		return conn.Close()
 	}
 	return nil
 }
 
+// IsConnected returns true while the client holds an authenticated connection
+func (c *WebSocketClient) IsConnected() bool {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	return c.authenticated
+}
+
 func (c *WebSocketClient) nextID() int {
 	c.messageIDMu.Lock()
 	defer c.messageIDMu.Unlock()
@@ -171,12 +424,15 @@ func (c *WebSocketClient) nextID() int {
 	return c.messageID
 }
 
-func (c *WebSocketClient) readMessage() (*WSMessage, error) {
-	_, data, err := c.conn.ReadMessage()
+func readMessage(conn *websocket.Conn) (*WSMessage, error) {
+	_, data, err := conn.ReadMessage()
 	if err != nil {
 		return nil, err
 	}
+	return parseMessage(data)
+}
 
+func parseMessage(data []byte) (*WSMessage, error) {
 	var msg WSMessage
 	if err := json.Unmarshal(data, &msg); err != nil {
 		return nil, err
@@ -191,72 +447,292 @@ func (c *WebSocketClient) readMessage() (*WSMessage, error) {
 	return &msg, nil
 }
 
-func (c *WebSocketClient) receiveLoop() {
+// writeJSON serializes writes on the current connection
+func (c *WebSocketClient) writeJSON(v interface{}) error {
+	c.connMu.RLock()
+	conn := c.conn
+	c.connMu.RUnlock()
+	if conn == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return err
+	}
+	c.recordFrame("send", data)
+	return nil
+}
+
+func (c *WebSocketClient) receiveLoop(conn *websocket.Conn) {
 	for {
-		select {
-		case <-c.done:
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if c.isClosed() {
+				return
+			}
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Debug("WebSocket closed by server")
+			} else {
+				log.WithError(err).Debug("WebSocket read error")
+			}
+			c.handleDisconnect(err)
 			return
-		default:
 		}
 
-		msg, err := c.readMessage()
+		c.recordFrame("recv", data)
+
+		msg, err := parseMessage(data)
 		if err != nil {
-			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+			log.WithError(err).Debug("Failed to parse WebSocket message")
+			continue
+		}
+
+		c.handleMessage(msg)
+	}
+}
+
+func (c *WebSocketClient) isClosed() bool {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	return c.closed
+}
+
+// handleDisconnect fails in-flight commands and starts reconnecting
+func (c *WebSocketClient) handleDisconnect(cause error) {
+	c.stateMu.Lock()
+	c.authenticated = false
+	c.reconnecting = c.AutoReconnect
+	c.stateMu.Unlock()
+
+	c.failPending(&ConnectionLostError{Err: cause})
+
+	if !c.AutoReconnect {
+		return
+	}
+	c.reconnectLoop()
+}
+
+// reconnectLoop dials with exponential backoff until it succeeds or the
+// client is closed, then restores the active subscriptions
+func (c *WebSocketClient) reconnectLoop() {
+	delay := c.ReconnectDelay
+	if delay <= 0 {
+		delay = DefaultReconnectDelay
+	}
+	maxDelay := c.MaxReconnectDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxReconnectDelay
+	}
+
+	// Close replaces done on the next Connect, so wait on the current one
+	c.stateMu.RLock()
+	done := c.done
+	c.stateMu.RUnlock()
+
+	for attempt := 1; ; attempt++ {
+		// Add up to 20% jitter so parallel clients don't reconnect in lockstep
+		wait := delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+		log.WithFields(log.Fields{
+			"attempt": attempt,
+			"delay":   wait,
+		}).Debug("Reconnecting WebSocket")
+
+		select {
+		case <-done:
+			return
+		case <-time.After(wait):
+		}
+
+		conn, err := c.dial(c.context())
+		if err == nil {
+			c.setConn(conn)
+
+			c.stateMu.Lock()
+			if c.closed {
+				c.stateMu.Unlock()
+				conn.Close()
 				return
 			}
-			log.WithError(err).Debug("WebSocket read error")
+			c.authenticated = true
+			c.reconnecting = false
+			c.stateMu.Unlock()
+
+			log.WithField("attempt", attempt).Debug("WebSocket reconnected")
+
+			go c.receiveLoop(conn)
+			c.resubscribe()
+			return
+		}
+
+		log.WithError(err).Debug("WebSocket reconnect failed")
+		delay *= 2
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+}
+
+// resubscribe re-sends every active subscription under a fresh message ID.
+// A subscription the server rejects is closed.
+func (c *WebSocketClient) resubscribe() {
+	c.subsMu.Lock()
+	subs := make([]*subscription, 0, len(c.subscriptions))
+	for _, sub := range c.subscriptions {
+		subs = append(subs, sub)
+	}
+	c.subscriptions = make(map[int]*subscription)
+	for _, sub := range subs {
+		sub.id = c.nextID()
+		c.subscriptions[sub.id] = sub
+	}
+	c.subsMu.Unlock()
+
+	for _, sub := range subs {
+		if _, err := c.sendMessage(c.context(), sub.id, sub.cmdType, sub.params); err != nil {
+			if errors.Is(err, ErrConnectionLost) {
+				// Dropped again: the next reconnect resubscribes it
+				continue
+			}
+			// Close the subscription so its consumer sees the loss rather
+			// than waiting for events that never come
+			log.WithError(err).WithField("type", sub.cmdType).Warn("Failed to resubscribe")
+			c.removeSubscription(sub)
+			c.subsMu.RLock()
+			onClose := sub.onClose
+			c.subsMu.RUnlock()
+			if onClose != nil {
+				onClose()
+			}
 			continue
 		}
+		log.WithFields(log.Fields{
+			"id":   sub.id,
+			"type": sub.cmdType,
+		}).Debug("Resubscribed")
+	}
+}
 
-		c.handleMessage(msg)
+// failPending resolves every in-flight command with err
+func (c *WebSocketClient) failPending(err error) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	for id, ch := range c.pending {
+		ch <- commandResult{err: err}
+		delete(c.pending, id)
 	}
 }
 
 func (c *WebSocketClient) handleMessage(msg *WSMessage) {
 	switch msg.Type {
-	case "result":
-		c.pendingMu.RLock()
+	case "result", "pong":
+		c.pendingMu.Lock()
 		ch, ok := c.pending[msg.ID]
-		c.pendingMu.RUnlock()
+		delete(c.pending, msg.ID)
+		c.pendingMu.Unlock()
 
 		if ok {
-			ch <- msg
-			c.pendingMu.Lock()
-			delete(c.pending, msg.ID)
-			c.pendingMu.Unlock()
+			ch <- commandResult{msg: msg}
 		}
 
 	case "event":
 		c.subsMu.RLock()
-		callback, ok := c.subscriptions[msg.ID]
+		sub, ok := c.subscriptions[msg.ID]
 		c.subsMu.RUnlock()
 
 		if ok && msg.Event != nil {
-			callback(msg.Event)
+			sub.callback(msg.Event)
 		}
+	}
+}
 
-	case "pong":
-		c.pendingMu.RLock()
-		ch, ok := c.pending[msg.ID]
-		c.pendingMu.RUnlock()
+// SendCommand sends a command and waits for a response
+func (c *WebSocketClient) SendCommand(cmdType string, params map[string]interface{}) (interface{}, error) {
+	return c.SendCommandContext(c.context(), cmdType, params)
+}
 
-		if ok {
-			ch <- msg
-			c.pendingMu.Lock()
-			delete(c.pending, msg.ID)
-			c.pendingMu.Unlock()
+// SendCommandContext sends a command and waits for a response or for ctx to be done
+func (c *WebSocketClient) SendCommandContext(ctx context.Context, cmdType string, params map[string]interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cacheable := c.Cache != nil && cachedCommands[cmdType] && len(params) == 0
+	if cacheable {
+		if result, ok := c.Cache.Get(cmdType); ok {
+			log.WithField("type", cmdType).Debug("Using cached result")
+			return result, nil
 		}
 	}
+
+	result, err := c.sendWithRetry(ctx, cmdType, params)
+	if c.Cache != nil {
+		if cacheable && err == nil {
+			c.Cache.Put(cmdType, result)
+		} else if commandMutates(cmdType, params) {
+			// Invalidate even on failure, the change may have been applied
+			c.Cache.Invalidate()
+		}
+	}
+	return result, err
 }
 
-// SendCommand sends a command and waits for a response
-func (c *WebSocketClient) SendCommand(cmdType string, params map[string]interface{}) (interface{}, error) {
-	if !c.authenticated {
-		return nil, fmt.Errorf("not connected")
+// sendWithRetry sends a command, retrying commands that are safe to repeat
+// when the connection drops, according to the client's retry policy
+func (c *WebSocketClient) sendWithRetry(ctx context.Context, cmdType string, params map[string]interface{}) (interface{}, error) {
+	retries := 0
+	if commandRetrySafe(cmdType, params) {
+		retries = c.Retry.retries()
 	}
 
-	msgID := c.nextID()
+	for attempt := 1; ; attempt++ {
+		result, err := c.sendOnce(ctx, cmdType, params)
+		if err == nil || !commandRetryable(err) || attempt > retries {
+			return result, err
+		}
+
+		log.WithError(err).WithFields(log.Fields{
+			"type":    cmdType,
+			"attempt": attempt,
+			"retries": retries,
+		}).Debug("Retrying WebSocket command")
+		if err := c.Retry.wait(ctx, attempt); err != nil {
+			return nil, err
+		}
+	}
+}
 
+// sendOnce sends a command under a fresh message ID
+func (c *WebSocketClient) sendOnce(ctx context.Context, cmdType string, params map[string]interface{}) (interface{}, error) {
+	if err := c.Limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.checkConnected(); err != nil {
+		return nil, err
+	}
+	return c.sendMessage(ctx, c.nextID(), cmdType, params)
+}
+
+// checkConnected returns an error when commands cannot be sent right now
+func (c *WebSocketClient) checkConnected() error {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	if c.authenticated {
+		return nil
+	}
+	if c.reconnecting {
+		return &ConnectionLostError{Err: fmt.Errorf("reconnecting")}
+	}
+	return fmt.Errorf("not connected")
+}
+
+// sendMessage sends a command under msgID and waits for its result
+func (c *WebSocketClient) sendMessage(ctx context.Context, msgID int, cmdType string, params map[string]interface{}) (interface{}, error) {
 	// Build message
 	msg := map[string]interface{}{
 		"id":   msgID,
@@ -267,7 +743,7 @@ func (c *WebSocketClient) SendCommand(cmdType string, params map[string]interfac
 	}
 
 	// Create response channel
-	respCh := make(chan *WSMessage, 1)
+	respCh := make(chan commandResult, 1)
 	c.pendingMu.Lock()
 	c.pending[msgID] = respCh
 	c.pendingMu.Unlock()
@@ -278,7 +754,13 @@ func (c *WebSocketClient) SendCommand(cmdType string, params map[string]interfac
 	}).Debug("Sending WebSocket command")
 
 	// Send message
-	if err := c.conn.WriteJSON(msg); err != nil {
+	send := c.writeJSON
+	if c.replaying() {
+		send = func(interface{}) error {
+			return c.replay(msgID, cmdType, params)
+		}
+	}
+	if err := send(msg); err != nil {
 		c.pendingMu.Lock()
 		delete(c.pending, msgID)
 		c.pendingMu.Unlock()
@@ -287,19 +769,26 @@ func (c *WebSocketClient) SendCommand(cmdType string, params map[string]interfac
 
 	// Wait for response
 	select {
-	case resp := <-respCh:
-		if resp == nil {
-			return nil, fmt.Errorf("connection closed")
+	case res := <-respCh:
+		if res.err != nil {
+			return nil, res.err
 		}
-		if !resp.Success {
-			errMsg := "unknown error"
+		resp := res.msg
+		// Home Assistant answers ping with a bare pong, without a success flag
+		if !resp.Success && resp.Type != "pong" {
 			if resp.Error != nil {
-				errMsg = resp.Error.Message
+				return nil, resp.Error
 			}
-			return nil, fmt.Errorf("%s", errMsg)
+			return nil, fmt.Errorf("unknown error")
 		}
 		return resp.Result, nil
 
+	case <-ctx.Done():
+		c.pendingMu.Lock()
+		delete(c.pending, msgID)
+		c.pendingMu.Unlock()
+		return nil, ctx.Err()
+
 	case <-time.After(c.Timeout):
 		c.pendingMu.Lock()
 		delete(c.pending, msgID)
@@ -308,6 +797,124 @@ func (c *WebSocketClient) SendCommand(cmdType string, params map[string]interfac
 	}
 }
 
+// replay delivers the cassette's answer to a command as if it had been
+// received under msgID
+func (c *WebSocketClient) replay(msgID int, cmdType string, params map[string]interface{}) error {
+	frames, err := c.Cassette.ReplayCommand(cmdType, params)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for _, frame := range frames {
+			msg, err := parseMessage(frame)
+			if err != nil {
+				log.WithError(err).Debug("Failed to parse recorded WebSocket message")
+				continue
+			}
+			msg.ID = msgID
+			c.handleMessage(msg)
+		}
+	}()
+	return nil
+}
+
+// subscribe registers a subscription and waits for the server to confirm it.
+// The subscription is replayed automatically after a reconnect.
+func (c *WebSocketClient) subscribe(cmdType string, params map[string]interface{}, callback func(map[string]interface{})) (*subscription, error) {
+	if err := c.checkConnected(); err != nil {
+		return nil, err
+	}
+
+	sub := &subscription{
+		id:       c.nextID(),
+		cmdType:  cmdType,
+		params:   params,
+		callback: callback,
+	}
+
+	// Register before sending so no early event is missed
+	c.subsMu.Lock()
+	c.subscriptions[sub.id] = sub
+	c.subsMu.Unlock()
+
+	if _, err := c.sendMessage(c.context(), sub.id, cmdType, params); err != nil {
+		c.removeSubscription(sub)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// subscriptionBuffer is the number of events buffered per subscription
+// before new events are dropped
+const subscriptionBuffer = 1024
+
+// Subscribe starts a subscription command (such as subscribe_events) and
+// streams its events on the returned channel. The channel is closed when the
+// returned unsubscribe function is called or the client is closed.
+// Unsubscribing sends unsubscribe_events for the subscription.
+func (c *WebSocketClient) Subscribe(cmdType string, params map[string]interface{}) (<-chan Event, func() error, error) {
+	events := make(chan Event, subscriptionBuffer)
+
+	var mu sync.Mutex
+	stopped := false
+	stop := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !stopped {
+			stopped = true
+			close(events)
+		}
+	}
+
+	sub, err := c.subscribe(cmdType, params, func(event map[string]interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopped {
+			return
+		}
+		select {
+		case events <- Event(event):
+		default:
+			log.WithField("type", cmdType).Warn("Subscription buffer full, dropping event")
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.subsMu.Lock()
+	sub.onClose = stop
+	c.subsMu.Unlock()
+
+	var once sync.Once
+	var unsubErr error
+	unsubscribe := func() error {
+		once.Do(func() {
+			id := c.removeSubscription(sub)
+			stop()
+			if !c.IsConnected() {
+				return
+			}
+			_, unsubErr = c.SendCommand("unsubscribe_events", map[string]interface{}{
+				"subscription": id,
+			})
+		})
+		return unsubErr
+	}
+
+	return events, unsubscribe, nil
+}
+
+// removeSubscription stops delivering events for sub and returns the
+// message ID it is currently registered under
+func (c *WebSocketClient) removeSubscription(sub *subscription) int {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	delete(c.subscriptions, sub.id)
+	return sub.id
+}
+
 // High-level API methods
 
 // GetStates returns all entity states
@@ -655,138 +1262,140 @@ func (c *WebSocketClient) ZoneDelete(zoneID string) error {
 	return err
 }
 
-// SystemHealthInfo returns system health information using subscription
-func (c *WebSocketClient) SystemHealthInfo() (map[string]interface{}, error) {
-	if !c.authenticated {
-		return nil, fmt.Errorf("not connected")
+// AuthCurrentUser returns the user the connection is authenticated as
+func (c *WebSocketClient) AuthCurrentUser() (map[string]interface{}, error) {
+	result, err := c.SendCommand("auth/current_user", nil)
+	if err != nil {
+		return nil, err
 	}
+	if m, ok := result.(map[string]interface{}); ok {
+		return m, nil
+	}
+	return nil, fmt.Errorf("unexpected response type")
+}
 
-	msgID := c.nextID()
-
-	// Channel to receive events
-	eventCh := make(chan map[string]interface{}, 100)
-	doneCh := make(chan struct{})
-
-	// Accumulated data
-	data := make(map[string]interface{})
-	var dataErr error
+// AuthRefreshTokenList returns the refresh tokens of the current user,
+// including those backing long-lived access tokens
+func (c *WebSocketClient) AuthRefreshTokenList() ([]interface{}, error) {
+	result, err := c.SendCommand("auth/refresh_tokens", nil)
+	if err != nil {
+		return nil, err
+	}
+	if arr, ok := result.([]interface{}); ok {
+		return arr, nil
+	}
+	return nil, fmt.Errorf("unexpected response type")
+}
 
-	// Register subscription callback
-	c.subsMu.Lock()
-	c.subscriptions[msgID] = func(event map[string]interface{}) {
-		select {
-		case eventCh <- event:
-		default:
-		}
+// AuthLongLivedTokenCreate creates a long-lived access token valid for
+// lifespanDays days and returns it
+func (c *WebSocketClient) AuthLongLivedTokenCreate(clientName string, lifespanDays int) (string, error) {
+	result, err := c.SendCommand("auth/long_lived_access_token", map[string]interface{}{
+		"client_name": clientName,
+		"lifespan":    lifespanDays,
+	})
+	if err != nil {
+		return "", err
 	}
-	c.subsMu.Unlock()
+	if token, ok := result.(string); ok {
+		return token, nil
+	}
+	return "", fmt.Errorf("unexpected response type")
+}
 
-	// Cleanup function
-	defer func() {
-		c.subsMu.Lock()
-		delete(c.subscriptions, msgID)
-		c.subsMu.Unlock()
-	}()
+// AuthRefreshTokenDelete revokes a refresh token and the access tokens issued with it
+func (c *WebSocketClient) AuthRefreshTokenDelete(tokenID string) error {
+	_, err := c.SendCommand("auth/delete_refresh_token", map[string]interface{}{
+		"refresh_token_id": tokenID,
+	})
+	return err
+}
 
-	// Send subscribe message
-	msg := map[string]interface{}{
-		"id":   msgID,
-		"type": "system_health/info",
+// SubscribeEvents subscribes to events on the event bus.
+// An empty eventType subscribes to all events.
+func (c *WebSocketClient) SubscribeEvents(eventType string) (<-chan Event, func() error, error) {
+	var params map[string]interface{}
+	if eventType != "" {
+		params = map[string]interface{}{"event_type": eventType}
 	}
+	return c.Subscribe("subscribe_events", params)
+}
 
-	// Create response channel for the initial result
-	respCh := make(chan *WSMessage, 1)
-	c.pendingMu.Lock()
-	c.pending[msgID] = respCh
-	c.pendingMu.Unlock()
-
-	if err := c.conn.WriteJSON(msg); err != nil {
-		c.pendingMu.Lock()
-		delete(c.pending, msgID)
-		c.pendingMu.Unlock()
-		return nil, fmt.Errorf("failed to send command: %w", err)
+// SystemHealthInfo returns system health information using subscription
+func (c *WebSocketClient) SystemHealthInfo() (map[string]interface{}, error) {
+	events, unsubscribe, err := c.Subscribe("system_health/info", nil)
+	if err != nil {
+		return nil, err
 	}
+	defer unsubscribe()
 
-	// Wait for initial result (subscription confirmation)
-	select {
-	case resp := <-respCh:
-		if resp == nil {
-			return nil, fmt.Errorf("connection closed")
-		}
-		if !resp.Success {
-			errMsg := "unknown error"
-			if resp.Error != nil {
-				errMsg = resp.Error.Message
+	// Accumulated data
+	data := make(map[string]interface{})
+	timeout := time.After(30 * time.Second)
+	ctx := c.context()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				return nil, fmt.Errorf("connection closed")
+			}
+			if applySystemHealthEvent(data, event) {
+				return data, nil
 			}
-			return nil, fmt.Errorf("%s", errMsg)
+		case <-timeout:
+			return nil, fmt.Errorf("timeout waiting for system health data")
 		}
-	case <-time.After(c.Timeout):
-		return nil, fmt.Errorf("timeout waiting for subscription confirmation")
 	}
+}
 
-	// Process events in a goroutine
-	go func() {
-		defer close(doneCh)
-		for event := range eventCh {
-			eventType, _ := event["type"].(string)
-
-			switch eventType {
-			case "initial":
-				if eventData, ok := event["data"].(map[string]interface{}); ok {
-					for k, v := range eventData {
-						data[k] = v
-					}
+// applySystemHealthEvent merges a system_health/info event into data and
+// reports whether the stream has finished
+func applySystemHealthEvent(data map[string]interface{}, event Event) bool {
+	eventType, _ := event["type"].(string)
+
+	switch eventType {
+	case "initial":
+		if eventData, ok := event["data"].(map[string]interface{}); ok {
+			for k, v := range eventData {
+				data[k] = v
+			}
+		}
+	case "update":
+		domain, _ := event["domain"].(string)
+		key, _ := event["key"].(string)
+		success, _ := event["success"].(bool)
+
+		if domain != "" && key != "" {
+			if _, exists := data[domain]; !exists {
+				data[domain] = map[string]interface{}{
+					"info": make(map[string]interface{}),
 				}
-			case "update":
-				domain, _ := event["domain"].(string)
-				key, _ := event["key"].(string)
-				success, _ := event["success"].(bool)
-
-				if domain != "" && key != "" {
-					if _, exists := data[domain]; !exists {
-						data[domain] = map[string]interface{}{
-							"info": make(map[string]interface{}),
-						}
-					}
-					if domainData, ok := data[domain].(map[string]interface{}); ok {
-						if _, exists := domainData["info"]; !exists {
-							domainData["info"] = make(map[string]interface{})
-						}
-						if infoData, ok := domainData["info"].(map[string]interface{}); ok {
-							if success {
-								infoData[key] = event["data"]
-							} else {
-								if errData, ok := event["error"].(map[string]interface{}); ok {
-									infoData[key] = map[string]interface{}{
-										"error": true,
-										"value": errData["msg"],
-									}
-								}
+			}
+			if domainData, ok := data[domain].(map[string]interface{}); ok {
+				if _, exists := domainData["info"]; !exists {
+					domainData["info"] = make(map[string]interface{})
+				}
+				if infoData, ok := domainData["info"].(map[string]interface{}); ok {
+					if success {
+						infoData[key] = event["data"]
+					} else {
+						if errData, ok := event["error"].(map[string]interface{}); ok {
+							infoData[key] = map[string]interface{}{
+								"error": true,
+								"value": errData["msg"],
 							}
 						}
 					}
 				}
-			case "finish":
-				return
 			}
 		}
-	}()
-
-	// Wait for finish or timeout
-	select {
-	case <-doneCh:
-		// Normal completion
-	case <-time.After(30 * time.Second):
-		dataErr = fmt.Errorf("timeout waiting for system health data")
+	case "finish":
+		return true
 	}
-
-	close(eventCh)
-
-	if dataErr != nil {
-		return nil, dataErr
-	}
-
-	return data, nil
+	return false
 }
 
 // SearchRelated returns related items for a given item type and ID
@@ -0,0 +1,122 @@
+package client
+
+import "fmt"
+
+// Typed variants of the WebSocket API methods
+
+// ListStates returns all entity states
+func (c *WebSocketClient) ListStates() ([]State, error) {
+	var states []State
+	if err := c.sendTyped("get_states", nil, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// ListEntityEntries returns all entries of the entity registry
+func (c *WebSocketClient) ListEntityEntries() ([]EntityRegistryEntry, error) {
+	var entries []EntityRegistryEntry
+	if err := c.sendTyped("config/entity_registry/list", nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetEntityEntry returns the entity registry entry of an entity
+func (c *WebSocketClient) GetEntityEntry(entityID string) (*EntityRegistryEntry, error) {
+	var entry EntityRegistryEntry
+	if err := c.sendTyped("config/entity_registry/get", map[string]interface{}{"entity_id": entityID}, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ListDevices returns all entries of the device registry
+func (c *WebSocketClient) ListDevices() ([]DeviceEntry, error) {
+	var devices []DeviceEntry
+	if err := c.sendTyped("config/device_registry/list", nil, &devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// ListAreas returns all areas
+func (c *WebSocketClient) ListAreas() ([]Area, error) {
+	var areas []Area
+	if err := c.sendTyped("config/area_registry/list", nil, &areas); err != nil {
+		return nil, err
+	}
+	return areas, nil
+}
+
+// ListFloors returns all floors
+func (c *WebSocketClient) ListFloors() ([]Floor, error) {
+	var floors []Floor
+	if err := c.sendTyped("config/floor_registry/list", nil, &floors); err != nil {
+		return nil, err
+	}
+	return floors, nil
+}
+
+// ListLabels returns all labels
+func (c *WebSocketClient) ListLabels() ([]Label, error) {
+	var labels []Label
+	if err := c.sendTyped("config/label_registry/list", nil, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// ListZones returns all storage-managed zones
+func (c *WebSocketClient) ListZones() ([]Zone, error) {
+	var zones []Zone
+	if err := c.sendTyped("zone/list", nil, &zones); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// ListConfigEntries returns all config entries, optionally filtered by domain
+func (c *WebSocketClient) ListConfigEntries(domain string) ([]ConfigEntry, error) {
+	params := map[string]interface{}{}
+	if domain != "" {
+		params["domain"] = domain
+	}
+	var entries []ConfigEntry
+	if err := c.sendTyped("config_entries/get", params, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ListDashboards returns all storage dashboards
+func (c *WebSocketClient) ListDashboards() ([]Dashboard, error) {
+	var dashboards []Dashboard
+	if err := c.sendTyped("lovelace/dashboards/list", nil, &dashboards); err != nil {
+		return nil, err
+	}
+	return dashboards, nil
+}
+
+// ListBackups returns the backups known to the backup integration
+func (c *WebSocketClient) ListBackups() ([]Backup, error) {
+	var info struct {
+		Backups []Backup `json:"backups"`
+	}
+	if err := c.sendTyped("backup/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return info.Backups, nil
+}
+
+// sendTyped sends a command and decodes its result into v
+func (c *WebSocketClient) sendTyped(cmdType string, params map[string]interface{}, v interface{}) error {
+	result, err := c.SendCommand(cmdType, params)
+	if err != nil {
+		return err
+	}
+	if err := decodeResult(result, v); err != nil {
+		return fmt.Errorf("unexpected response type: %w", err)
+	}
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -44,7 +43,6 @@ func runActionCall(cmd *cobra.Command, args []string) error {
 	if actionName == "" {
 		return fmt.Errorf("action name is required (use --action flag or positional argument)")
 	}
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	// Parse action name
@@ -71,8 +69,7 @@ func runActionCall(cmd *cobra.Command, args []string) error {
 		serviceData["area_id"] = actionCallArea
 	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/home-assistant/hab/client/clienttest"
+)
+
+func TestActionCall(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	out, err := runCommand(t, "action", "call", "light.turn_on", "-e", "light.kitchen", "-d", `{"brightness": 128}`)
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if out != "Action light.turn_on called successfully.\n" {
+		t.Errorf("output = %q", out)
+	}
+
+	want := []clienttest.ServiceCall{{
+		Domain:  "light",
+		Service: "turn_on",
+		Data:    map[string]interface{}{"entity_id": "light.kitchen", "brightness": float64(128)},
+	}}
+	if got := srv.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %+v, want %+v", got, want)
+	}
+}
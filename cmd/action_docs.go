@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -33,7 +32,6 @@ func runActionDocs(cmd *cobra.Command, args []string) error {
 	if actionName == "" {
 		return fmt.Errorf("action name is required (use --action flag or positional argument)")
 	}
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	// Parse action name
@@ -44,8 +42,7 @@ func runActionDocs(cmd *cobra.Command, args []string) error {
 	domain := parts[0]
 	service := parts[1]
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -25,11 +24,9 @@ func runActionList(cmd *cobra.Command, args []string) error {
 		domain = args[0]
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
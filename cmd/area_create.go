@@ -3,8 +3,8 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
+	"github.com/home-assistant/hab/input"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -17,30 +17,42 @@ var (
 var areaCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new area",
-	Long:  `Create a new area in Home Assistant.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runAreaCreate,
+	Long: `Create a new area in Home Assistant.
+
+With --bulk, the input is a list of areas with a 'name' and any of the
+area registry fields, such as floor_id, icon and aliases.`,
+	Args: bulkArgs(cobra.ExactArgs(1)),
+	RunE: runAreaCreate,
 }
 
 func init() {
 	areaCmd.AddCommand(areaCreateCmd)
 	areaCreateCmd.Flags().StringVar(&areaCreateFloor, "floor", "", "Floor ID to assign")
 	areaCreateCmd.Flags().StringVar(&areaCreateIcon, "icon", "", "Icon for the area")
+	addBulkFlags(areaCreateCmd, "areas")
 }
 
 func runAreaCreate(cmd *cobra.Command, args []string) error {
-	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
+	if bulkInput.Bulk {
+		items, err := input.ParseInputList(bulkInput.Data, bulkInput.File, bulkInput.Format)
+		if err != nil {
+			return err
+		}
+		ws, err := connectWebSocket(cmd)
+		if err != nil {
+			return err
+		}
+		defer ws.Close()
+		return runBulk(items, textMode, func(item map[string]interface{}) (string, interface{}, error) {
+			return bulkCreateNamed(item, ws.AreaRegistryCreate)
+		})
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	name := args[0]
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -36,17 +35,10 @@ func runAreaGet(cmd *cobra.Command, args []string) error {
 	if areaID == "" {
 		return fmt.Errorf("area ID is required (use --area flag or positional argument)")
 	}
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -34,17 +33,10 @@ func init() {
 }
 
 func runAreaList(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -87,10 +79,10 @@ func runAreaList(cmd *cobra.Command, args []string) error {
 
 	// Handle count mode
 	if areaListCount {
-		if textMode {
+		if customTextOutput(textMode) {
 			fmt.Printf("Count: %d\n", len(result))
 		} else {
-			client.PrintOutput(map[string]interface{}{"count": len(result)}, false, "")
+			client.PrintOutput(map[string]interface{}{"count": len(result)}, textMode, "")
 		}
 		return nil
 	}
@@ -102,7 +94,7 @@ func runAreaList(cmd *cobra.Command, args []string) error {
 
 	// Handle brief mode
 	if areaListBrief {
-		if textMode {
+		if customTextOutput(textMode) {
 			for _, item := range result {
 				name, _ := item["name"].(string)
 				areaID, _ := item["area_id"].(string)
@@ -116,13 +108,13 @@ func runAreaList(cmd *cobra.Command, args []string) error {
 					"name":    item["name"],
 				})
 			}
-			client.PrintOutput(brief, false, "")
+			client.PrintOutput(brief, textMode, "")
 		}
 		return nil
 	}
 
 	// Full output
-	if textMode {
+	if customTextOutput(textMode) {
 		if len(result) == 0 {
 			fmt.Println("No areas.")
 			return nil
@@ -138,7 +130,7 @@ func runAreaList(cmd *cobra.Command, args []string) error {
 			}
 		}
 	} else {
-		client.PrintOutput(result, false, "")
+		client.PrintOutput(result, textMode, "")
 	}
 	return nil
 }
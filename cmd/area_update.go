@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -18,9 +17,12 @@ var (
 var areaUpdateCmd = &cobra.Command{
 	Use:   "update <area_id>",
 	Short: "Update an area",
-	Long:  `Update an existing area.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runAreaUpdate,
+	Long: `Update an existing area.
+
+With --patch (JSON Patch, RFC 6902) or --merge (JSON merge patch, RFC 7396)
+the changes are given as a patch of the area instead of field flags.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAreaUpdate,
 }
 
 func init() {
@@ -28,13 +30,18 @@ func init() {
 	areaUpdateCmd.Flags().StringVar(&areaUpdateName, "name", "", "New name for the area")
 	areaUpdateCmd.Flags().StringVar(&areaUpdateFloor, "floor", "", "Floor ID to assign")
 	areaUpdateCmd.Flags().StringVar(&areaUpdateIcon, "icon", "", "Icon for the area")
+	addPatchInputFlags(areaUpdateCmd)
 }
 
 func runAreaUpdate(cmd *cobra.Command, args []string) error {
 	areaID := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
+	patch, err := readFlagsPatch(cmd)
+	if err != nil {
+		return err
+	}
+
 	params := make(map[string]interface{})
 	if areaUpdateName != "" {
 		params["name"] = areaUpdateName
@@ -46,22 +53,30 @@ func runAreaUpdate(cmd *cobra.Command, args []string) error {
 		params["icon"] = areaUpdateIcon
 	}
 
-	if len(params) == 0 {
-		return fmt.Errorf("no update parameters provided")
-	}
-
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	if err := checkUpdateParams(patch, params); err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
 
+	if patch != nil {
+		items, err := ws.AreaRegistryList()
+		if err != nil {
+			return err
+		}
+		current, err := findItem(items, "area_id", areaID, "area")
+		if err != nil {
+			return err
+		}
+		if params, err = patchParams(current, patch, "area_id"); err != nil {
+			return err
+		}
+	}
+
 	result, err := ws.AreaRegistryUpdate(areaID, params)
 	if err != nil {
 		return err
@@ -1,7 +1,13 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/home-assistant/hab/auth"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var authCmd = &cobra.Command{
@@ -14,3 +20,44 @@ var authCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(authCmd)
 }
+
+// credentialsPassphrase returns the passphrase protecting stored credentials.
+// It is asked for at most once per command.
+var credentialsPassphrase = sync.OnceValues(func() (string, error) {
+	return readPassphrase("HAB_PASSPHRASE", "Credentials passphrase: ", false)
+})
+
+// readPassphrase returns the passphrase in the environment variable envVar,
+// or prompts for it when stdin is a terminal. With confirm, it is entered twice.
+func readPassphrase(envVar, prompt string, confirm bool) (string, error) {
+	if passphrase := os.Getenv(envVar); passphrase != "" {
+		return passphrase, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("%w: set %s", auth.ErrPassphraseRequired, envVar)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if len(passphrase) == 0 {
+		return "", auth.ErrPassphraseRequired
+	}
+
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if string(again) != string(passphrase) {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return string(passphrase), nil
+}
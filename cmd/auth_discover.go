@@ -1,6 +1,13 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/home-assistant/hab/auth"
@@ -9,28 +16,91 @@ import (
 	"github.com/spf13/viper"
 )
 
-var discoverTimeout int
+var (
+	discoverMDNSTimeout  time.Duration
+	discoverProbe        []string
+	discoverProbeFile    string
+	discoverProbePort    int
+	discoverProbeWorkers int
+	discoverProbeTimeout time.Duration
+	discoverNoMDNS       bool
+)
 
 var authDiscoverCmd = &cobra.Command{
 	Use:   "discover",
 	Short: "Discover Home Assistant servers on the network",
-	Long:  `Search for Home Assistant servers on the local network using mDNS/DNS-SD.`,
-	RunE:  runAuthDiscover,
+	Long: `Search for Home Assistant servers on the local network using mDNS/DNS-SD.
+
+mDNS does not cross VLANs or Docker networks. With --probe or --probe-file,
+hab also requests /api/discovery_info and /manifest.json from the given CIDR
+ranges, hosts, host:port pairs or URLs, and merges the results with mDNS.
+
+--mdns-timeout sets how long mDNS listens for answers; the global --timeout
+bounds the whole command.
+
+Examples:
+  hab auth discover --probe 192.168.20.0/24
+  hab auth discover --probe nas.lan,10.0.0.5:8124 --no-mdns
+  hab auth discover --probe-file hosts.txt`,
+	RunE: runAuthDiscover,
 }
 
 func init() {
 	authCmd.AddCommand(authDiscoverCmd)
 
-	authDiscoverCmd.Flags().IntVar(&discoverTimeout, "timeout", 3, "Discovery timeout in seconds")
+	authDiscoverCmd.Flags().DurationVar(&discoverMDNSTimeout, "mdns-timeout", 3*time.Second, "How long to listen for mDNS answers")
+	authDiscoverCmd.Flags().StringSliceVar(&discoverProbe, "probe", nil, "CIDR ranges, hosts or URLs to probe (repeatable)")
+	authDiscoverCmd.Flags().StringVar(&discoverProbeFile, "probe-file", "", "File listing probe targets, one per line ('-' for stdin)")
+	authDiscoverCmd.Flags().IntVar(&discoverProbePort, "probe-port", auth.DefaultProbePort, "Port to probe on targets without one")
+	authDiscoverCmd.Flags().IntVar(&discoverProbeWorkers, "probe-workers", auth.DefaultProbeWorkers, "Number of hosts probed at the same time")
+	authDiscoverCmd.Flags().DurationVar(&discoverProbeTimeout, "probe-timeout", auth.DefaultProbeTimeout, "Timeout of each probe request")
+	authDiscoverCmd.Flags().BoolVar(&discoverNoMDNS, "no-mdns", false, "Skip mDNS and only probe")
 }
 
 func runAuthDiscover(cmd *cobra.Command, args []string) error {
 	textMode := viper.GetBool("text")
 
-	servers, err := auth.DiscoverServers(time.Duration(discoverTimeout) * time.Second)
+	targets, err := discoverProbeTargets()
 	if err != nil {
 		return err
 	}
+	if discoverNoMDNS && len(targets) == 0 {
+		return fmt.Errorf("--no-mdns requires --probe or --probe-file")
+	}
+
+	var (
+		wg                  sync.WaitGroup
+		mdnsServers, probed []auth.DiscoveredServer
+		mdnsErr, probeErr   error
+	)
+	if !discoverNoMDNS {
+		wait := discoverMDNSTimeout
+		if deadline, ok := cmd.Context().Deadline(); ok && time.Until(deadline) < wait {
+			wait = time.Until(deadline)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mdnsServers, mdnsErr = auth.DiscoverServers(wait)
+		}()
+	}
+	if len(targets) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			probed, probeErr = probeServers(cmd.Context(), targets)
+		}()
+	}
+	wg.Wait()
+
+	if probeErr != nil {
+		return probeErr
+	}
+	// Probing can still find servers when mDNS is unavailable
+	if mdnsErr != nil && len(targets) == 0 {
+		return mdnsErr
+	}
+	servers := auth.MergeServers(mdnsServers, probed)
 
 	// Convert to output format
 	results := make([]map[string]interface{}, len(servers))
@@ -57,3 +127,49 @@ func runAuthDiscover(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// discoverProbeTargets returns the targets of --probe and --probe-file
+func discoverProbeTargets() ([]string, error) {
+	targets := append([]string(nil), discoverProbe...)
+	if discoverProbeFile == "" {
+		return targets, nil
+	}
+
+	var r io.Reader = os.Stdin
+	if discoverProbeFile != "-" {
+		f, err := os.Open(discoverProbeFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read probe file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// Lines may hold several targets separated by spaces or commas,
+		// followed by a # comment
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		targets = append(targets, strings.FieldsFunc(line, func(c rune) bool {
+			return c == ',' || c == ' ' || c == '\t'
+		})...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read probe file: %w", err)
+	}
+	return targets, nil
+}
+
+// probeServers probes targets directly, not through a configured proxy, but
+// with the TLS settings for HTTPS targets
+func probeServers(ctx context.Context, targets []string) ([]auth.DiscoveredServer, error) {
+	transport := client.NewHTTPTransport(transportConfig)
+	transport.Proxy = nil
+	return auth.ProbeServers(ctx, auth.ProbeOptions{
+		Targets:   targets,
+		Port:      discoverProbePort,
+		Workers:   discoverProbeWorkers,
+		Timeout:   discoverProbeTimeout,
+		Transport: transport,
+	})
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuthDiscoverGlobalTimeout(t *testing.T) {
+	newTestServer(t, nil)
+	ha := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/discovery_info" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"location_name": "Home", "version": "2024.6.0", "uuid": "abc"}`)
+	}))
+	defer ha.Close()
+
+	// --timeout is the global duration flag, not a discover flag in seconds
+	out, err := runCommand(t, "auth", "discover", "--timeout", "30s", "--no-mdns", "--probe", ha.URL, "--json")
+	if err != nil {
+		t.Fatalf("discover failed: %v", err)
+	}
+	if !strings.Contains(out, `"uuid": "abc"`) {
+		t.Errorf("output = %q", out)
+	}
+}
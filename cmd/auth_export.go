@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var authExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export credentials encrypted with a passphrase",
+	Long: `Export the credentials of the current context, encrypted with a passphrase,
+to move them to another machine with 'hab auth import'.
+
+The passphrase is taken from HAB_PASSPHRASE or asked for. Without a file the
+exported credentials are printed.
+
+Examples:
+  hab auth export hab-credentials.txt
+  hab auth export --context production`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAuthExport,
+}
+
+func init() {
+	authCmd.AddCommand(authExportCmd)
+}
+
+func runAuthExport(cmd *cobra.Command, args []string) error {
+	textMode := viper.GetBool("text")
+	manager := newAuthManager()
+
+	creds, err := manager.GetCredentials()
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := readPassphrase("HAB_PASSPHRASE", "Export passphrase: ", true)
+	if err != nil {
+		return err
+	}
+	exported, err := auth.ExportCredentials(creds, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		if textMode {
+			fmt.Println(exported)
+			return nil
+		}
+		client.PrintOutput(map[string]interface{}{
+			"context":     currentContext(),
+			"credentials": exported,
+		}, textMode, "")
+		return nil
+	}
+
+	if err := os.WriteFile(args[0], []byte(exported+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", args[0], err)
+	}
+	result := map[string]interface{}{
+		"context": currentContext(),
+		"file":    args[0],
+	}
+	client.PrintSuccess(result, textMode, fmt.Sprintf("Credentials exported to %s.", args[0]))
+	return nil
+}
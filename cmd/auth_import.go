@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var authImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import credentials exported with 'hab auth export'",
+	Long: `Import credentials exported with 'hab auth export' into the current context,
+replacing its credentials. Use - to read them from stdin.
+
+The passphrase is taken from HAB_PASSPHRASE or asked for.
+
+Examples:
+  hab auth import hab-credentials.txt
+  hab auth import hab-credentials.txt --context production`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAuthImport,
+}
+
+func init() {
+	authCmd.AddCommand(authImportCmd)
+}
+
+func runAuthImport(cmd *cobra.Command, args []string) error {
+	textMode := viper.GetBool("text")
+
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	creds, err := auth.ImportCredentials(data, func() (string, error) {
+		return readPassphrase("HAB_PASSPHRASE", "Import passphrase: ", false)
+	})
+	if err != nil {
+		return err
+	}
+
+	manager := newAuthManager()
+	if err := manager.Save(creds); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"context": currentContext(),
+		"url":     creds.URL,
+	}
+	client.PrintSuccess(result, textMode, fmt.Sprintf("Credentials for %s imported into context %s.", creds.URL, currentContext()))
+	return nil
+}
@@ -19,13 +19,28 @@ var (
 	loginToken       bool
 	loginURL         string
 	loginAccessToken string
+	loginHeadless    bool
+	loginBareCode    bool
+	loginCallback    string
 )
 
 var authLoginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Authenticate with Home Assistant",
-	Long:  `Authenticate with Home Assistant using OAuth flow or a long-lived access token.`,
-	RunE:  runAuthLogin,
+	Long: `Authenticate with Home Assistant using OAuth flow or a long-lived access token.
+
+The OAuth flow opens a browser and receives the login on a local callback
+server. Over SSH or in a container, use --headless to open the printed URL in
+any browser and paste back the URL it is redirected to, or use --callback-addr
+to listen on a fixed port that you forward. The pasted URL carries the state
+that proves the login is the one hab started; --allow-bare-code also accepts
+just the code, without that check.
+
+Examples:
+  hab auth login --url http://homeassistant.local:8123 --headless
+  ssh -L 8765:127.0.0.1:8765 remote-host
+  hab auth login --url http://homeassistant.local:8123 --callback-addr 127.0.0.1:8765`,
+	RunE: runAuthLogin,
 }
 
 func init() {
@@ -34,13 +49,19 @@ func init() {
 	authLoginCmd.Flags().BoolVar(&loginToken, "token", false, "Use long-lived access token instead of OAuth")
 	authLoginCmd.Flags().StringVar(&loginURL, "url", "", "Home Assistant URL")
 	authLoginCmd.Flags().StringVar(&loginAccessToken, "access-token", "", "Long-lived access token (non-interactive mode)")
+	authLoginCmd.Flags().BoolVar(&loginHeadless, "headless", false, "Log in without a local browser by pasting the redirect URL")
+	authLoginCmd.Flags().StringVar(&loginCallback, "callback-addr", "", "Address (host:port) for the OAuth callback server to listen on")
+	authLoginCmd.Flags().BoolVar(&loginBareCode, "allow-bare-code", false, "With --headless, accept just the authorization code; its state cannot be verified")
+	authLoginCmd.MarkFlagsMutuallyExclusive("headless", "callback-addr")
 }
 
 func runAuthLogin(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
-	manager := auth.NewManager(configDir)
+	manager := newAuthManager()
 
+	if loginBareCode && !loginHeadless {
+		return fmt.Errorf("--allow-bare-code requires --headless")
+	}
 	if loginToken {
 		return loginWithToken(manager, textMode)
 	}
@@ -128,6 +149,7 @@ func loginWithToken(manager *auth.Manager, textMode bool) error {
 
 	// Validate the token by making a test request
 	restClient := client.NewRestClient(url, accessToken)
+	restClient.Transport = transportConfig
 	config, err := restClient.GetConfig()
 	if err != nil {
 		return fmt.Errorf("authentication failed: %w", err)
@@ -170,13 +192,18 @@ func loginWithOAuth(manager *auth.Manager, textMode bool) error {
 	}
 
 	// Run OAuth flow
-	creds, err := auth.RunOAuthFlow(url)
+	creds, err := auth.RunOAuthFlowWithOptions(url, auth.OAuthOptions{
+		CallbackAddr:  loginCallback,
+		Headless:      loginHeadless,
+		AllowBareCode: loginBareCode,
+	})
 	if err != nil {
 		return fmt.Errorf("OAuth flow failed: %w", err)
 	}
 
 	// Validate and get info
 	restClient := client.NewRestClient(creds.URL, creds.AccessToken)
+	restClient.Transport = transportConfig
 	config, err := restClient.GetConfig()
 	if err != nil {
 		return fmt.Errorf("authentication validation failed: %w", err)
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -19,11 +18,14 @@ func init() {
 }
 
 func runAuthLogout(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
-	manager := auth.NewManager(configDir)
+	manager := newAuthManager()
 
-	if manager.Logout() {
+	removed, err := manager.Logout()
+	if err != nil {
+		return err
+	}
+	if removed {
 		client.PrintSuccess(nil, textMode, "Successfully logged out.")
 	} else {
 		client.PrintSuccess(nil, textMode, "No credentials to remove.")
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var authRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "Re-encrypt stored credentials with a new key",
+	Long: `Re-encrypt the credentials files of all contexts.
+
+With --credential-store passphrase the files are encrypted with a new
+passphrase, taken from HAB_NEW_PASSPHRASE or asked for. Otherwise they are
+encrypted with the machine key. The current passphrase, if any, is taken from
+HAB_PASSPHRASE or asked for.
+
+Examples:
+  hab auth rotate-key --credential-store passphrase   # set or change the passphrase
+  hab auth rotate-key                                 # go back to the machine key`,
+	Args: cobra.NoArgs,
+	RunE: runAuthRotateKey,
+}
+
+func init() {
+	authCmd.AddCommand(authRotateKeyCmd)
+}
+
+func runAuthRotateKey(cmd *cobra.Command, args []string) error {
+	textMode := viper.GetBool("text")
+
+	store := viper.GetString("credential-store")
+	if store != auth.StoreFile && store != auth.StorePassphrase {
+		return fmt.Errorf("rotate-key needs the file or passphrase credential store, not %q", store)
+	}
+
+	newPassphrase := ""
+	if store == auth.StorePassphrase {
+		var err error
+		newPassphrase, err = readPassphrase("HAB_NEW_PASSPHRASE", "New passphrase: ", true)
+		if err != nil {
+			return err
+		}
+	}
+
+	rotated, err := auth.RotateKey(viper.GetString("config"), credentialsPassphrase, newPassphrase)
+	if err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{
+		"contexts": rotated,
+		"key":      "machine",
+	}
+	if newPassphrase != "" {
+		result["key"] = "passphrase"
+	}
+	client.PrintSuccess(result, textMode, fmt.Sprintf("Re-encrypted the credentials of %d context(s).", len(rotated)))
+	return nil
+}
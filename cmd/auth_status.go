@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -19,9 +18,8 @@ func init() {
 }
 
 func runAuthStatus(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
-	manager := auth.NewManager(configDir)
+	manager := newAuthManager()
 
 	status := manager.GetAuthStatus()
 	client.PrintOutput(status, textMode, "")
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+)
+
+var authTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage access and refresh tokens",
+	Long: `List, create and revoke the tokens of the authenticated user.
+
+Long-lived access tokens are listed together with the refresh tokens of
+logged-in sessions, each with the client it was issued to and when and from
+where it was last used.`,
+}
+
+func init() {
+	authCmd.AddCommand(authTokenCmd)
+}
+
+// tokenTypeLongLived is the refresh token type backing long-lived access tokens
+const tokenTypeLongLived = "long_lived_access_token"
+
+// tokenSummary returns the fields of a refresh token shown in listings,
+// few enough to fit a text table
+func tokenSummary(token map[string]interface{}) map[string]interface{} {
+	name := token["client_name"]
+	if name == nil || name == "" {
+		name = token["client_id"]
+	}
+	return map[string]interface{}{
+		"id":           token["id"],
+		"name":         name,
+		"type":         token["type"],
+		"client":       token["client_id"],
+		"last_used_at": token["last_used_at"],
+		"last_used_ip": token["last_used_ip"],
+	}
+}
+
+// findToken returns the refresh token with the given ID, or the only one
+// with the given client name
+func findToken(ws client.WebSocketAPI, idOrName string) (map[string]interface{}, error) {
+	tokens, err := ws.AuthRefreshTokenList()
+	if err != nil {
+		return nil, err
+	}
+
+	var byName []map[string]interface{}
+	for _, t := range tokens {
+		token, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if token["id"] == idOrName {
+			return token, nil
+		}
+		if token["client_name"] == idOrName {
+			byName = append(byName, token)
+		}
+	}
+
+	switch len(byName) {
+	case 0:
+		return nil, fmt.Errorf("token %q not found", idOrName)
+	case 1:
+		return byName[0], nil
+	default:
+		return nil, fmt.Errorf("%d tokens are named %q, use the token ID", len(byName), idOrName)
+	}
+}
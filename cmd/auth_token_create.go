@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var authTokenCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a long-lived access token",
+	Long: `Create a long-lived access token for the authenticated user.
+
+The token is shown only once. Use --save to store it as the credentials of
+the current context instead of the credentials used to create it.
+
+Examples:
+  hab auth token create ci-bot --lifespan 90
+  hab auth token create hab --save`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAuthTokenCreate,
+}
+
+var (
+	authTokenCreateLifespan int
+	authTokenCreateSave     bool
+)
+
+func init() {
+	authTokenCmd.AddCommand(authTokenCreateCmd)
+	authTokenCreateCmd.Flags().IntVar(&authTokenCreateLifespan, "lifespan", 3650, "Days until the token expires")
+	authTokenCreateCmd.Flags().BoolVar(&authTokenCreateSave, "save", false, "Store the token as the credentials of the current context")
+}
+
+func runAuthTokenCreate(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	textMode := viper.GetBool("text")
+
+	if authTokenCreateLifespan <= 0 {
+		return fmt.Errorf("--lifespan must be at least 1 day")
+	}
+
+	manager := newAuthManager()
+	creds, err := manager.GetCredentials()
+	if err != nil {
+		return err
+	}
+	if authTokenCreateSave && creds.IsSupervisor() {
+		return fmt.Errorf("--save is not available with the Supervisor token")
+	}
+
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	user, err := ws.AuthCurrentUser()
+	if err != nil {
+		return err
+	}
+
+	token, err := ws.AuthLongLivedTokenCreate(name, authTokenCreateLifespan)
+	if err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{
+		"name":          name,
+		"user":          user["name"],
+		"lifespan_days": authTokenCreateLifespan,
+		"token":         token,
+	}
+
+	if authTokenCreateSave {
+		if err := manager.Save(&auth.Credentials{URL: creds.URL, AccessToken: token}); err != nil {
+			return fmt.Errorf("token created but not saved: %w", err)
+		}
+		result["saved"] = true
+		client.PrintSuccess(result, textMode, fmt.Sprintf("Token %s created and saved for context %s.", name, currentContext()))
+		return nil
+	}
+
+	client.PrintSuccess(result, textMode, fmt.Sprintf("Token %s created. Store it now, it is not shown again.", name))
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var authTokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List tokens",
+	Long:  `List the long-lived access tokens and refresh tokens of the authenticated user.`,
+	RunE:  runAuthTokenList,
+}
+
+var authTokenListLongLived bool
+
+func init() {
+	authTokenCmd.AddCommand(authTokenListCmd)
+	authTokenListCmd.Flags().BoolVar(&authTokenListLongLived, "long-lived", false, "Only list long-lived access tokens")
+}
+
+func runAuthTokenList(cmd *cobra.Command, args []string) error {
+	textMode := viper.GetBool("text")
+
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	tokens, err := ws.AuthRefreshTokenList()
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(tokens))
+	for _, t := range tokens {
+		token, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if authTokenListLongLived && token["type"] != tokenTypeLongLived {
+			continue
+		}
+		result = append(result, tokenSummary(token))
+	}
+
+	client.PrintOutput(result, textMode, "")
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var authTokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <id-or-name>",
+	Short: "Revoke a token",
+	Long: `Revoke a long-lived access token or the refresh token of a session, given
+its ID or name from 'hab auth token list'.
+
+Examples:
+  hab auth token revoke ci-bot -f`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAuthTokenRevoke,
+}
+
+var authTokenRevokeForce bool
+
+func init() {
+	authTokenCmd.AddCommand(authTokenRevokeCmd)
+	authTokenRevokeCmd.Flags().BoolVarP(&authTokenRevokeForce, "force", "f", false, "Skip confirmation")
+}
+
+func runAuthTokenRevoke(cmd *cobra.Command, args []string) error {
+	textMode := viper.GetBool("text")
+
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	token, err := findToken(ws, args[0])
+	if err != nil {
+		return err
+	}
+	summary := tokenSummary(token)
+
+	if !authTokenRevokeForce && !textMode {
+		prompt := fmt.Sprintf("Revoke token %v (%v)? [y/N]: ", summary["name"], summary["id"])
+		if token["is_current"] == true {
+			prompt = fmt.Sprintf("Token %v is the one hab is using. Revoke it? [y/N]: ", summary["name"])
+		}
+		fmt.Print(prompt)
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	id, _ := token["id"].(string)
+	if err := ws.AuthRefreshTokenDelete(id); err != nil {
+		return err
+	}
+
+	client.PrintSuccess(summary, textMode, fmt.Sprintf("Token %v revoked.", summary["name"]))
+	return nil
+}
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -30,22 +30,25 @@ func init() {
 	automationActionCreateCmd.Flags().StringVarP(&automationActionCreateData, "data", "d", "", "Action configuration as JSON")
 	automationActionCreateCmd.Flags().StringVarP(&automationActionCreateFile, "file", "f", "", "Path to config file")
 	automationActionCreateCmd.Flags().StringVar(&automationActionCreateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(automationActionCreateCmd)
+	addValidateFlag(automationActionCreateCmd)
 }
 
 func runAutomationActionCreate(cmd *cobra.Command, args []string) error {
 	automationID := args[0]
 	automationID = strings.TrimPrefix(automationID, "automation.")
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	actionConfig, err := input.ParseInput(automationActionCreateData, automationActionCreateFile, automationActionCreateFormat)
 	if err != nil {
 		return err
 	}
+	if err := validateInput(cmd, schema.Action, actionConfig); err != nil {
+		return err
+	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
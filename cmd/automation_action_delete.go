@@ -7,7 +7,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -36,11 +35,9 @@ func runAutomationActionDelete(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid action index: %s", args[1])
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
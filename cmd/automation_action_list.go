@@ -3,7 +3,6 @@ package cmd
 import (
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -25,11 +24,9 @@ func runAutomationActionList(cmd *cobra.Command, args []string) error {
 	automationID := args[0]
 	automationID = strings.TrimPrefix(automationID, "automation.")
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -5,9 +5,9 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -31,6 +31,9 @@ func init() {
 	automationActionUpdateCmd.Flags().StringVarP(&automationActionUpdateData, "data", "d", "", "Action configuration as JSON (replaces entire action)")
 	automationActionUpdateCmd.Flags().StringVarP(&automationActionUpdateFile, "file", "f", "", "Path to config file")
 	automationActionUpdateCmd.Flags().StringVar(&automationActionUpdateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(automationActionUpdateCmd)
+	addValidateFlag(automationActionUpdateCmd)
+	addPatchFlags(automationActionUpdateCmd)
 }
 
 func runAutomationActionUpdate(cmd *cobra.Command, args []string) error {
@@ -41,16 +44,25 @@ func runAutomationActionUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid action index: %s", args[1])
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	newAction, err := input.ParseInput(automationActionUpdateData, automationActionUpdateFile, automationActionUpdateFormat)
+	patch, err := readConfigPatch(cmd, automationActionUpdateData, automationActionUpdateFile, automationActionUpdateFormat)
 	if err != nil {
 		return err
 	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	var newAction map[string]interface{}
+	if patch == nil {
+		newAction, err = input.ParseInput(automationActionUpdateData, automationActionUpdateFile, automationActionUpdateFormat)
+		if err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Action, newAction); err != nil {
+			return err
+		}
+	}
+
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -83,6 +95,19 @@ func runAutomationActionUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("action index %d out of range (0-%d)", actionIndex, len(actions)-1)
 	}
 
+	if patch != nil {
+		current, ok := actions[actionIndex].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("action at index %d is not an object", actionIndex)
+		}
+		if newAction, err = patch(current); err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Action, newAction); err != nil {
+			return err
+		}
+	}
+
 	// Update the action
 	actions[actionIndex] = newAction
 	config[actionKey] = actions
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -30,22 +30,25 @@ func init() {
 	automationConditionCreateCmd.Flags().StringVarP(&automationConditionCreateData, "data", "d", "", "Condition configuration as JSON")
 	automationConditionCreateCmd.Flags().StringVarP(&automationConditionCreateFile, "file", "f", "", "Path to config file")
 	automationConditionCreateCmd.Flags().StringVar(&automationConditionCreateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(automationConditionCreateCmd)
+	addValidateFlag(automationConditionCreateCmd)
 }
 
 func runAutomationConditionCreate(cmd *cobra.Command, args []string) error {
 	automationID := args[0]
 	automationID = strings.TrimPrefix(automationID, "automation.")
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	conditionConfig, err := input.ParseInput(automationConditionCreateData, automationConditionCreateFile, automationConditionCreateFormat)
 	if err != nil {
 		return err
 	}
+	if err := validateInput(cmd, schema.Condition, conditionConfig); err != nil {
+		return err
+	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -5,9 +5,9 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -31,6 +31,9 @@ func init() {
 	automationConditionUpdateCmd.Flags().StringVarP(&automationConditionUpdateData, "data", "d", "", "Condition configuration as JSON (replaces entire condition)")
 	automationConditionUpdateCmd.Flags().StringVarP(&automationConditionUpdateFile, "file", "f", "", "Path to config file")
 	automationConditionUpdateCmd.Flags().StringVar(&automationConditionUpdateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(automationConditionUpdateCmd)
+	addValidateFlag(automationConditionUpdateCmd)
+	addPatchFlags(automationConditionUpdateCmd)
 }
 
 func runAutomationConditionUpdate(cmd *cobra.Command, args []string) error {
@@ -41,16 +44,25 @@ func runAutomationConditionUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid condition index: %s", args[1])
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	newCondition, err := input.ParseInput(automationConditionUpdateData, automationConditionUpdateFile, automationConditionUpdateFormat)
+	patch, err := readConfigPatch(cmd, automationConditionUpdateData, automationConditionUpdateFile, automationConditionUpdateFormat)
 	if err != nil {
 		return err
 	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	var newCondition map[string]interface{}
+	if patch == nil {
+		newCondition, err = input.ParseInput(automationConditionUpdateData, automationConditionUpdateFile, automationConditionUpdateFormat)
+		if err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Condition, newCondition); err != nil {
+			return err
+		}
+	}
+
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -83,6 +95,19 @@ func runAutomationConditionUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("condition index %d out of range (0-%d)", conditionIndex, len(conditions)-1)
 	}
 
+	if patch != nil {
+		current, ok := conditions[conditionIndex].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("condition at index %d is not an object", conditionIndex)
+		}
+		if newCondition, err = patch(current); err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Condition, newCondition); err != nil {
+			return err
+		}
+	}
+
 	// Update the condition
 	conditions[conditionIndex] = newCondition
 	config[conditionKey] = conditions
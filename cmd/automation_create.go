@@ -3,9 +3,9 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -14,14 +14,18 @@ var (
 	automationCreateData   string
 	automationCreateFile   string
 	automationCreateFormat string
+	automationCreateBulk   bool
 )
 
 var automationCreateCmd = &cobra.Command{
-	Use:     "create <id>",
-	Short:   "Create a new automation",
-	Long:    `Create a new automation from JSON or YAML. The ID is used to identify the automation.`,
+	Use:   "create <id>",
+	Short: "Create a new automation",
+	Long: `Create a new automation from JSON or YAML. The ID is used to identify the automation.
+
+With --bulk, the input is a list of automations, each with an 'id' field,
+as in automations.yaml.`,
 	GroupID: automationGroupCommands,
-	Args:    cobra.ExactArgs(1),
+	Args:    bulkArgs(cobra.ExactArgs(1)),
 	RunE:    runAutomationCreate,
 }
 
@@ -30,29 +34,51 @@ func init() {
 	automationCreateCmd.Flags().StringVarP(&automationCreateData, "data", "d", "", "Automation configuration as JSON")
 	automationCreateCmd.Flags().StringVarP(&automationCreateFile, "file", "f", "", "Path to config file")
 	automationCreateCmd.Flags().StringVar(&automationCreateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(automationCreateCmd)
+	addValidateFlag(automationCreateCmd)
+	addBulkFlag(automationCreateCmd, &automationCreateBulk, "automations")
 }
 
 func runAutomationCreate(cmd *cobra.Command, args []string) error {
-	automationID := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
+	if automationCreateBulk {
+		items, err := input.ParseInputList(automationCreateData, automationCreateFile, automationCreateFormat)
+		if err != nil {
+			return err
+		}
+		restClient, err := getRestClient(cmd)
+		if err != nil {
+			return err
+		}
+		return runBulk(items, textMode, func(item map[string]interface{}) (string, interface{}, error) {
+			automationID, err := bulkString(item, "id")
+			if err != nil {
+				return "", nil, err
+			}
+			if err := validateInput(cmd, schema.Automation, item); err != nil {
+				return automationID, nil, err
+			}
+			result, err := createAutomation(restClient, automationID, item)
+			return automationID, result, err
+		})
+	}
+
+	automationID := args[0]
 	config, err := input.ParseInput(automationCreateData, automationCreateFile, automationCreateFormat)
 	if err != nil {
 		return err
 	}
-
-	if _, ok := config["alias"]; !ok {
-		return fmt.Errorf("automation must have an 'alias' field")
+	if err := validateInput(cmd, schema.Automation, config); err != nil {
+		return err
 	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
 
-	result, err := restClient.Post(fmt.Sprintf("config/automation/config/%s", automationID), config)
+	result, err := createAutomation(restClient, automationID, config)
 	if err != nil {
 		return err
 	}
@@ -60,3 +86,11 @@ func runAutomationCreate(cmd *cobra.Command, args []string) error {
 	client.PrintSuccess(result, textMode, fmt.Sprintf("Automation %s created successfully.", automationID))
 	return nil
 }
+
+// createAutomation validates and saves a new automation
+func createAutomation(restClient client.RestAPI, automationID string, config map[string]interface{}) (interface{}, error) {
+	if _, ok := config["alias"]; !ok {
+		return nil, fmt.Errorf("automation must have an 'alias' field")
+	}
+	return restClient.Post(fmt.Sprintf("config/automation/config/%s", automationID), config)
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
 	"github.com/spf13/cobra"
@@ -34,12 +33,12 @@ func init() {
 	automationCreateFromBlueprintCmd.Flags().StringVarP(&automationFromBlueprintData, "data", "d", "", "Blueprint inputs as JSON (must include alias)")
 	automationCreateFromBlueprintCmd.Flags().StringVarP(&automationFromBlueprintFile, "file", "f", "", "Path to inputs file")
 	automationCreateFromBlueprintCmd.Flags().StringVar(&automationFromBlueprintFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(automationCreateFromBlueprintCmd)
 }
 
 func runAutomationCreateFromBlueprint(cmd *cobra.Command, args []string) error {
 	automationID := args[0]
 	blueprintPath := args[1]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	inputs, err := input.ParseInput(automationFromBlueprintData, automationFromBlueprintFile, automationFromBlueprintFormat)
@@ -69,8 +68,7 @@ func runAutomationCreateFromBlueprint(cmd *cobra.Command, args []string) error {
 		delete(inputs, "description")
 	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -30,7 +29,6 @@ func init() {
 }
 
 func runAutomationList(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 	extended, _ := cmd.Flags().GetBool("extended")
 	blueprintFilter, _ := cmd.Flags().GetString("blueprint")
@@ -43,14 +41,8 @@ func runAutomationList(cmd *cobra.Command, args []string) error {
 		extended = true
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -61,9 +53,9 @@ func runAutomationList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get REST client for extended info
-	var restClient *client.RestClient
+	var restClient client.RestAPI
 	if extended {
-		restClient, err = manager.GetRestClient()
+		restClient, err = getRestClient(cmd)
 		if err != nil {
 			return err
 		}
@@ -132,10 +124,10 @@ func runAutomationList(cmd *cobra.Command, args []string) error {
 
 	// Handle count mode
 	if listCount {
-		if textMode {
+		if customTextOutput(textMode) {
 			fmt.Printf("Count: %d\n", len(result))
 		} else {
-			client.PrintOutput(map[string]interface{}{"count": len(result)}, false, "")
+			client.PrintOutput(map[string]interface{}{"count": len(result)}, textMode, "")
 		}
 		return nil
 	}
@@ -147,7 +139,7 @@ func runAutomationList(cmd *cobra.Command, args []string) error {
 
 	// Handle brief mode
 	if listBrief {
-		if textMode {
+		if customTextOutput(textMode) {
 			for _, item := range result {
 				alias, _ := item["alias"].(string)
 				entityID, _ := item["entity_id"].(string)
@@ -161,13 +153,13 @@ func runAutomationList(cmd *cobra.Command, args []string) error {
 					"alias":     item["alias"],
 				})
 			}
-			client.PrintOutput(brief, false, "")
+			client.PrintOutput(brief, textMode, "")
 		}
 		return nil
 	}
 
 	// Full output
-	if textMode {
+	if customTextOutput(textMode) {
 		if len(result) == 0 {
 			fmt.Println("No automations.")
 			return nil
@@ -192,7 +184,7 @@ func runAutomationList(cmd *cobra.Command, args []string) error {
 			}
 		}
 	} else {
-		client.PrintOutput(result, false, "")
+		client.PrintOutput(result, textMode, "")
 	}
 	return nil
 }
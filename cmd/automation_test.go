@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/home-assistant/hab/client/clienttest"
+)
+
+func automationConfig(t *testing.T, srv *clienttest.Server, id string) map[string]interface{} {
+	t.Helper()
+	var config map[string]interface{}
+	srv.Update(func(d *clienttest.Data) {
+		config = d.Automations[id]
+	})
+	return config
+}
+
+func TestAutomationCreate(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	out, err := runCommand(t, "automation", "create", "morning_lights", "-d", `
+alias: Morning lights
+triggers:
+  - trigger: time
+    at: "07:00:00"
+actions:
+  - action: light.turn_on
+    target:
+      entity_id: light.kitchen
+`)
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if !strings.Contains(out, "Automation morning_lights created successfully.") {
+		t.Errorf("output = %q", out)
+	}
+
+	want := map[string]interface{}{
+		"alias":    "Morning lights",
+		"triggers": []interface{}{map[string]interface{}{"trigger": "time", "at": "07:00:00"}},
+		"actions": []interface{}{map[string]interface{}{
+			"action": "light.turn_on",
+			"target": map[string]interface{}{"entity_id": "light.kitchen"},
+		}},
+	}
+	if got := automationConfig(t, srv, "morning_lights"); !reflect.DeepEqual(got, want) {
+		t.Errorf("saved config = %v, want %v", got, want)
+	}
+}
+
+func TestAutomationCreateRejectsInvalidConfig(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	_, err := runCommand(t, "automation", "create", "broken", "-d", `{"alias": "Broken", "tirggers": []}`)
+	if err == nil || !strings.Contains(err.Error(), "tirggers: unknown key") {
+		t.Fatalf("err = %v, want a validation error", err)
+	}
+	if config := automationConfig(t, srv, "broken"); config != nil {
+		t.Errorf("invalid config was saved: %v", config)
+	}
+}
+
+func TestAutomationUpdate(t *testing.T) {
+	data := clienttest.NewData()
+	data.Automations["morning_lights"] = map[string]interface{}{
+		"alias":    "Morning lights",
+		"triggers": []interface{}{map[string]interface{}{"trigger": "time", "at": "07:00:00"}},
+		"actions":  []interface{}{map[string]interface{}{"action": "light.turn_on"}},
+	}
+	srv := newTestServer(t, data)
+
+	out, err := runCommand(t, "--json", "automation", "update", "morning_lights", "-d",
+		`{"alias": "Early lights", "triggers": [{"trigger": "time", "at": "06:00:00"}], "actions": []}`)
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if !strings.Contains(out, `"success": true`) {
+		t.Errorf("output = %q", out)
+	}
+
+	want := map[string]interface{}{
+		"alias":    "Early lights",
+		"triggers": []interface{}{map[string]interface{}{"trigger": "time", "at": "06:00:00"}},
+		"actions":  []interface{}{},
+	}
+	if got := automationConfig(t, srv, "morning_lights"); !reflect.DeepEqual(got, want) {
+		t.Errorf("saved config = %v, want %v", got, want)
+	}
+}
+
+func TestAutomationUpdateAcceptsEntityID(t *testing.T) {
+	data := clienttest.NewData()
+	data.Automations["morning_lights"] = map[string]interface{}{"alias": "Morning lights", "triggers": []interface{}{}, "actions": []interface{}{}}
+	srv := newTestServer(t, data)
+
+	// The config API is keyed by the automation's ID, without the entity domain
+	if _, err := runCommand(t, "automation", "update", "automation.morning_lights", "-d",
+		`{"alias": "Early lights", "triggers": [], "actions": []}`); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if got := automationConfig(t, srv, "morning_lights")["alias"]; got != "Early lights" {
+		t.Errorf("alias = %v, want Early lights", got)
+	}
+	if config := automationConfig(t, srv, "automation.morning_lights"); config != nil {
+		t.Errorf("update created a separate automation: %v", config)
+	}
+}
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -30,22 +30,25 @@ func init() {
 	automationTriggerCreateCmd.Flags().StringVarP(&automationTriggerCreateData, "data", "d", "", "Trigger configuration as JSON")
 	automationTriggerCreateCmd.Flags().StringVarP(&automationTriggerCreateFile, "file", "f", "", "Path to config file")
 	automationTriggerCreateCmd.Flags().StringVar(&automationTriggerCreateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(automationTriggerCreateCmd)
+	addValidateFlag(automationTriggerCreateCmd)
 }
 
 func runAutomationTriggerCreate(cmd *cobra.Command, args []string) error {
 	automationID := args[0]
 	automationID = strings.TrimPrefix(automationID, "automation.")
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	triggerConfig, err := input.ParseInput(automationTriggerCreateData, automationTriggerCreateFile, automationTriggerCreateFormat)
 	if err != nil {
 		return err
 	}
+	if err := validateInput(cmd, schema.Trigger, triggerConfig); err != nil {
+		return err
+	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
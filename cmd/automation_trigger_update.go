@@ -5,9 +5,9 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -31,6 +31,9 @@ func init() {
 	automationTriggerUpdateCmd.Flags().StringVarP(&automationTriggerUpdateData, "data", "d", "", "Trigger configuration as JSON (replaces entire trigger)")
 	automationTriggerUpdateCmd.Flags().StringVarP(&automationTriggerUpdateFile, "file", "f", "", "Path to config file")
 	automationTriggerUpdateCmd.Flags().StringVar(&automationTriggerUpdateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(automationTriggerUpdateCmd)
+	addValidateFlag(automationTriggerUpdateCmd)
+	addPatchFlags(automationTriggerUpdateCmd)
 }
 
 func runAutomationTriggerUpdate(cmd *cobra.Command, args []string) error {
@@ -41,16 +44,25 @@ func runAutomationTriggerUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid trigger index: %s", args[1])
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	newTrigger, err := input.ParseInput(automationTriggerUpdateData, automationTriggerUpdateFile, automationTriggerUpdateFormat)
+	patch, err := readConfigPatch(cmd, automationTriggerUpdateData, automationTriggerUpdateFile, automationTriggerUpdateFormat)
 	if err != nil {
 		return err
 	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	var newTrigger map[string]interface{}
+	if patch == nil {
+		newTrigger, err = input.ParseInput(automationTriggerUpdateData, automationTriggerUpdateFile, automationTriggerUpdateFormat)
+		if err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Trigger, newTrigger); err != nil {
+			return err
+		}
+	}
+
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -83,6 +95,19 @@ func runAutomationTriggerUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("trigger index %d out of range (0-%d)", triggerIndex, len(triggers)-1)
 	}
 
+	if patch != nil {
+		current, ok := triggers[triggerIndex].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("trigger at index %d is not an object", triggerIndex)
+		}
+		if newTrigger, err = patch(current); err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Trigger, newTrigger); err != nil {
+			return err
+		}
+	}
+
 	// Update the trigger
 	triggers[triggerIndex] = newTrigger
 	config[triggerKey] = triggers
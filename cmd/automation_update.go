@@ -1,11 +1,12 @@
 package cmd
 
 import (
+	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -17,9 +18,20 @@ var (
 )
 
 var automationUpdateCmd = &cobra.Command{
-	Use:     "update <automation_id>",
-	Short:   "Update an existing automation",
-	Long:    `Update an automation with new configuration.`,
+	Use:   "update <automation_id>",
+	Short: "Update an existing automation",
+	Long: `Update an automation with new configuration.
+
+With --patch the input is a JSON Patch (RFC 6902), with --merge a JSON merge
+patch (RFC 7396), applied to the current configuration. A "test" operation
+makes the update fail if the automation changed in the meantime.
+
+Examples:
+  hab automation update morning_lights --merge -d '{"mode": "restart"}'
+  hab automation update morning_lights --patch <<'EOF'
+  - {op: test, path: /alias, value: Morning lights}
+  - {op: replace, path: /triggers/0/at, value: "07:00:00"}
+  EOF`,
 	GroupID: automationGroupCommands,
 	Args:    cobra.ExactArgs(1),
 	RunE:    runAutomationUpdate,
@@ -30,28 +42,55 @@ func init() {
 	automationUpdateCmd.Flags().StringVarP(&automationUpdateData, "data", "d", "", "Updated configuration as JSON")
 	automationUpdateCmd.Flags().StringVarP(&automationUpdateFile, "file", "f", "", "Path to config file")
 	automationUpdateCmd.Flags().StringVar(&automationUpdateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(automationUpdateCmd)
+	addValidateFlag(automationUpdateCmd)
+	addPatchFlags(automationUpdateCmd)
 }
 
 func runAutomationUpdate(cmd *cobra.Command, args []string) error {
-	automationID := args[0]
-	if !strings.HasPrefix(automationID, "automation.") {
-		automationID = "automation." + automationID
-	}
+	// Strip "automation." prefix if provided - API expects just the ID
+	automationID := strings.TrimPrefix(args[0], "automation.")
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	config, err := input.ParseInput(automationUpdateData, automationUpdateFile, automationUpdateFormat)
+	patch, err := readConfigPatch(cmd, automationUpdateData, automationUpdateFile, automationUpdateFormat)
 	if err != nil {
 		return err
 	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	var config map[string]interface{}
+	if patch == nil {
+		config, err = input.ParseInput(automationUpdateData, automationUpdateFile, automationUpdateFormat)
+		if err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Automation, config); err != nil {
+			return err
+		}
+	}
+
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
 
+	if patch != nil {
+		current, err := restClient.Get("config/automation/config/" + automationID)
+		if err != nil {
+			return err
+		}
+		currentConfig, ok := current.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid automation config")
+		}
+		if config, err = patch(currentConfig); err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Automation, config); err != nil {
+			return err
+		}
+	}
+
 	result, err := restClient.Post("config/automation/config/"+automationID, config)
 	if err != nil {
 		return err
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -33,18 +32,11 @@ func runBlueprintGet(cmd *cobra.Command, args []string) error {
 	if path == "" {
 		return fmt.Errorf("blueprint path is required (use --path flag or positional argument)")
 	}
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 	domain, _ := cmd.Flags().GetString("domain")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/home-assistant/hab/client"
+	"github.com/home-assistant/hab/input"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// bulkInput holds --bulk and, for commands that otherwise take no input,
+// the --data, --file and --format flags the items are read from
+var bulkInput struct {
+	Bulk   bool
+	Data   string
+	File   string
+	Format string
+}
+
+// addBulkFlag adds --bulk to a command that reads items with its own input flags
+func addBulkFlag(cmd *cobra.Command, bulk *bool, what string) {
+	cmd.Flags().BoolVar(bulk, "bulk", false, fmt.Sprintf("Create several %s from a JSON array, NDJSON or multi-document YAML", what))
+}
+
+// addBulkFlags adds --bulk together with the input flags its items are read from
+// Shorthands already used by the command are left out.
+func addBulkFlags(cmd *cobra.Command, what string) {
+	shorthand := func(s string) string {
+		if cmd.Flags().ShorthandLookup(s) != nil {
+			return ""
+		}
+		return s
+	}
+	addBulkFlag(cmd, &bulkInput.Bulk, what)
+	cmd.Flags().StringVarP(&bulkInput.Data, "data", shorthand("d"), "", "Items for --bulk as JSON or YAML")
+	cmd.Flags().StringVarP(&bulkInput.File, "file", shorthand("f"), "", "Path to the items file for --bulk")
+	cmd.Flags().StringVar(&bulkInput.Format, "format", "", "Input format for --bulk (json, ndjson, yaml)")
+	addTemplateFlags(cmd)
+}
+
+// bulkArgs validates positional arguments with args, or accepts none with
+// --bulk, where each item carries what the arguments would give
+func bulkArgs(args cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, a []string) error {
+		if bulk, _ := cmd.Flags().GetBool("bulk"); bulk {
+			return cobra.NoArgs(cmd, a)
+		}
+		return args(cmd, a)
+	}
+}
+
+// bulkCreateFunc creates one item. It returns a label naming the item in
+// the report and the created object.
+type bulkCreateFunc func(item map[string]interface{}) (label string, result interface{}, err error)
+
+// runBulk creates each item with create and reports the outcome per item.
+// Items are created in order; a failed item does not stop the others. It
+// returns an error if any item failed.
+func runBulk(items []map[string]interface{}, textMode bool, create bulkCreateFunc) error {
+	report := make([]map[string]interface{}, 0, len(items))
+	failed := 0
+	for i, item := range items {
+		label, result, err := create(item)
+		if label == "" {
+			label = fmt.Sprintf("item %d", i+1)
+		}
+		entry := map[string]interface{}{
+			"index": i,
+			"item":  label,
+		}
+		if err != nil {
+			failed++
+			entry["status"] = "failed"
+			entry["error"] = err.Error()
+		} else {
+			entry["status"] = "created"
+			if textMode {
+				// Rows of a text table share the columns of the first
+				entry["error"] = ""
+			} else {
+				entry["result"] = result
+			}
+		}
+		report = append(report, entry)
+	}
+
+	msg := fmt.Sprintf("Created %d item(s).", len(items))
+	if failed > 0 {
+		msg = fmt.Sprintf("%d of %d item(s) failed.", failed, len(items))
+	}
+
+	switch {
+	case textMode:
+		client.PrintOutput(report, textMode, "")
+		// A failure is reported on stderr with the returned error
+		if failed == 0 {
+			fmt.Println(msg)
+		}
+	case failed == 0:
+		client.PrintSuccess(report, textMode, msg)
+	default:
+		fmt.Println(client.FormatError("BULK_FAILED", msg, map[string]interface{}{
+			"items": report,
+		}))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d item(s) failed", failed, len(items))
+	}
+	return nil
+}
+
+// bulkString returns the string field key of item, or an error naming it
+func bulkString(item map[string]interface{}, key string) (string, error) {
+	s, _ := item[key].(string)
+	if s == "" {
+		return "", fmt.Errorf("item has no '%s' field", key)
+	}
+	return s, nil
+}
+
+// bulkCreateNamed creates a registry item from its 'name' field and the
+// remaining fields as parameters
+func bulkCreateNamed(item map[string]interface{}, create func(name string, params map[string]interface{}) (map[string]interface{}, error)) (string, interface{}, error) {
+	name, err := bulkString(item, "name")
+	if err != nil {
+		return "", nil, err
+	}
+	params := make(map[string]interface{}, len(item))
+	for k, v := range item {
+		if k != "name" {
+			params[k] = v
+		}
+	}
+	result, err := create(name, params)
+	return name, result, err
+}
+
+// requireFlags returns cobra's error for required flags that were not set.
+// Commands with --bulk check this themselves, as the items carry the values.
+func requireFlags(cmd *cobra.Command, names ...string) error {
+	var missing []string
+	for _, name := range names {
+		if !cmd.Flags().Changed(name) {
+			missing = append(missing, fmt.Sprintf("%q", name))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("required flag(s) %s not set", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// runHelperBulkCreate creates helpers of one storage-based type from the
+// --bulk input. Each item holds the parameters of the type, with 'name'.
+func runHelperBulkCreate(cmd *cobra.Command, helperType string) error {
+	textMode := viper.GetBool("text")
+
+	items, err := input.ParseInputList(bulkInput.Data, bulkInput.File, bulkInput.Format)
+	if err != nil {
+		return err
+	}
+
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	return runBulk(items, textMode, func(item map[string]interface{}) (string, interface{}, error) {
+		name, err := bulkString(item, "name")
+		if err != nil {
+			return "", nil, err
+		}
+		result, err := ws.HelperCreate(helperType, item)
+		return name, result, err
+	})
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the registry cache",
+	Long: `Manage the on-disk cache of registry data.
+
+The cache is enabled with --cache-ttl (or HAB_CACHE_TTL / "cache-ttl" in the
+config file). It is invalidated automatically by hab commands that change
+Home Assistant; use --no-cache to bypass it for a single command.`,
+	GroupID: "other",
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/home-assistant/hab/cache"
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Clear the registry cache",
+	Long:  `Remove cached registry data for all Home Assistant instances.`,
+	Args:  cobra.NoArgs,
+	RunE:  runCacheClear,
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	textMode := viper.GetBool("text")
+	configDir := viper.GetString("config")
+
+	if err := cache.ClearAll(configDir); err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{
+		"cleared": true,
+		"path":    cache.GetCacheDir(configDir),
+	}
+	client.PrintSuccess(result, textMode, "Cache cleared.")
+	return nil
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/cache"
+	"github.com/home-assistant/hab/cassette"
+	"github.com/home-assistant/hab/client"
+	"github.com/home-assistant/hab/config"
+	"github.com/home-assistant/hab/daemon"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// newHAClient returns the client commands talk to Home Assistant through.
+// Tests replace it to point commands at a clienttest.Server.
+var newHAClient = func(cmd *cobra.Command) (client.HAClient, error) {
+	if activeCassette != nil && activeCassette.Replaying() {
+		// Replay answers from the cassette, no credentials needed
+		ha := client.NewInstance(cmd.Context(), activeCassette.URL(), "")
+		ha.Cassette = activeCassette
+		return ha, nil
+	}
+	if ha := daemonClient(cmd); ha != nil {
+		return ha, nil
+	}
+
+	manager := newAuthManager()
+	creds, err := manager.GetCredentials()
+	if err != nil {
+		return nil, err
+	}
+	ha := client.NewInstance(cmd.Context(), creds.URL, creds.AccessToken)
+	// Sessions such as watch outlive access tokens; keep them refreshed
+	ha.TokenSource = manager.Token
+	keepFreshOnce.Do(func() { manager.KeepFresh(cmd.Context()) })
+	ha.Cache = newCache(creds.URL)
+	ha.Retry = newRetryPolicy()
+	ha.Limiter = client.NewRateLimiter(viper.GetFloat64("rate-limit"))
+	ha.Transport = transportConfig
+	if activeCassette != nil {
+		ha.Cassette = activeCassette
+	}
+	return ha, nil
+}
+
+// keepFreshOnce starts the background token refresh once per process. Other
+// clients still refresh on demand through their TokenSource.
+var keepFreshOnce sync.Once
+
+// daemonClient returns an HAClient that forwards through a running hab
+// daemon, or nil when none is running, --no-daemon is set or HAB_URL
+// points at a different instance
+func daemonClient(cmd *cobra.Command) *client.Instance {
+	if viper.GetBool("no-daemon") {
+		return nil
+	}
+	socketPath := config.GetDaemonSocketPath(viper.GetString("config"), currentContext())
+	info, err := daemon.Probe(cmd.Context(), socketPath)
+	if err != nil {
+		log.WithError(err).Debug("No daemon, connecting directly")
+		return nil
+	}
+	if envURL := os.Getenv("HAB_URL"); envURL != "" && strings.TrimRight(envURL, "/") != info.URL {
+		log.WithField("daemon_url", info.URL).Debug("Daemon serves a different instance, connecting directly")
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"socket": socketPath,
+		"url":    info.URL,
+	}).Debug("Forwarding through daemon")
+	ha := client.NewInstance(cmd.Context(), daemon.BaseURL, "")
+	ha.Dial = daemon.Dialer(socketPath)
+	ha.Cache = newCache(info.URL)
+	ha.Retry = newRetryPolicy()
+	ha.Limiter = client.NewRateLimiter(viper.GetFloat64("rate-limit"))
+	if activeCassette != nil {
+		ha.Cassette = activeCassette
+	}
+	return ha
+}
+
+// newCache returns the registry cache for the instance, or nil when disabled
+func newCache(instanceURL string) client.Cache {
+	if ttl := viper.GetDuration("cache-ttl"); ttl > 0 && !viper.GetBool("no-cache") {
+		return cache.New(viper.GetString("config"), instanceURL, ttl)
+	}
+	return nil
+}
+
+// transportConfig holds the TLS and proxy settings, loaded before each command
+var transportConfig *client.TransportConfig
+
+// loadTransportConfig loads the TLS and proxy settings from flags, config and
+// environment, and the settings stored for the context, and applies them to
+// the OAuth token requests
+func loadTransportConfig(cmd *cobra.Command) error {
+	opts := client.TransportOptions{
+		CAFile:     viper.GetString("ca-file"),
+		ClientCert: viper.GetString("client-cert"),
+		ClientKey:  viper.GetString("client-key"),
+		Insecure:   viper.GetBool("insecure"),
+		Proxy:      viper.GetString("proxy"),
+	}
+	// The context commands manage the stored settings, so a broken one must not stop them
+	if cmd.Parent() != contextCmd {
+		stored, err := auth.GetContextTransport(viper.GetString("config"), currentContext())
+		if err != nil {
+			return err
+		}
+		applyContextTransport(cmd, &opts, stored)
+	}
+
+	cfg, err := opts.Load()
+	if err != nil {
+		return err
+	}
+	transportConfig = cfg
+	auth.HTTPClient = &http.Client{Transport: client.NewHTTPTransport(cfg)}
+	return nil
+}
+
+// applyContextTransport sets the options stored for the context in opts,
+// except those given by a flag or an environment variable. The client
+// certificate and key are taken together.
+func applyContextTransport(cmd *cobra.Command, opts *client.TransportOptions, stored auth.ContextTransport) {
+	if stored.CAFile != "" && !transportOverridden(cmd, "ca-file") {
+		opts.CAFile = stored.CAFile
+	}
+	if stored.ClientCert != "" && !transportOverridden(cmd, "client-cert") && !transportOverridden(cmd, "client-key") {
+		opts.ClientCert, opts.ClientKey = stored.ClientCert, stored.ClientKey
+	}
+	if stored.Insecure && !transportOverridden(cmd, "insecure") {
+		opts.Insecure = true
+	}
+	if stored.Proxy != "" && !transportOverridden(cmd, "proxy") {
+		opts.Proxy = stored.Proxy
+	}
+}
+
+// transportOverridden reports whether a setting was given by its flag or its
+// HAB_ environment variable, which take precedence over the context
+func transportOverridden(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Changed(name) {
+		return true
+	}
+	_, ok := os.LookupEnv("HAB_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")))
+	return ok
+}
+
+// activeCassette is the cassette set by --record or --replay, if any
+var activeCassette *cassette.Cassette
+
+// loadCassette opens the cassette for --replay, or starts the recording for
+// --record against the instance the credentials point at
+func loadCassette() error {
+	recordPath := viper.GetString("record")
+	replayPath := viper.GetString("replay")
+	switch {
+	case recordPath != "" && replayPath != "":
+		return fmt.Errorf("--record and --replay cannot be used together")
+	case replayPath != "":
+		c, err := cassette.Load(replayPath)
+		if err != nil {
+			return err
+		}
+		activeCassette = c
+	case recordPath != "":
+		activeCassette = cassette.NewRecorder(recordPath, newAuthManager().GetURL())
+	}
+	return nil
+}
+
+// saveCassette writes the --record cassette once the command finished
+func saveCassette() {
+	if activeCassette == nil {
+		return
+	}
+	if err := activeCassette.Save(); err != nil {
+		client.PrintWarning("Failed to save cassette: " + err.Error())
+	}
+}
+
+// newRetryPolicy returns the retry policy set by --retries
+func newRetryPolicy() *client.RetryPolicy {
+	return client.NewRetryPolicy(viper.GetInt("retries"))
+}
+
+// connectWebSocket returns an authenticated WebSocket client whose connection
+// is bound to the command context (Ctrl-C and --timeout close it).
+// Callers must Close the client.
+func connectWebSocket(cmd *cobra.Command) (client.WebSocketAPI, error) {
+	ha, err := newHAClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ha.WebSocket()
+}
+
+// getRestClient returns a REST client whose requests are bound to the command context
+func getRestClient(cmd *cobra.Command) (client.RestAPI, error) {
+	ha, err := newHAClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ha.REST()
+}
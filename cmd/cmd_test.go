@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/home-assistant/hab/client"
+	"github.com/home-assistant/hab/client/clienttest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// newTestServer starts a fake Home Assistant and points commands at it for
+// the rest of the test
+func newTestServer(t *testing.T, data *clienttest.Data) *clienttest.Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HAB_SKIP_UPDATE_CHECK", "1")
+
+	srv := clienttest.NewServer(data)
+	t.Cleanup(srv.Close)
+
+	orig := newHAClient
+	newHAClient = func(cmd *cobra.Command) (client.HAClient, error) {
+		return srv.Client(cmd.Context()), nil
+	}
+	t.Cleanup(func() { newHAClient = orig })
+	return srv
+}
+
+// commandRun is a hab command running in the background
+type commandRun struct {
+	cancel context.CancelFunc
+	done   chan error
+
+	mu  sync.Mutex
+	out bytes.Buffer
+}
+
+// stdoutMu serializes commands, which all print to os.Stdout
+var stdoutMu sync.Mutex
+
+// startCommand runs hab with args in the background, capturing its stdout.
+// The test fails if the command is still running when the test ends.
+func startCommand(t *testing.T, args ...string) *commandRun {
+	t.Helper()
+	stdoutMu.Lock()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		stdoutMu.Unlock()
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &commandRun{cancel: cancel, done: make(chan error, 1)}
+
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		buf := make([]byte, 4096)
+		for {
+			n, err := r.Read(buf)
+			run.mu.Lock()
+			run.out.Write(buf[:n])
+			run.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		rootCmd.SetArgs(args)
+		err := rootCmd.ExecuteContext(ctx)
+		cancelTimeout()
+
+		os.Stdout = stdout
+		w.Close()
+		<-copied
+		r.Close()
+		resetCommandState()
+		stdoutMu.Unlock()
+		run.done <- err
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		select {
+		case <-run.done:
+		case <-time.After(5 * time.Second):
+			t.Error("command did not stop")
+		}
+	})
+	return run
+}
+
+// runCommand runs hab with args and returns its stdout
+func runCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	run := startCommand(t, args...)
+	err := run.wait(t)
+	return run.output(), err
+}
+
+// String returns the output, for failure messages
+func (r *commandRun) String() string {
+	return r.output()
+}
+
+// output returns what the command printed so far
+func (r *commandRun) output() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.out.String()
+}
+
+// wait waits for the command to finish and returns its error
+func (r *commandRun) wait(t *testing.T) error {
+	t.Helper()
+	select {
+	case err := <-r.done:
+		r.done <- err
+		return err
+	case <-time.After(10 * time.Second):
+		t.Fatalf("command did not finish; output:\n%s", r.output())
+		return nil
+	}
+}
+
+// stop interrupts the command and returns its error
+func (r *commandRun) stop(t *testing.T) error {
+	t.Helper()
+	r.cancel()
+	return r.wait(t)
+}
+
+// waitForOutput waits until the output contains want
+func (r *commandRun) waitForOutput(t *testing.T, want string) {
+	t.Helper()
+	eventually(t, func() bool { return strings.Contains(r.output(), want) },
+		"output to contain %q; output:\n%s", want, r)
+}
+
+// eventually polls cond until it holds, failing the test after 10 seconds
+func eventually(t *testing.T, cond func() bool, format string, args ...interface{}) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for "+format, args...)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// resetCommandState restores the flags and settings a command run changed,
+// since cobra and viper keep them in package state
+func resetCommandState() {
+	var reset func(c *cobra.Command)
+	reset = func(c *cobra.Command) {
+		resetFlag := func(f *pflag.Flag) {
+			if !f.Changed {
+				return
+			}
+			if v, ok := f.Value.(pflag.SliceValue); ok {
+				v.Replace(nil)
+			} else {
+				f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		}
+		c.Flags().VisitAll(resetFlag)
+		c.PersistentFlags().VisitAll(resetFlag)
+		// Cobra only hands the execution context to commands without one
+		c.SetContext(nil)
+		for _, sub := range c.Commands() {
+			reset(sub)
+		}
+	}
+	reset(rootCmd)
+
+	// --json overrides text mode in viper rather than through the flag
+	viper.Set("text", true)
+	rootCmd.SetArgs(nil)
+}
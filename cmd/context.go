@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Manage Home Assistant instance contexts",
+	Long: `Manage named contexts, one per Home Assistant instance.
+
+Each context has its own credentials. Commands use the context selected by
+--context, then HAB_CONTEXT, then 'hab context use'. Without any of these the
+"default" context is used, which holds the credentials of 'hab auth login'.
+
+Examples:
+  hab context add staging
+  hab auth login --context staging
+  hab context use staging
+  hab entity list --context production`,
+	GroupID: "start",
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+}
+
+// activeContext is the context resolved by the root command before it runs
+var activeContext string
+
+// resolveContext checks and resolves the context selected by --context,
+// HAB_CONTEXT or 'hab context use'. Only 'hab auth login' may name a
+// context that does not exist yet, and 'hab context add', which creates one.
+func resolveContext(cmd *cobra.Command) error {
+	allowNew := cmd == authLoginCmd || cmd == contextAddCmd
+	name, err := auth.ResolveContext(viper.GetString("config"), viper.GetString("context"), allowNew)
+	if err != nil {
+		return err
+	}
+	activeContext = name
+	return nil
+}
+
+// currentContext returns the context selected by --context, HAB_CONTEXT or 'hab context use'
+func currentContext() string {
+	if activeContext == "" {
+		// Shell completion runs without the root command's checks
+		name, err := auth.ResolveContext(viper.GetString("config"), viper.GetString("context"), false)
+		if err != nil {
+			return config.DefaultContext
+		}
+		return name
+	}
+	return activeContext
+}
+
+// newCredentialStore returns the store selected by --credential-store
+func newCredentialStore() auth.CredentialStore {
+	return auth.NewCredentialStore(viper.GetString("config"), viper.GetString("credential-store"), credentialsPassphrase)
+}
+
+// newAuthManager returns an auth manager for the current context
+func newAuthManager() *auth.Manager {
+	manager := auth.NewContextManager(viper.GetString("config"), currentContext())
+	manager.Store = newCredentialStore()
+	return manager
+}
+
+// completeContextNames completes the names of existing contexts
+func completeContextNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	contexts, err := auth.ListContexts(viper.GetString("config"), newCredentialStore(), currentContext())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(contexts))
+	for _, c := range contexts {
+		names = append(names, c.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
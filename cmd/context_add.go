@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var contextAddUse bool
+
+var contextAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a context",
+	Long: `Add a named context. Log in to it with 'hab auth login --context <name>'.
+
+TLS and proxy flags given here (--ca-file, --client-cert, --client-key,
+--insecure, --proxy) are stored for the context; see 'hab context set'.
+
+Examples:
+  hab context add staging
+  hab context add staging --ca-file ~/pki/internal-ca.pem
+  hab context add production --use`,
+	Args: cobra.ExactArgs(1),
+	RunE: runContextAdd,
+}
+
+func init() {
+	contextCmd.AddCommand(contextAddCmd)
+	contextAddCmd.Flags().BoolVar(&contextAddUse, "use", false, "Make the new context the current one")
+}
+
+func runContextAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	textMode := viper.GetBool("text")
+	configDir := viper.GetString("config")
+
+	var transport auth.ContextTransport
+	hasTransport, err := readTransportFlags(cmd, &transport)
+	if err != nil {
+		return err
+	}
+	if err := auth.AddContext(configDir, name); err != nil {
+		return err
+	}
+	if hasTransport {
+		if err := auth.SetContextTransport(configDir, name, transport); err != nil {
+			return err
+		}
+	}
+	if contextAddUse {
+		if err := auth.UseContext(configDir, name); err != nil {
+			return err
+		}
+	}
+
+	result := map[string]interface{}{
+		"name":    name,
+		"current": contextAddUse,
+	}
+	client.PrintSuccess(result, textMode, fmt.Sprintf("Context %s added. Run 'hab auth login --context %s' to authenticate.", name, name))
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var contextDeleteForce bool
+
+var contextDeleteCmd = &cobra.Command{
+	Use:               "delete <name>",
+	Short:             "Delete a context",
+	Long:              `Delete a context and its stored credentials.`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runContextDelete,
+	ValidArgsFunction: completeContextNames,
+}
+
+func init() {
+	contextCmd.AddCommand(contextDeleteCmd)
+	contextDeleteCmd.Flags().BoolVarP(&contextDeleteForce, "force", "f", false, "Skip confirmation")
+}
+
+func runContextDelete(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	textMode := viper.GetBool("text")
+
+	if !contextDeleteForce && !textMode {
+		fmt.Printf("Delete context %s and its credentials? [y/N]: ", name)
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	if err := auth.DeleteContext(viper.GetString("config"), newCredentialStore(), name); err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{
+		"name":    name,
+		"deleted": true,
+	}
+	client.PrintSuccess(result, textMode, fmt.Sprintf("Context %s deleted.", name))
+	return nil
+}
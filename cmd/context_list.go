@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var contextListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List contexts",
+	Long:  `List the contexts, their instance URLs and which one is current.`,
+	Args:  cobra.NoArgs,
+	RunE:  runContextList,
+}
+
+func init() {
+	contextCmd.AddCommand(contextListCmd)
+}
+
+func runContextList(cmd *cobra.Command, args []string) error {
+	textMode := viper.GetBool("text")
+
+	contexts, err := auth.ListContexts(viper.GetString("config"), newCredentialStore(), currentContext())
+	if err != nil {
+		return err
+	}
+
+	result := make([]interface{}, 0, len(contexts))
+	for _, c := range contexts {
+		result = append(result, map[string]interface{}{
+			"name":          c.Name,
+			"url":           c.URL,
+			"current":       c.Current,
+			"authenticated": c.Authenticated,
+		})
+	}
+
+	client.PrintOutput(result, textMode, "")
+	return nil
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var contextRenameCmd = &cobra.Command{
+	Use:               "rename <old-name> <new-name>",
+	Short:             "Rename a context",
+	Long:              `Rename a context, keeping its credentials.`,
+	Args:              cobra.ExactArgs(2),
+	RunE:              runContextRename,
+	ValidArgsFunction: completeContextNames,
+}
+
+func init() {
+	contextCmd.AddCommand(contextRenameCmd)
+}
+
+func runContextRename(cmd *cobra.Command, args []string) error {
+	oldName, newName := args[0], args[1]
+	textMode := viper.GetBool("text")
+
+	if err := auth.RenameContext(viper.GetString("config"), newCredentialStore(), oldName, newName); err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{
+		"old_name": oldName,
+		"name":     newName,
+	}
+	client.PrintSuccess(result, textMode, fmt.Sprintf("Context %s renamed to %s.", oldName, newName))
+	return nil
+}
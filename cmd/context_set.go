@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var contextSetUnset []string
+
+var contextSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Store TLS and proxy settings for a context",
+	Long: `Store the TLS and proxy settings given with --ca-file, --client-cert,
+--client-key, --insecure and --proxy for a context. They then apply to every
+command using the context; flags and HAB_ environment variables still
+override them. Use --unset to remove a setting.
+
+Examples:
+  hab context set staging --ca-file ~/pki/internal-ca.pem
+  hab context set staging --client-cert ~/pki/hab.crt --client-key ~/pki/hab.key
+  hab context set staging --unset ca-file --unset proxy`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runContextSet,
+	ValidArgsFunction: completeContextNames,
+}
+
+func init() {
+	contextCmd.AddCommand(contextSetCmd)
+	contextSetCmd.Flags().StringSliceVar(&contextSetUnset, "unset", nil, "Remove a setting (ca-file, client-cert, client-key, insecure, proxy)")
+	contextSetCmd.RegisterFlagCompletionFunc("unset", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return transportSettings, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+func runContextSet(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	textMode := viper.GetBool("text")
+	configDir := viper.GetString("config")
+
+	transport, err := auth.GetContextTransport(configDir, name)
+	if err != nil {
+		return err
+	}
+	changed, err := readTransportFlags(cmd, &transport)
+	if err != nil {
+		return err
+	}
+	for _, key := range contextSetUnset {
+		switch key {
+		case "ca-file":
+			transport.CAFile = ""
+		case "client-cert":
+			transport.ClientCert = ""
+		case "client-key":
+			transport.ClientKey = ""
+		case "insecure":
+			transport.Insecure = false
+		case "proxy":
+			transport.Proxy = ""
+		default:
+			return fmt.Errorf("unknown setting %q (use ca-file, client-cert, client-key, insecure or proxy)", key)
+		}
+	}
+	if !changed && len(contextSetUnset) == 0 {
+		return fmt.Errorf("no settings provided (use --ca-file, --client-cert, --client-key, --insecure, --proxy or --unset)")
+	}
+
+	if err := auth.SetContextTransport(configDir, name, transport); err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{
+		"name":        name,
+		"ca_file":     transport.CAFile,
+		"client_cert": transport.ClientCert,
+		"client_key":  transport.ClientKey,
+		"insecure":    transport.Insecure,
+		"proxy":       transport.Proxy,
+	}
+	client.PrintSuccess(result, textMode, fmt.Sprintf("Settings of context %s updated.", name))
+	return nil
+}
+
+// transportSettings are the TLS and proxy settings a context can store
+var transportSettings = []string{"ca-file", "client-cert", "client-key", "insecure", "proxy"}
+
+// readTransportFlags copies the TLS and proxy flags given on the command line
+// into transport and reports whether there were any. File paths are made
+// absolute, since the context is used from other directories.
+func readTransportFlags(cmd *cobra.Command, transport *auth.ContextTransport) (bool, error) {
+	flags := cmd.Flags()
+	abs := func(name string) (string, error) {
+		path, _ := flags.GetString(name)
+		if path == "" {
+			return "", nil
+		}
+		return filepath.Abs(path)
+	}
+
+	var err error
+	changed := false
+	for _, name := range transportSettings {
+		if !flags.Changed(name) {
+			continue
+		}
+		changed = true
+		switch name {
+		case "ca-file":
+			transport.CAFile, err = abs(name)
+		case "client-cert":
+			transport.ClientCert, err = abs(name)
+		case "client-key":
+			transport.ClientKey, err = abs(name)
+		case "insecure":
+			transport.Insecure, err = flags.GetBool(name)
+		case "proxy":
+			transport.Proxy, err = flags.GetString(name)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/home-assistant/hab/auth"
+	"github.com/spf13/viper"
+)
+
+// proxyFor returns the proxy the loaded transport settings use for url
+func proxyFor(t *testing.T, url string) string {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	proxy, err := transportConfig.Proxy(req)
+	if err != nil || proxy == nil {
+		return ""
+	}
+	return proxy.String()
+}
+
+func TestContextTransportSettings(t *testing.T) {
+	newTestServer(t, nil)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	tlsServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsServer.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runCommand(t, "context", "add", "staging", "--ca-file", caFile); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if _, err := runCommand(t, "context", "set", "staging", "--proxy", "http://proxy.staging:3128"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	stored, err := auth.GetContextTransport(viper.GetString("config"), "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := auth.ContextTransport{CAFile: caFile, Proxy: "http://proxy.staging:3128"}
+	if stored != want {
+		t.Errorf("stored = %+v, want %+v", stored, want)
+	}
+
+	// The stored settings apply to commands using the context
+	if _, err := runCommand(t, "entity", "list", "--context", "staging"); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if transportConfig.TLS == nil || transportConfig.TLS.RootCAs == nil {
+		t.Error("the context's CA file was not loaded")
+	}
+	if got := proxyFor(t, "https://ha.staging"); got != "http://proxy.staging:3128" {
+		t.Errorf("proxy = %q, want the context's proxy", got)
+	}
+
+	// Other contexts do not use them
+	if _, err := runCommand(t, "entity", "list"); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if transportConfig.TLS != nil {
+		t.Error("the default context uses the staging CA file")
+	}
+
+	// Flags and environment variables override them
+	if _, err := runCommand(t, "entity", "list", "--context", "staging", "--proxy", "http://flag:1"); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if got := proxyFor(t, "https://ha.staging"); got != "http://flag:1" {
+		t.Errorf("proxy = %q, want the flag", got)
+	}
+	t.Setenv("HAB_PROXY", "http://env:2")
+	if _, err := runCommand(t, "entity", "list", "--context", "staging"); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if got := proxyFor(t, "https://ha.staging"); got != "http://env:2" {
+		t.Errorf("proxy = %q, want the environment variable", got)
+	}
+
+	if _, err := runCommand(t, "context", "set", "staging", "--unset", "ca-file,proxy"); err != nil {
+		t.Fatalf("unset failed: %v", err)
+	}
+	if stored, _ := auth.GetContextTransport(viper.GetString("config"), "staging"); stored != (auth.ContextTransport{}) {
+		t.Errorf("stored = %+v after --unset", stored)
+	}
+}
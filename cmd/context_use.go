@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var contextUseCmd = &cobra.Command{
+	Use:               "use <name>",
+	Short:             "Switch the current context",
+	Long:              `Make a context the current one for later commands.`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runContextUse,
+	ValidArgsFunction: completeContextNames,
+}
+
+func init() {
+	contextCmd.AddCommand(contextUseCmd)
+}
+
+func runContextUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	textMode := viper.GetBool("text")
+
+	if err := auth.UseContext(viper.GetString("config"), name); err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{
+		"name":    name,
+		"current": true,
+	}
+	client.PrintSuccess(result, textMode, fmt.Sprintf("Switched to context %s.", name))
+	return nil
+}
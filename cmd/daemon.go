@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/home-assistant/hab/client"
+	"github.com/home-assistant/hab/config"
+	"github.com/home-assistant/hab/daemon"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Keep a connection open for faster commands",
+	Long: `Run a connection daemon in the foreground until interrupted.
+
+The daemon authenticates once, keeps one WebSocket connection to Home
+Assistant open and listens on a Unix socket in the config directory. Other
+hab invocations using the same config directory detect it and forward their
+commands through it instead of connecting themselves. Without a running
+daemon they connect directly. Use --no-daemon to bypass it.
+
+Examples:
+  hab daemon &
+  hab daemon status
+  hab daemon stop`,
+	GroupID: "other",
+	Args:    cobra.NoArgs,
+	RunE:    runDaemon,
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	textMode := viper.GetBool("text")
+	configDir := viper.GetString("config")
+
+	manager := newAuthManager()
+	creds, err := manager.GetCredentials()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(config.GetContextDir(configDir, currentContext()), 0700); err != nil {
+		return err
+	}
+
+	// The manager refreshes OAuth tokens before they expire
+	manager.KeepFresh(cmd.Context())
+
+	socketPath := config.GetDaemonSocketPath(configDir, currentContext())
+	srv := daemon.NewServer(socketPath, creds.URL, manager.Token)
+	srv.Version = Version
+	srv.Retry = newRetryPolicy()
+	srv.Transport = transportConfig
+	srv.OnReady = func() {
+		fmt.Fprintf(os.Stderr, "hab daemon listening on %s (%s)\n", socketPath, creds.URL)
+	}
+
+	if err := srv.Run(cmd.Context()); err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{
+		"stopped": true,
+		"socket":  socketPath,
+	}
+	client.PrintSuccess(result, textMode, "Daemon stopped.")
+	return nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/home-assistant/hab/client"
+	"github.com/home-assistant/hab/config"
+	"github.com/home-assistant/hab/daemon"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether a daemon is running",
+	Long:  `Show whether a connection daemon is running for the config directory.`,
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonStatus,
+}
+
+func init() {
+	daemonCmd.AddCommand(daemonStatusCmd)
+}
+
+func runDaemonStatus(cmd *cobra.Command, args []string) error {
+	textMode := viper.GetBool("text")
+	socketPath := config.GetDaemonSocketPath(viper.GetString("config"), currentContext())
+
+	info, err := daemon.Probe(cmd.Context(), socketPath)
+	if err != nil {
+		result := map[string]interface{}{
+			"running": false,
+			"socket":  socketPath,
+		}
+		client.PrintOutput(result, textMode, "")
+		return nil
+	}
+
+	result := map[string]interface{}{
+		"running":    true,
+		"socket":     socketPath,
+		"url":        info.URL,
+		"pid":        info.PID,
+		"version":    info.Version,
+		"started_at": info.StartedAt,
+	}
+	client.PrintOutput(result, textMode, "")
+	return nil
+}
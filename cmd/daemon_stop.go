@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/home-assistant/hab/client"
+	"github.com/home-assistant/hab/config"
+	"github.com/home-assistant/hab/daemon"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running daemon",
+	Long:  `Ask the connection daemon for the config directory to shut down.`,
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonStop,
+}
+
+func init() {
+	daemonCmd.AddCommand(daemonStopCmd)
+}
+
+func runDaemonStop(cmd *cobra.Command, args []string) error {
+	textMode := viper.GetBool("text")
+	socketPath := config.GetDaemonSocketPath(viper.GetString("config"), currentContext())
+
+	if _, err := daemon.Probe(cmd.Context(), socketPath); err != nil {
+		return fmt.Errorf("no daemon running")
+	}
+	if err := daemon.Stop(cmd.Context(), socketPath); err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{
+		"stopped": true,
+		"socket":  socketPath,
+	}
+	client.PrintSuccess(result, textMode, "Daemon stopped.")
+	return nil
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
 	"github.com/spf13/cobra"
@@ -17,14 +16,17 @@ var (
 	badgeCreateFormat string
 	badgeCreateEntity string
 	badgeCreateType   string
+	badgeCreateBulk   bool
 )
 
 var badgeCreateCmd = &cobra.Command{
 	Use:   "create <dashboard_url_path> <view_index>",
 	Short: "Create a new badge",
-	Long:  `Create a new badge in a dashboard view.`,
-	Args:  cobra.ExactArgs(2),
-	RunE:  runBadgeCreate,
+	Long: `Create a new badge in a dashboard view.
+
+With --bulk, the input is a list of badges, all added to the view.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runBadgeCreate,
 }
 
 func init() {
@@ -32,8 +34,10 @@ func init() {
 	badgeCreateCmd.Flags().StringVarP(&badgeCreateData, "data", "d", "", "Badge configuration as JSON")
 	badgeCreateCmd.Flags().StringVarP(&badgeCreateFile, "file", "f", "", "Path to config file")
 	badgeCreateCmd.Flags().StringVar(&badgeCreateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(badgeCreateCmd)
 	badgeCreateCmd.Flags().StringVar(&badgeCreateEntity, "entity", "", "Entity ID for simple badge")
 	badgeCreateCmd.Flags().StringVar(&badgeCreateType, "type", "", "Badge type (e.g., entity)")
+	addBulkFlag(badgeCreateCmd, &badgeCreateBulk, "badges")
 }
 
 func runBadgeCreate(cmd *cobra.Command, args []string) error {
@@ -43,9 +47,31 @@ func runBadgeCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid view index: %s", args[1])
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
+	if badgeCreateBulk {
+		items, err := input.ParseInputList(badgeCreateData, badgeCreateFile, badgeCreateFormat)
+		if err != nil {
+			return err
+		}
+		ws, err := connectWebSocket(cmd)
+		if err != nil {
+			return err
+		}
+		defer ws.Close()
+		return runBulk(items, textMode, func(badgeConfig map[string]interface{}) (string, interface{}, error) {
+			label := "badge"
+			if entity, ok := badgeConfig["entity"].(string); ok {
+				label = fmt.Sprintf("badge (%s)", entity)
+			}
+			index, err := addBadge(ws, urlPath, viewIndex, badgeConfig)
+			if err != nil {
+				return label, nil, err
+			}
+			return label, map[string]interface{}{"index": index, "config": badgeConfig}, nil
+		})
+	}
+
 	var badgeConfig interface{}
 
 	// If data or file provided, parse it
@@ -69,18 +95,27 @@ func runBadgeCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("badge configuration required (use --data, --file, or --entity)")
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
+	defer ws.Close()
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	index, err := addBadge(ws, urlPath, viewIndex, badgeConfig)
+	if err != nil {
 		return err
 	}
-	defer ws.Close()
 
+	resultData := map[string]interface{}{
+		"index":  index,
+		"config": badgeConfig,
+	}
+	client.PrintSuccess(resultData, textMode, fmt.Sprintf("Badge created at index %d.", index))
+	return nil
+}
+
+// addBadge appends badgeConfig to the badges of a dashboard view and returns its index
+func addBadge(ws client.WebSocketAPI, urlPath string, viewIndex int, badgeConfig interface{}) (int, error) {
 	// Get current dashboard config
 	params := map[string]interface{}{}
 	if urlPath != "lovelace" {
@@ -89,26 +124,26 @@ func runBadgeCreate(cmd *cobra.Command, args []string) error {
 
 	result, err := ws.SendCommand("lovelace/config", params)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	config, ok := result.(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("invalid dashboard config")
+		return 0, fmt.Errorf("invalid dashboard config")
 	}
 
 	views, ok := config["views"].([]interface{})
 	if !ok {
-		return fmt.Errorf("no views in dashboard")
+		return 0, fmt.Errorf("no views in dashboard")
 	}
 
 	if viewIndex < 0 || viewIndex >= len(views) {
-		return fmt.Errorf("view index %d out of range (0-%d)", viewIndex, len(views)-1)
+		return 0, fmt.Errorf("view index %d out of range (0-%d)", viewIndex, len(views)-1)
 	}
 
 	view, ok := views[viewIndex].(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("invalid view at index %d", viewIndex)
+		return 0, fmt.Errorf("invalid view at index %d", viewIndex)
 	}
 
 	badges, ok := view["badges"].([]interface{})
@@ -132,13 +167,8 @@ func runBadgeCreate(cmd *cobra.Command, args []string) error {
 
 	_, err = ws.SendCommand("lovelace/config/save", saveParams)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	resultData := map[string]interface{}{
-		"index":  len(badges) - 1,
-		"config": badgeConfig,
-	}
-	client.PrintSuccess(resultData, textMode, fmt.Sprintf("Badge created at index %d.", len(badges)-1))
-	return nil
+	return len(badges) - 1, nil
 }
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
 	"github.com/spf13/cobra"
@@ -31,6 +30,8 @@ func init() {
 	badgeUpdateCmd.Flags().StringVarP(&badgeUpdateData, "data", "d", "", "Badge configuration as JSON (replaces entire badge)")
 	badgeUpdateCmd.Flags().StringVarP(&badgeUpdateFile, "file", "f", "", "Path to config file")
 	badgeUpdateCmd.Flags().StringVar(&badgeUpdateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(badgeUpdateCmd)
+	addPatchFlags(badgeUpdateCmd)
 	badgeUpdateCmd.Flags().StringVar(&badgeUpdateEntity, "entity", "", "Entity ID for simple badge")
 }
 
@@ -45,17 +46,15 @@ func runBadgeUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid badge index: %s", args[2])
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	patch, err := readConfigPatch(cmd, badgeUpdateData, badgeUpdateFile, badgeUpdateFormat)
+	if err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -101,8 +100,21 @@ func runBadgeUpdate(cmd *cobra.Command, args []string) error {
 
 	var newBadge interface{}
 
-	// If data or file provided, replace entirely
-	if badgeUpdateData != "" || badgeUpdateFile != "" {
+	// With --patch or --merge, apply the input to the current badge.
+	// Otherwise, if data or file provided, replace entirely.
+	if patch != nil {
+		current, ok := badges[badgeIndex].(map[string]interface{})
+		if entity, isEntity := badges[badgeIndex].(string); isEntity {
+			// A plain entity ID is the short form of an entity badge
+			current, ok = map[string]interface{}{"entity": entity}, true
+		}
+		if !ok {
+			return fmt.Errorf("badge at index %d is not an object", badgeIndex)
+		}
+		if newBadge, err = patch(current); err != nil {
+			return err
+		}
+	} else if badgeUpdateData != "" || badgeUpdateFile != "" {
 		parsed, err := input.ParseInput(badgeUpdateData, badgeUpdateFile, badgeUpdateFormat)
 		if err != nil {
 			return err
@@ -5,9 +5,9 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -20,6 +20,7 @@ var (
 	cardCreateEntity  string
 	cardCreateName    string
 	cardCreateSection int
+	cardCreateBulk    bool
 )
 
 var cardCreateCmd = &cobra.Command{
@@ -29,7 +30,10 @@ var cardCreateCmd = &cobra.Command{
 
 If view_index is not specified, uses the last view. If no views exist, creates one.
 If section is not specified, uses the last section. If no sections exist, creates one.
-If type is not specified, defaults to "tile".`,
+If type is not specified, defaults to "tile".
+
+With --bulk, the input is a list of cards, all added to the same view and
+section. --type, --entity and --name do not apply.`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runCardCreate,
 }
@@ -39,10 +43,13 @@ func init() {
 	cardCreateCmd.Flags().StringVarP(&cardCreateData, "data", "d", "", "Card configuration as JSON")
 	cardCreateCmd.Flags().StringVarP(&cardCreateFile, "file", "f", "", "Path to config file")
 	cardCreateCmd.Flags().StringVar(&cardCreateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(cardCreateCmd)
+	addValidateFlag(cardCreateCmd)
 	cardCreateCmd.Flags().StringVar(&cardCreateType, "type", "", "Card type (e.g., entities, button, markdown)")
 	cardCreateCmd.Flags().StringVar(&cardCreateEntity, "entity", "", "Entity ID (for simple entity cards)")
 	cardCreateCmd.Flags().StringVar(&cardCreateName, "name", "", "Card name/title")
 	cardCreateCmd.Flags().IntVarP(&cardCreateSection, "section", "s", -1, "Section index (if card should be in a section)")
+	addBulkFlag(cardCreateCmd, &cardCreateBulk, "cards")
 }
 
 func runCardCreate(cmd *cobra.Command, args []string) error {
@@ -58,9 +65,36 @@ func runCardCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
+	if cardCreateBulk {
+		items, err := input.ParseInputList(cardCreateData, cardCreateFile, cardCreateFormat)
+		if err != nil {
+			return err
+		}
+		ws, err := connectWebSocket(cmd)
+		if err != nil {
+			return err
+		}
+		defer ws.Close()
+		return runBulk(items, textMode, func(cardConfig map[string]interface{}) (string, interface{}, error) {
+			if _, ok := cardConfig["type"]; !ok {
+				cardConfig["type"] = "tile"
+			}
+			label := fmt.Sprintf("%v card", cardConfig["type"])
+			if entity, ok := cardConfig["entity"].(string); ok {
+				label = fmt.Sprintf("%s (%s)", label, entity)
+			}
+			if err := validateInput(cmd, schema.Card, cardConfig); err != nil {
+				return label, nil, err
+			}
+			if _, err := addCard(ws, urlPath, viewIndex, cardCreateSection, cardConfig); err != nil {
+				return label, nil, err
+			}
+			return label, cardConfig, nil
+		})
+	}
+
 	var cardConfig map[string]interface{}
 	var err error
 
@@ -89,19 +123,30 @@ func runCardCreate(cmd *cobra.Command, args []string) error {
 	if _, ok := cardConfig["type"]; !ok {
 		cardConfig["type"] = "tile"
 	}
-
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	if err := validateInput(cmd, schema.Card, cardConfig); err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
 
+	msg, err := addCard(ws, urlPath, viewIndex, cardCreateSection, cardConfig)
+	if err != nil {
+		return err
+	}
+
+	client.PrintSuccess(cardConfig, textMode, msg)
+	return nil
+}
+
+// addCard appends cardConfig to a section of a dashboard view, creating the
+// view and section when the dashboard has none. Negative indexes select the
+// last view or section. The card index is stored in cardConfig and a
+// description of the changes is returned.
+func addCard(ws client.WebSocketAPI, urlPath string, viewIndex, sectionIndex int, cardConfig map[string]interface{}) (string, error) {
 	// Get current dashboard config
 	params := map[string]interface{}{}
 	if urlPath != "lovelace" {
@@ -110,7 +155,7 @@ func runCardCreate(cmd *cobra.Command, args []string) error {
 
 	result, err := ws.SendCommand("lovelace/config", params)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	config, ok := result.(map[string]interface{})
@@ -142,12 +187,12 @@ func runCardCreate(cmd *cobra.Command, args []string) error {
 	}
 
 	if viewIndex >= len(views) {
-		return fmt.Errorf("view index %d out of range (0-%d)", viewIndex, len(views)-1)
+		return "", fmt.Errorf("view index %d out of range (0-%d)", viewIndex, len(views)-1)
 	}
 
 	view, ok := views[viewIndex].(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("invalid view at index %d", viewIndex)
+		return "", fmt.Errorf("invalid view at index %d", viewIndex)
 	}
 
 	// Get or create sections
@@ -168,19 +213,18 @@ func runCardCreate(cmd *cobra.Command, args []string) error {
 		sectionCreated = true
 	}
 
-	// Determine section index: use provided value or default to last section
-	sectionIndex := cardCreateSection
+	// Default to last section if not specified
 	if sectionIndex < 0 {
 		sectionIndex = len(sections) - 1
 	}
 
 	if sectionIndex >= len(sections) {
-		return fmt.Errorf("section index %d out of range (0-%d)", sectionIndex, len(sections)-1)
+		return "", fmt.Errorf("section index %d out of range (0-%d)", sectionIndex, len(sections)-1)
 	}
 
 	section, ok := sections[sectionIndex].(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("invalid section at index %d", sectionIndex)
+		return "", fmt.Errorf("invalid section at index %d", sectionIndex)
 	}
 
 	cards, _ := section["cards"].([]interface{})
@@ -206,7 +250,7 @@ func runCardCreate(cmd *cobra.Command, args []string) error {
 
 	_, err = ws.SendCommand("lovelace/config/save", saveParams)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	cardConfig["index"] = newCardIndex
@@ -236,6 +280,5 @@ func runCardCreate(cmd *cobra.Command, args []string) error {
 		msg = "Card created at index " + strconv.Itoa(newCardIndex) + " in view " + strconv.Itoa(viewIndex) + " section " + strconv.Itoa(sectionIndex) + "."
 	}
 
-	client.PrintSuccess(cardConfig, textMode, msg)
-	return nil
+	return msg, nil
 }
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -35,6 +35,9 @@ func init() {
 	cardUpdateCmd.Flags().StringVarP(&cardUpdateData, "data", "d", "", "Card configuration as JSON (replaces entire card)")
 	cardUpdateCmd.Flags().StringVarP(&cardUpdateFile, "file", "f", "", "Path to config file")
 	cardUpdateCmd.Flags().StringVar(&cardUpdateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(cardUpdateCmd)
+	addValidateFlag(cardUpdateCmd)
+	addPatchFlags(cardUpdateCmd)
 	cardUpdateCmd.Flags().StringVar(&cardUpdateType, "type", "", "Card type")
 	cardUpdateCmd.Flags().StringVar(&cardUpdateEntity, "entity", "", "Entity ID")
 	cardUpdateCmd.Flags().IntVarP(&cardUpdateSection, "section", "s", -1, "Section index (if card is in a section)")
@@ -51,17 +54,15 @@ func runCardUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid card index: %s", args[2])
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	patch, err := readConfigPatch(cmd, cardUpdateData, cardUpdateFile, cardUpdateFormat)
+	if err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -132,8 +133,13 @@ func runCardUpdate(cmd *cobra.Command, args []string) error {
 		existingCard = make(map[string]interface{})
 	}
 
-	// If data or file provided, replace the entire card config
-	if cardUpdateData != "" || cardUpdateFile != "" {
+	// With --patch or --merge, apply the input to the current config.
+	// Otherwise, if data or file provided, replace the entire card config.
+	if patch != nil {
+		if existingCard, err = patch(existingCard); err != nil {
+			return err
+		}
+	} else if cardUpdateData != "" || cardUpdateFile != "" {
 		newConfig, err := input.ParseInput(cardUpdateData, cardUpdateFile, cardUpdateFormat)
 		if err != nil {
 			return err
@@ -148,6 +154,9 @@ func runCardUpdate(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed("entity") {
 		existingCard["entity"] = cardUpdateEntity
 	}
+	if err := validateInput(cmd, schema.Card, existingCard); err != nil {
+		return err
+	}
 
 	cards[cardIndex] = existingCard
 	section["cards"] = cards
@@ -6,7 +6,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -30,7 +29,6 @@ func init() {
 
 func runDashboardDelete(cmd *cobra.Command, args []string) error {
 	dashboardID := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	if !dashboardDeleteForce && !textMode {
@@ -44,14 +42,8 @@ func runDashboardDelete(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
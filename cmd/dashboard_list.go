@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -32,17 +31,10 @@ func init() {
 }
 
 func runDashboardList(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -61,10 +53,10 @@ func runDashboardList(cmd *cobra.Command, args []string) error {
 
 	// Handle count mode
 	if dashboardListCount {
-		if textMode {
+		if customTextOutput(textMode) {
 			fmt.Printf("Count: %d\n", len(dashboards))
 		} else {
-			client.PrintOutput(map[string]interface{}{"count": len(dashboards)}, false, "")
+			client.PrintOutput(map[string]interface{}{"count": len(dashboards)}, textMode, "")
 		}
 		return nil
 	}
@@ -76,7 +68,7 @@ func runDashboardList(cmd *cobra.Command, args []string) error {
 
 	// Handle brief mode
 	if dashboardListBrief {
-		if textMode {
+		if customTextOutput(textMode) {
 			for _, d := range dashboards {
 				if dashboard, ok := d.(map[string]interface{}); ok {
 					title := getStr(dashboard, "title")
@@ -98,13 +90,13 @@ func runDashboardList(cmd *cobra.Command, args []string) error {
 					})
 				}
 			}
-			client.PrintOutput(brief, false, "")
+			client.PrintOutput(brief, textMode, "")
 		}
 		return nil
 	}
 
 	// Full output
-	if textMode {
+	if customTextOutput(textMode) {
 		if len(dashboards) == 0 {
 			fmt.Println("No dashboards.")
 			return nil
@@ -116,7 +108,7 @@ func runDashboardList(cmd *cobra.Command, args []string) error {
 			}
 		}
 	} else {
-		client.PrintOutput(dashboards, false, "")
+		client.PrintOutput(dashboards, textMode, "")
 	}
 	return nil
 }
@@ -3,9 +3,9 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -30,26 +30,24 @@ func init() {
 	dashboardSaveConfigCmd.Flags().StringVarP(&dashboardSaveConfigData, "data", "d", "", "Dashboard configuration as JSON")
 	dashboardSaveConfigCmd.Flags().StringVarP(&dashboardSaveConfigFile, "file", "f", "", "Path to config file")
 	dashboardSaveConfigCmd.Flags().StringVar(&dashboardSaveConfigFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(dashboardSaveConfigCmd)
+	addValidateFlag(dashboardSaveConfigCmd)
 }
 
 func runDashboardSaveConfig(cmd *cobra.Command, args []string) error {
 	urlPath := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	config, err := input.ParseInput(dashboardSaveConfigData, dashboardSaveConfigFile, dashboardSaveConfigFormat)
 	if err != nil {
 		return err
 	}
-
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	if err := validateInput(cmd, schema.Dashboard, config); err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -32,6 +32,8 @@ func init() {
 	sectionCreateCmd.Flags().StringVarP(&sectionCreateData, "data", "d", "", "Section configuration as JSON")
 	sectionCreateCmd.Flags().StringVarP(&sectionCreateFile, "file", "f", "", "Path to config file")
 	sectionCreateCmd.Flags().StringVar(&sectionCreateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(sectionCreateCmd)
+	addValidateFlag(sectionCreateCmd)
 	sectionCreateCmd.Flags().StringVar(&sectionCreateTitle, "title", "", "Section title")
 	sectionCreateCmd.Flags().StringVar(&sectionCreateType, "type", "", "Section type (e.g., grid)")
 }
@@ -43,7 +45,6 @@ func runSectionCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid view index: %s", args[1])
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	var sectionConfig map[string]interface{}
@@ -70,15 +71,12 @@ func runSectionCreate(cmd *cobra.Command, args []string) error {
 	if _, ok := sectionConfig["cards"]; !ok {
 		sectionConfig["cards"] = []interface{}{}
 	}
-
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	if err := validateInput(cmd, schema.Section, sectionConfig); err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
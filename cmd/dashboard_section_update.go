@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -32,6 +32,9 @@ func init() {
 	sectionUpdateCmd.Flags().StringVarP(&sectionUpdateData, "data", "d", "", "Section configuration as JSON (replaces entire section)")
 	sectionUpdateCmd.Flags().StringVarP(&sectionUpdateFile, "file", "f", "", "Path to config file")
 	sectionUpdateCmd.Flags().StringVar(&sectionUpdateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(sectionUpdateCmd)
+	addValidateFlag(sectionUpdateCmd)
+	addPatchFlags(sectionUpdateCmd)
 	sectionUpdateCmd.Flags().StringVar(&sectionUpdateTitle, "title", "", "Section title")
 	sectionUpdateCmd.Flags().StringVar(&sectionUpdateType, "type", "", "Section type")
 }
@@ -47,17 +50,15 @@ func runSectionUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid section index: %s", args[2])
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	patch, err := readConfigPatch(cmd, sectionUpdateData, sectionUpdateFile, sectionUpdateFormat)
+	if err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -107,8 +108,13 @@ func runSectionUpdate(cmd *cobra.Command, args []string) error {
 		existingSection = make(map[string]interface{})
 	}
 
-	// If data or file provided, replace the entire section config
-	if sectionUpdateData != "" || sectionUpdateFile != "" {
+	// With --patch or --merge, apply the input to the current config.
+	// Otherwise, if data or file provided, replace the entire section config.
+	if patch != nil {
+		if existingSection, err = patch(existingSection); err != nil {
+			return err
+		}
+	} else if sectionUpdateData != "" || sectionUpdateFile != "" {
 		newConfig, err := input.ParseInput(sectionUpdateData, sectionUpdateFile, sectionUpdateFormat)
 		if err != nil {
 			return err
@@ -123,6 +129,9 @@ func runSectionUpdate(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed("type") {
 		existingSection["type"] = sectionUpdateType
 	}
+	if err := validateInput(cmd, schema.Section, existingSection); err != nil {
+		return err
+	}
 
 	sections[sectionIndex] = existingSection
 	view["sections"] = sections
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -17,9 +16,12 @@ var (
 )
 
 var dashboardUpdateCmd = &cobra.Command{
-	Use:     "update <dashboard_id>",
-	Short:   "Update a dashboard",
-	Long:    `Update dashboard settings like title, icon, sidebar visibility, and admin requirement.`,
+	Use:   "update <dashboard_id>",
+	Short: "Update a dashboard",
+	Long: `Update dashboard settings like title, icon, sidebar visibility, and admin requirement.
+
+With --patch (JSON Patch, RFC 6902) or --merge (JSON merge patch, RFC 7396)
+the changes are given as a patch of the dashboard settings instead of flags.`,
 	GroupID: dashboardGroupCommands,
 	Args:    cobra.ExactArgs(1),
 	RunE:    runDashboardUpdate,
@@ -34,28 +36,19 @@ func init() {
 	var sidebar, admin bool
 	dashboardUpdateCmd.Flags().BoolVar(&sidebar, "sidebar", true, "Show in sidebar")
 	dashboardUpdateCmd.Flags().BoolVar(&admin, "require-admin", false, "Require admin access")
+	addPatchInputFlags(dashboardUpdateCmd)
 }
 
 func runDashboardUpdate(cmd *cobra.Command, args []string) error {
 	dashboardID := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	patch, err := readFlagsPatch(cmd)
+	if err != nil {
 		return err
 	}
-	defer ws.Close()
 
-	params := map[string]interface{}{
-		"dashboard_id": dashboardID,
-	}
+	params := map[string]interface{}{}
 
 	if cmd.Flags().Changed("title") {
 		params["title"] = dashboardUpdateTitle
@@ -71,6 +64,31 @@ func runDashboardUpdate(cmd *cobra.Command, args []string) error {
 		admin, _ := cmd.Flags().GetBool("require-admin")
 		params["require_admin"] = admin
 	}
+	if patch != nil && len(params) > 0 {
+		return checkUpdateParams(patch, params)
+	}
+
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	if patch != nil {
+		dashboards, err := ws.SendCommand("lovelace/dashboards/list", nil)
+		if err != nil {
+			return err
+		}
+		items, _ := dashboards.([]interface{})
+		current, err := findItem(items, "id", dashboardID, "dashboard")
+		if err != nil {
+			return err
+		}
+		if params, err = patchParams(current, patch, "id"); err != nil {
+			return err
+		}
+	}
+	params["dashboard_id"] = dashboardID
 
 	result, err := ws.SendCommand("lovelace/dashboards/update", params)
 	if err != nil {
@@ -3,9 +3,9 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -32,6 +32,8 @@ func init() {
 	viewCreateCmd.Flags().StringVarP(&viewCreateData, "data", "d", "", "View configuration as JSON")
 	viewCreateCmd.Flags().StringVarP(&viewCreateFile, "file", "f", "", "Path to config file")
 	viewCreateCmd.Flags().StringVar(&viewCreateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(viewCreateCmd)
+	addValidateFlag(viewCreateCmd)
 	viewCreateCmd.Flags().StringVar(&viewCreateTitle, "title", "", "View title")
 	viewCreateCmd.Flags().StringVar(&viewCreateIcon, "icon", "", "View icon (e.g., mdi:home)")
 	viewCreateCmd.Flags().StringVar(&viewCreatePath, "path", "", "View path (URL slug)")
@@ -39,7 +41,6 @@ func init() {
 
 func runViewCreate(cmd *cobra.Command, args []string) error {
 	urlPath := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	var viewConfig map[string]interface{}
@@ -70,15 +71,12 @@ func runViewCreate(cmd *cobra.Command, args []string) error {
 	if _, ok := viewConfig["title"]; !ok {
 		return fmt.Errorf("view title is required (use --title or provide in data)")
 	}
-
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	if err := validateInput(cmd, schema.View, viewConfig); err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -33,6 +33,9 @@ func init() {
 	viewUpdateCmd.Flags().StringVarP(&viewUpdateData, "data", "d", "", "View configuration as JSON (replaces entire view)")
 	viewUpdateCmd.Flags().StringVarP(&viewUpdateFile, "file", "f", "", "Path to config file")
 	viewUpdateCmd.Flags().StringVar(&viewUpdateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(viewUpdateCmd)
+	addValidateFlag(viewUpdateCmd)
+	addPatchFlags(viewUpdateCmd)
 	viewUpdateCmd.Flags().StringVar(&viewUpdateTitle, "title", "", "View title")
 	viewUpdateCmd.Flags().StringVar(&viewUpdateIcon, "icon", "", "View icon (e.g., mdi:home)")
 	viewUpdateCmd.Flags().StringVar(&viewUpdatePath, "path", "", "View path (URL slug)")
@@ -45,17 +48,15 @@ func runViewUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid view index: %s", args[1])
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	patch, err := readConfigPatch(cmd, viewUpdateData, viewUpdateFile, viewUpdateFormat)
+	if err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -91,8 +92,13 @@ func runViewUpdate(cmd *cobra.Command, args []string) error {
 		existingView = make(map[string]interface{})
 	}
 
-	// If data or file provided, replace the entire view config
-	if viewUpdateData != "" || viewUpdateFile != "" {
+	// With --patch or --merge, apply the input to the current config.
+	// Otherwise, if data or file provided, replace the entire view config.
+	if patch != nil {
+		if existingView, err = patch(existingView); err != nil {
+			return err
+		}
+	} else if viewUpdateData != "" || viewUpdateFile != "" {
 		newConfig, err := input.ParseInput(viewUpdateData, viewUpdateFile, viewUpdateFormat)
 		if err != nil {
 			return err
@@ -110,6 +116,9 @@ func runViewUpdate(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed("path") {
 		existingView["path"] = viewUpdatePath
 	}
+	if err := validateInput(cmd, schema.View, existingView); err != nil {
+		return err
+	}
 
 	views[viewIndex] = existingView
 	config["views"] = views
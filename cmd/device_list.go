@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -36,17 +35,10 @@ func init() {
 }
 
 func runDeviceList(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -118,10 +110,10 @@ func runDeviceList(cmd *cobra.Command, args []string) error {
 
 	// Handle count mode
 	if deviceListCount {
-		if textMode {
+		if customTextOutput(textMode) {
 			fmt.Printf("Count: %d\n", len(result))
 		} else {
-			client.PrintOutput(map[string]interface{}{"count": len(result)}, false, "")
+			client.PrintOutput(map[string]interface{}{"count": len(result)}, textMode, "")
 		}
 		return nil
 	}
@@ -133,7 +125,7 @@ func runDeviceList(cmd *cobra.Command, args []string) error {
 
 	// Handle brief mode
 	if deviceListBrief {
-		if textMode {
+		if customTextOutput(textMode) {
 			for _, item := range result {
 				name, _ := item["name"].(string)
 				id, _ := item["id"].(string)
@@ -147,13 +139,13 @@ func runDeviceList(cmd *cobra.Command, args []string) error {
 					"name": item["name"],
 				})
 			}
-			client.PrintOutput(brief, false, "")
+			client.PrintOutput(brief, textMode, "")
 		}
 		return nil
 	}
 
 	// Full output
-	if textMode {
+	if customTextOutput(textMode) {
 		if len(result) == 0 {
 			fmt.Println("No devices.")
 			return nil
@@ -180,7 +172,7 @@ func runDeviceList(cmd *cobra.Command, args []string) error {
 			}
 		}
 	} else {
-		client.PrintOutput(result, false, "")
+		client.PrintOutput(result, textMode, "")
 	}
 	return nil
 }
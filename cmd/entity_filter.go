@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+)
+
+// entityFilter holds the registry based filters shared by entity list and entity watch
+type entityFilter struct {
+	EntityID    string
+	Domain      string
+	Area        string
+	Floor       string
+	Label       string
+	Device      string
+	DeviceClass string
+}
+
+// addEntityFilterFlags registers the filter flags on cmd
+func addEntityFilterFlags(cmd *cobra.Command, f *entityFilter) {
+	cmd.Flags().StringVarP(&f.Domain, "domain", "d", "", "Filter by domain (e.g., light, switch)")
+	cmd.Flags().StringVarP(&f.Area, "area", "a", "", "Filter by area ID")
+	cmd.Flags().StringVarP(&f.Floor, "floor", "f", "", "Filter by floor ID (includes all areas on that floor)")
+	cmd.Flags().StringVarP(&f.Label, "label", "l", "", "Filter by label ID")
+	cmd.Flags().StringVar(&f.Device, "device", "", "Filter by device ID")
+	cmd.Flags().StringVar(&f.DeviceClass, "device-class", "", "Filter by device class (e.g., temperature, motion, door)")
+}
+
+// entityRegistryIndex is a lookup of registry data needed to filter entities
+type entityRegistryIndex struct {
+	entries     map[string]*client.EntityRegistryEntry // entity_id -> registry entry
+	deviceNames map[string]string                      // device_id -> name
+	areaFloors  map[string]string                      // area_id -> floor_id
+}
+
+// loadEntityRegistryIndex fetches the entity registry, device names and,
+// when the floor filter is used, the area-to-floor mapping
+func loadEntityRegistryIndex(ws client.WebSocketAPI, f entityFilter) (*entityRegistryIndex, error) {
+	registry, err := ws.ListEntityEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	index := &entityRegistryIndex{
+		entries:     make(map[string]*client.EntityRegistryEntry),
+		deviceNames: make(map[string]string),
+	}
+
+	for i := range registry {
+		index.entries[registry[i].EntityID] = &registry[i]
+	}
+
+	// Get device registry for device names
+	devices, err := ws.ListDevices()
+	if err == nil {
+		for _, device := range devices {
+			if name := device.DisplayName(); name != "" {
+				index.deviceNames[device.ID] = name
+			}
+		}
+	}
+
+	// Build area-to-floor map if floor filter is used
+	if f.Floor != "" {
+		index.areaFloors = make(map[string]string)
+		areas, err := ws.ListAreas()
+		if err == nil {
+			for _, area := range areas {
+				if area.AreaID != "" {
+					index.areaFloors[area.AreaID] = area.FloorID
+				}
+			}
+		}
+	}
+
+	return index, nil
+}
+
+// matches reports whether the entity passes every filter
+func (f entityFilter) matches(index *entityRegistryIndex, entityID string, attrs map[string]interface{}) bool {
+	parts := strings.SplitN(entityID, ".", 2)
+	entityDomain := ""
+	if len(parts) > 0 {
+		entityDomain = parts[0]
+	}
+
+	// Apply entity ID filter
+	if f.EntityID != "" && entityID != f.EntityID {
+		return false
+	}
+
+	// Apply domain filter
+	if f.Domain != "" && entityDomain != f.Domain {
+		return false
+	}
+
+	regEntry := index.entries[entityID]
+
+	// Apply device filter
+	if f.Device != "" && (regEntry == nil || regEntry.DeviceID != f.Device) {
+		return false
+	}
+
+	// Apply area filter
+	if f.Area != "" && (regEntry == nil || regEntry.AreaID != f.Area) {
+		return false
+	}
+
+	// Apply floor filter (check if entity's area is on the specified floor)
+	if f.Floor != "" {
+		if regEntry == nil || regEntry.AreaID == "" {
+			return false
+		}
+		if index.areaFloors[regEntry.AreaID] != f.Floor {
+			return false
+		}
+	}
+
+	// Apply label filter
+	if f.Label != "" && (regEntry == nil || !regEntry.HasLabel(f.Label)) {
+		return false
+	}
+
+	// Apply device class filter
+	if f.DeviceClass != "" && entityDeviceClass(regEntry, attrs) != f.DeviceClass {
+		return false
+	}
+
+	return true
+}
+
+// entityDeviceClass returns the device class from the registry entry
+// (original_device_class takes precedence), falling back to state attributes
+func entityDeviceClass(regEntry *client.EntityRegistryEntry, attrs map[string]interface{}) string {
+	if regEntry != nil {
+		if regEntry.OriginalDeviceClass != "" {
+			return regEntry.OriginalDeviceClass
+		} else if regEntry.DeviceClass != "" {
+			return regEntry.DeviceClass
+		}
+	}
+	if dc, ok := attrs["device_class"].(string); ok {
+		return dc
+	}
+	return ""
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -41,13 +40,10 @@ func runEntityGet(cmd *cobra.Command, args []string) error {
 	if entityID == "" {
 		return fmt.Errorf("entity ID is required (use --entity flag or positional argument)")
 	}
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-
 	// Get state from REST API
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -58,19 +54,12 @@ func runEntityGet(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get registry data and optionally related items via WebSocket
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		// Fall back to just state if we can't get WebSocket connection
 		client.PrintOutput(state, textMode, "")
 		return nil
 	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
-		// Fall back to just state
-		client.PrintOutput(state, textMode, "")
-		return nil
-	}
 	defer ws.Close()
 
 	// Get entity registry data
@@ -2,25 +2,17 @@ package cmd
 
 import (
 	"fmt"
-	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	entityListID          string
-	entityListDomain      string
-	entityListArea        string
-	entityListFloor       string
-	entityListLabel       string
-	entityListDevice      string
-	entityListDeviceClass string
-	entityListCount       bool
-	entityListBrief       bool
-	entityListLimit       int
+	entityListFilter entityFilter
+	entityListCount  bool
+	entityListBrief  bool
+	entityListLimit  int
 )
 
 var entityListCmd = &cobra.Command{
@@ -32,224 +24,57 @@ var entityListCmd = &cobra.Command{
 
 func init() {
 	entityCmd.AddCommand(entityListCmd)
-	entityListCmd.Flags().StringVar(&entityListID, "entity-id", "", "Filter by entity ID")
-	entityListCmd.Flags().StringVarP(&entityListDomain, "domain", "d", "", "Filter by domain (e.g., light, switch)")
-	entityListCmd.Flags().StringVarP(&entityListArea, "area", "a", "", "Filter by area ID")
-	entityListCmd.Flags().StringVarP(&entityListFloor, "floor", "f", "", "Filter by floor ID (includes all areas on that floor)")
-	entityListCmd.Flags().StringVarP(&entityListLabel, "label", "l", "", "Filter by label ID")
-	entityListCmd.Flags().StringVar(&entityListDevice, "device", "", "Filter by device ID")
-	entityListCmd.Flags().StringVar(&entityListDeviceClass, "device-class", "", "Filter by device class (e.g., temperature, motion, door)")
+	entityListCmd.Flags().StringVar(&entityListFilter.EntityID, "entity-id", "", "Filter by entity ID")
+	addEntityFilterFlags(entityListCmd, &entityListFilter)
 	entityListCmd.Flags().BoolVarP(&entityListCount, "count", "c", false, "Return only the count of items")
 	entityListCmd.Flags().BoolVarP(&entityListBrief, "brief", "b", false, "Return minimal fields (entity_id and name only)")
 	entityListCmd.Flags().IntVarP(&entityListLimit, "limit", "n", 0, "Limit results to N items")
 }
 
 func runEntityList(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
 
-	// Get entity registry
-	registry, err := ws.EntityRegistryList()
+	index, err := loadEntityRegistryIndex(ws, entityListFilter)
 	if err != nil {
 		return err
 	}
 
-	registryMap := make(map[string]map[string]interface{})
-	for _, e := range registry {
-		if entry, ok := e.(map[string]interface{}); ok {
-			if entityID, ok := entry["entity_id"].(string); ok {
-				registryMap[entityID] = entry
-			}
-		}
-	}
-
-	// Get device registry for device names
-	deviceMap := make(map[string]string) // device_id -> name
-	devices, err := ws.DeviceRegistryList()
-	if err == nil {
-		for _, d := range devices {
-			if device, ok := d.(map[string]interface{}); ok {
-				deviceID, _ := device["id"].(string)
-				name, _ := device["name"].(string)
-				nameByUser, _ := device["name_by_user"].(string)
-				if nameByUser != "" {
-					deviceMap[deviceID] = nameByUser
-				} else if name != "" {
-					deviceMap[deviceID] = name
-				}
-			}
-		}
-	}
-
-	// Build area-to-floor map if floor filter is used
-	var areaFloorMap map[string]string
-	if entityListFloor != "" {
-		areaFloorMap = make(map[string]string)
-		areas, err := ws.AreaRegistryList()
-		if err == nil {
-			for _, a := range areas {
-				if area, ok := a.(map[string]interface{}); ok {
-					areaID, _ := area["area_id"].(string)
-					floorID, _ := area["floor_id"].(string)
-					if areaID != "" {
-						areaFloorMap[areaID] = floorID
-					}
-				}
-			}
-		}
-	}
-
 	// Get states
-	states, err := ws.GetStates()
+	states, err := ws.ListStates()
 	if err != nil {
 		return err
 	}
 
 	var entities []map[string]interface{}
-	for _, s := range states {
-		state, ok := s.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		entityID, _ := state["entity_id"].(string)
-		parts := strings.SplitN(entityID, ".", 2)
-		entityDomain := ""
-		if len(parts) > 0 {
-			entityDomain = parts[0]
-		}
-
-		// Apply entity ID filter
-		if entityListID != "" && entityID != entityListID {
+	for _, state := range states {
+		if !entityListFilter.matches(index, state.EntityID, state.Attributes) {
 			continue
 		}
 
-		// Apply domain filter
-		if entityListDomain != "" && entityDomain != entityListDomain {
-			continue
-		}
-
-		regEntry := registryMap[entityID]
-
-		// Apply device filter
-		if entityListDevice != "" {
-			if regEntry == nil {
-				continue
-			}
-			deviceID, _ := regEntry["device_id"].(string)
-			if deviceID != entityListDevice {
-				continue
-			}
-		}
-
-		// Apply area filter
-		if entityListArea != "" {
-			if regEntry == nil {
-				continue
-			}
-			if areaID, _ := regEntry["area_id"].(string); areaID != entityListArea {
-				continue
-			}
-		}
-
-		// Apply floor filter (check if entity's area is on the specified floor)
-		if entityListFloor != "" {
-			if regEntry == nil {
-				continue
-			}
-			areaID, _ := regEntry["area_id"].(string)
-			if areaID == "" {
-				continue
-			}
-			floorID := areaFloorMap[areaID]
-			if floorID != entityListFloor {
-				continue
-			}
-		}
-
-		// Apply label filter
-		if entityListLabel != "" {
-			if regEntry == nil {
-				continue
-			}
-			labels, _ := regEntry["labels"].([]interface{})
-			hasLabel := false
-			for _, l := range labels {
-				if labelStr, ok := l.(string); ok && labelStr == entityListLabel {
-					hasLabel = true
-					break
-				}
-			}
-			if !hasLabel {
-				continue
-			}
-		}
-
-		attrs, _ := state["attributes"].(map[string]interface{})
-
-		// Apply device class filter
-		if entityListDeviceClass != "" {
-			// Check registry entry first (original_device_class takes precedence)
-			var deviceClass string
-			if regEntry != nil {
-				if dc, ok := regEntry["original_device_class"].(string); ok && dc != "" {
-					deviceClass = dc
-				} else if dc, ok := regEntry["device_class"].(string); ok && dc != "" {
-					deviceClass = dc
-				}
-			}
-			// Fall back to state attributes if not in registry
-			if deviceClass == "" {
-				if dc, ok := attrs["device_class"].(string); ok {
-					deviceClass = dc
-				}
-			}
-			if deviceClass != entityListDeviceClass {
-				continue
-			}
-		}
-		friendlyName, _ := attrs["friendly_name"].(string)
+		regEntry := index.entries[state.EntityID]
 
 		var areaID string
 		var deviceID string
-		var labels []interface{}
+		var labels []string
 		var disabled bool
-		var deviceClass string
 		if regEntry != nil {
-			areaID, _ = regEntry["area_id"].(string)
-			deviceID, _ = regEntry["device_id"].(string)
-			labels, _ = regEntry["labels"].([]interface{})
-			disabled = regEntry["disabled_by"] != nil
-			// Get device class from registry (original_device_class takes precedence)
-			if dc, ok := regEntry["original_device_class"].(string); ok && dc != "" {
-				deviceClass = dc
-			} else if dc, ok := regEntry["device_class"].(string); ok && dc != "" {
-				deviceClass = dc
-			}
-		}
-		// Fall back to state attributes if not in registry
-		if deviceClass == "" {
-			if dc, ok := attrs["device_class"].(string); ok {
-				deviceClass = dc
-			}
+			areaID = regEntry.AreaID
+			deviceID = regEntry.DeviceID
+			labels = regEntry.Labels
+			disabled = regEntry.IsDisabled()
 		}
+		deviceClass := entityDeviceClass(regEntry, state.Attributes)
 
 		entities = append(entities, map[string]interface{}{
-			"entity_id":    entityID,
-			"state":        state["state"],
-			"name":         friendlyName,
+			"entity_id":    state.EntityID,
+			"state":        state.State,
+			"name":         state.FriendlyName(),
 			"area_id":      areaID,
 			"device_id":    deviceID,
 			"device_class": deviceClass,
@@ -260,10 +85,10 @@ func runEntityList(cmd *cobra.Command, args []string) error {
 
 	// Handle count mode
 	if entityListCount {
-		if textMode {
+		if customTextOutput(textMode) {
 			fmt.Printf("Count: %d\n", len(entities))
 		} else {
-			client.PrintOutput(map[string]interface{}{"count": len(entities)}, false, "")
+			client.PrintOutput(map[string]interface{}{"count": len(entities)}, textMode, "")
 		}
 		return nil
 	}
@@ -275,7 +100,7 @@ func runEntityList(cmd *cobra.Command, args []string) error {
 
 	// Handle brief mode
 	if entityListBrief {
-		if textMode {
+		if customTextOutput(textMode) {
 			for _, item := range entities {
 				entityID, _ := item["entity_id"].(string)
 				name, _ := item["name"].(string)
@@ -293,20 +118,20 @@ func runEntityList(cmd *cobra.Command, args []string) error {
 					"name":      item["name"],
 				})
 			}
-			client.PrintOutput(brief, false, "")
+			client.PrintOutput(brief, textMode, "")
 		}
 		return nil
 	}
 
 	// Full output
-	if textMode {
+	if customTextOutput(textMode) {
 		if len(entities) == 0 {
 			fmt.Println("No entities.")
 			return nil
 		}
-		printEntitiesGroupedByDevice(entities, deviceMap)
+		printEntitiesGroupedByDevice(entities, index.deviceNames)
 	} else {
-		client.PrintOutput(entities, false, "")
+		client.PrintOutput(entities, textMode, "")
 	}
 	return nil
 }
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	entityWatchFilter  entityFilter
+	entityWatchUntil   string
+	entityWatchInitial bool
+)
+
+var entityWatchCmd = &cobra.Command{
+	Use:   "watch [entity_id...]",
+	Short: "Stream entity state changes",
+	Long: `Follow state changes of entities without polling, printing one line per change.
+
+Accepts the same filters as 'entity list'. With --json each change is printed
+as one JSON object per line (NDJSON).
+
+Use --until to block until every watched entity reaches a state, for example
+in scripts. Combine with the global --timeout to fail if that does not happen
+in time; without --until, --timeout simply ends the watch.
+
+Examples:
+  hab entity watch light.kitchen
+  hab entity watch --domain binary_sensor --area living_room
+  hab entity watch cover.garage --until closed --timeout 2m`,
+	RunE: runEntityWatch,
+}
+
+func init() {
+	entityCmd.AddCommand(entityWatchCmd)
+	addEntityFilterFlags(entityWatchCmd, &entityWatchFilter)
+	entityWatchCmd.Flags().StringVar(&entityWatchUntil, "until", "", "Exit once every watched entity has this state")
+	entityWatchCmd.Flags().BoolVar(&entityWatchInitial, "initial", false, "Print the current state of every watched entity first")
+}
+
+func runEntityWatch(cmd *cobra.Command, args []string) error {
+	textMode := viper.GetBool("text")
+
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	index, err := loadEntityRegistryIndex(ws, entityWatchFilter)
+	if err != nil {
+		return err
+	}
+
+	events, unsubscribe, err := ws.SubscribeEntities(args)
+	if err != nil {
+		return err
+	}
+	defer unsubscribe()
+
+	ctx := cmd.Context()
+
+	states := make(client.EntityStates)
+	initial := true
+
+	for {
+		select {
+		case <-ctx.Done():
+			return entityWatchStopped(ctx)
+		case event, ok := <-events:
+			if !ok {
+				// The connection closes together with the command context
+				if ctx.Err() != nil {
+					return entityWatchStopped(ctx)
+				}
+				return fmt.Errorf("subscription closed")
+			}
+
+			for _, change := range states.Apply(event) {
+				if !entityWatchFilter.matches(index, change.EntityID, changeAttributes(change)) {
+					delete(states, change.EntityID)
+					continue
+				}
+				if initial && !entityWatchInitial {
+					continue
+				}
+				if !initial && !change.StateChanged() {
+					continue
+				}
+				if err := printEntityChange(change, textMode); err != nil {
+					return err
+				}
+			}
+			initial = false
+
+			if entityWatchUntil != "" && allInState(states, entityWatchUntil) {
+				return nil
+			}
+		}
+	}
+}
+
+// entityWatchStopped returns the result of a watch ended by its context:
+// an interrupt ends it cleanly, a timeout fails while --until is pending
+func entityWatchStopped(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && entityWatchUntil != "" {
+		return fmt.Errorf("timed out after %s waiting for state '%s'", viper.GetDuration("timeout"), entityWatchUntil)
+	}
+	return nil
+}
+
+// changeAttributes returns the attributes of the newest known state in change
+func changeAttributes(change client.EntityChange) map[string]interface{} {
+	state := change.New
+	if state == nil {
+		state = change.Old
+	}
+	attrs, _ := state["attributes"].(map[string]interface{})
+	return attrs
+}
+
+// allInState reports whether at least one entity is tracked and all of them have state
+func allInState(states client.EntityStates, state string) bool {
+	if len(states) == 0 {
+		return false
+	}
+	for _, s := range states {
+		if s["state"] != state {
+			return false
+		}
+	}
+	return true
+}
+
+// printEntityChange writes one change as an NDJSON line or a text line
+func printEntityChange(change client.EntityChange, textMode bool) error {
+	oldState := stateValue(change.Old)
+	newState := stateValue(change.New)
+
+	if !textMode {
+		line := map[string]interface{}{
+			"entity_id": change.EntityID,
+			"old_state": nil,
+			"new_state": nil,
+		}
+		if change.Old != nil {
+			line["old_state"] = oldState
+		}
+		if change.New != nil {
+			line["new_state"] = newState
+			line["attributes"] = change.New["attributes"]
+			line["last_changed"] = change.New["last_changed"]
+		} else {
+			line["removed"] = true
+		}
+		b, err := json.Marshal(line)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	name, _ := changeAttributes(change)["friendly_name"].(string)
+	label := change.EntityID
+	if name != "" && name != change.EntityID {
+		label = fmt.Sprintf("%s (%s)", name, change.EntityID)
+	}
+
+	switch {
+	case change.New == nil:
+		fmt.Printf("%s: removed\n", label)
+	case change.Old == nil:
+		fmt.Printf("%s: %s\n", label, newState)
+	default:
+		fmt.Printf("%s: %s -> %s\n", label, oldState, newState)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/home-assistant/hab/client/clienttest"
+)
+
+func kitchenData() *clienttest.Data {
+	data := clienttest.NewData()
+	data.States["light.kitchen"] = map[string]interface{}{
+		"entity_id":  "light.kitchen",
+		"state":      "off",
+		"attributes": map[string]interface{}{"friendly_name": "Kitchen"},
+	}
+	data.States["light.hall"] = map[string]interface{}{
+		"entity_id":  "light.hall",
+		"state":      "off",
+		"attributes": map[string]interface{}{"friendly_name": "Hall"},
+	}
+	return data
+}
+
+func TestEntityWatchUntil(t *testing.T) {
+	srv := newTestServer(t, kitchenData())
+
+	run := startCommand(t, "entity", "watch", "light.kitchen", "--initial", "--until", "on")
+	run.waitForOutput(t, "Kitchen (light.kitchen): off\n")
+
+	srv.SetState("light.hall", "on", map[string]interface{}{"friendly_name": "Hall"})
+	srv.SetState("light.kitchen", "on", map[string]interface{}{"friendly_name": "Kitchen"})
+
+	if err := run.wait(t); err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+	want := "Kitchen (light.kitchen): off\nKitchen (light.kitchen): off -> on\n"
+	if got := run.output(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestEntityWatchJSON(t *testing.T) {
+	srv := newTestServer(t, kitchenData())
+
+	run := startCommand(t, "entity", "watch", "--domain", "light", "--initial", "--json")
+	run.waitForOutput(t, `"entity_id":"light.kitchen"`)
+
+	srv.SetState("light.kitchen", "on", map[string]interface{}{"friendly_name": "Kitchen", "brightness": 128})
+	run.waitForOutput(t, `"new_state":"on"`)
+	if err := run.stop(t); err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(run.output()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), run.output())
+	}
+	var change map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[2]), &change); err != nil {
+		t.Fatalf("invalid NDJSON line %q: %v", lines[2], err)
+	}
+	if change["entity_id"] != "light.kitchen" || change["old_state"] != "off" || change["new_state"] != "on" {
+		t.Errorf("change = %v", change)
+	}
+	if attrs, _ := change["attributes"].(map[string]interface{}); attrs["brightness"] != float64(128) {
+		t.Errorf("attributes = %v", change["attributes"])
+	}
+}
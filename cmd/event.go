@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var eventCmd = &cobra.Command{
+	Use:     "event",
+	Short:   "Watch events",
+	Long:    `Watch events fired on the Home Assistant event bus.`,
+	GroupID: "other",
+}
+
+func init() {
+	rootCmd.AddCommand(eventCmd)
+}
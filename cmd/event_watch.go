@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	eventWatchEntityIDs []string
+	eventWatchDomain    string
+	eventWatchData      []string
+)
+
+var eventWatchCmd = &cobra.Command{
+	Use:   "watch [event_type]",
+	Short: "Stream events until interrupted",
+	Long: `Subscribe to the event bus and print events as they are fired until interrupted.
+
+Without an event type all events are streamed. With --json each event is
+printed as one JSON object per line (NDJSON).
+
+Examples:
+  hab event watch state_changed --entity-id light.kitchen
+  hab event watch call_service --domain light --json
+  hab event watch --data service=turn_on`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runEventWatch,
+}
+
+func init() {
+	eventCmd.AddCommand(eventWatchCmd)
+	eventWatchCmd.Flags().StringSliceVarP(&eventWatchEntityIDs, "entity-id", "e", nil, "Only show events for these entity IDs (repeatable)")
+	eventWatchCmd.Flags().StringVarP(&eventWatchDomain, "domain", "d", "", "Only show events for this domain (e.g., light)")
+	eventWatchCmd.Flags().StringArrayVar(&eventWatchData, "data", nil, "Only show events whose data has key=value (repeatable)")
+}
+
+func runEventWatch(cmd *cobra.Command, args []string) error {
+	eventType := ""
+	if len(args) > 0 {
+		eventType = args[0]
+	}
+	textMode := viper.GetBool("text")
+
+	dataFilter := make(map[string]string)
+	for _, kv := range eventWatchData {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --data filter %q (expected key=value)", kv)
+		}
+		dataFilter[key] = value
+	}
+
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	events, unsubscribe, err := ws.SubscribeEvents(eventType)
+	if err != nil {
+		return err
+	}
+	defer unsubscribe()
+
+	ctx := cmd.Context()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				// The connection closes together with the command context
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("subscription closed")
+			}
+			if !eventMatches(event, eventWatchEntityIDs, eventWatchDomain, dataFilter) {
+				continue
+			}
+			if err := printEvent(event, textMode); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// eventMatches applies the entity ID, domain and data filters to an event
+func eventMatches(event client.Event, entityIDs []string, domain string, dataFilter map[string]string) bool {
+	data, _ := event["data"].(map[string]interface{})
+
+	if len(entityIDs) > 0 {
+		found := false
+		for _, id := range eventEntityIDs(data) {
+			for _, want := range entityIDs {
+				if id == want {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if domain != "" {
+		// call_service and similar events carry the domain directly
+		if d, ok := data["domain"].(string); ok {
+			if d != domain {
+				return false
+			}
+		} else {
+			found := false
+			for _, id := range eventEntityIDs(data) {
+				if strings.HasPrefix(id, domain+".") {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+
+	for key, want := range dataFilter {
+		value, ok := data[key]
+		if !ok || fmt.Sprintf("%v", value) != want {
+			return false
+		}
+	}
+
+	return true
+}
+
+// eventEntityIDs returns the entity IDs referenced by event data, which may
+// be a single entity_id string or a list (e.g. in call_service targets)
+func eventEntityIDs(data map[string]interface{}) []string {
+	var ids []string
+	collect := func(v interface{}) {
+		switch val := v.(type) {
+		case string:
+			ids = append(ids, val)
+		case []interface{}:
+			for _, item := range val {
+				if s, ok := item.(string); ok {
+					ids = append(ids, s)
+				}
+			}
+		}
+	}
+	collect(data["entity_id"])
+	if serviceData, ok := data["service_data"].(map[string]interface{}); ok {
+		collect(serviceData["entity_id"])
+	}
+	return ids
+}
+
+// printEvent writes a single event as an NDJSON line or a text summary
+func printEvent(event client.Event, textMode bool) error {
+	if !textMode {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	eventType, _ := event["event_type"].(string)
+	timeFired, _ := event["time_fired"].(string)
+	data, _ := event["data"].(map[string]interface{})
+
+	if eventType == "state_changed" {
+		entityID, _ := data["entity_id"].(string)
+		oldState := stateValue(data["old_state"])
+		newState := stateValue(data["new_state"])
+		fmt.Printf("%s %s %s: %s -> %s\n", timeFired, eventType, entityID, oldState, newState)
+		return nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s %s\n", timeFired, eventType, string(b))
+	return nil
+}
+
+// stateValue extracts the state string from a state object in event data
+func stateValue(v interface{}) string {
+	state, ok := v.(map[string]interface{})
+	if !ok {
+		return "(none)"
+	}
+	s, _ := state["state"].(string)
+	return s
+}
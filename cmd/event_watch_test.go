@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fireUntilSeen fires events until the watch prints one of them, since a
+// watch prints nothing once it has subscribed
+func fireUntilSeen(t *testing.T, run *commandRun, fire func(n int), want string) {
+	t.Helper()
+	n := 0
+	eventually(t, func() bool {
+		n++
+		fire(n)
+		time.Sleep(50 * time.Millisecond)
+		return strings.Contains(run.output(), want)
+	}, "output to contain %q", want)
+}
+
+func TestEventWatchFilters(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	run := startCommand(t, "event", "watch", "call_service", "--domain", "light", "--json")
+	fireUntilSeen(t, run, func(n int) {
+		srv.FireEvent("call_service", map[string]interface{}{"domain": "switch", "service": "turn_on"})
+		srv.FireEvent("automation_triggered", map[string]interface{}{"name": "x"})
+		srv.FireEvent("call_service", map[string]interface{}{"domain": "light", "service": "turn_on", "n": n})
+	}, `"domain":"light"`)
+	if err := run.stop(t); err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(run.output()), "\n") {
+		var event struct {
+			EventType string                 `json:"event_type"`
+			Data      map[string]interface{} `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		if event.EventType != "call_service" || event.Data["domain"] != "light" {
+			t.Errorf("unexpected event %s", line)
+		}
+	}
+}
+
+func TestEventWatchReconnects(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	run := startCommand(t, "event", "watch", "test_event")
+	fireUntilSeen(t, run, func(n int) {
+		srv.FireEvent("test_event", map[string]interface{}{"phase": "before", "n": n})
+	}, `"phase":"before"`)
+
+	// The subscription is restored on the new connection
+	srv.DropConnections()
+	fireUntilSeen(t, run, func(n int) {
+		srv.FireEvent("test_event", map[string]interface{}{"phase": "after", "n": n})
+	}, `"phase":"after"`)
+
+	if err := run.stop(t); err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(run.output()), "\n") {
+		if !strings.Contains(line, `test_event {"n":`) {
+			t.Errorf("unexpected line %q", line)
+		}
+	}
+}
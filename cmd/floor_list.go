@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -32,17 +31,10 @@ func init() {
 }
 
 func runFloorList(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -68,10 +60,10 @@ func runFloorList(cmd *cobra.Command, args []string) error {
 
 	// Handle count mode
 	if floorListCount {
-		if textMode {
+		if customTextOutput(textMode) {
 			fmt.Printf("Count: %d\n", len(floors))
 		} else {
-			client.PrintOutput(map[string]interface{}{"count": len(floors)}, false, "")
+			client.PrintOutput(map[string]interface{}{"count": len(floors)}, textMode, "")
 		}
 		return nil
 	}
@@ -83,7 +75,7 @@ func runFloorList(cmd *cobra.Command, args []string) error {
 
 	// Handle brief mode
 	if floorListBrief {
-		if textMode {
+		if customTextOutput(textMode) {
 			for _, f := range floors {
 				if floor, ok := f.(map[string]interface{}); ok {
 					name, _ := floor["name"].(string)
@@ -101,13 +93,13 @@ func runFloorList(cmd *cobra.Command, args []string) error {
 					})
 				}
 			}
-			client.PrintOutput(brief, false, "")
+			client.PrintOutput(brief, textMode, "")
 		}
 		return nil
 	}
 
 	// Full output
-	if textMode {
+	if customTextOutput(textMode) {
 		if len(floors) == 0 {
 			fmt.Println("No floors.")
 			return nil
@@ -126,7 +118,7 @@ func runFloorList(cmd *cobra.Command, args []string) error {
 			}
 		}
 	} else {
-		client.PrintOutput(floors, false, "")
+		client.PrintOutput(floors, textMode, "")
 	}
 	return nil
 }
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -18,9 +17,12 @@ var (
 var floorUpdateCmd = &cobra.Command{
 	Use:   "update <floor_id>",
 	Short: "Update a floor",
-	Long:  `Update an existing floor.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runFloorUpdate,
+	Long: `Update an existing floor.
+
+With --patch (JSON Patch, RFC 6902) or --merge (JSON merge patch, RFC 7396)
+the changes are given as a patch of the floor instead of field flags.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFloorUpdate,
 }
 
 func init() {
@@ -28,13 +30,18 @@ func init() {
 	floorUpdateCmd.Flags().StringVar(&floorUpdateName, "name", "", "New name for the floor")
 	floorUpdateCmd.Flags().StringVar(&floorUpdateIcon, "icon", "", "Icon for the floor")
 	floorUpdateCmd.Flags().IntVar(&floorUpdateLevel, "level", 0, "Floor level")
+	addPatchInputFlags(floorUpdateCmd)
 }
 
 func runFloorUpdate(cmd *cobra.Command, args []string) error {
 	floorID := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
+	patch, err := readFlagsPatch(cmd)
+	if err != nil {
+		return err
+	}
+
 	params := make(map[string]interface{})
 	if floorUpdateName != "" {
 		params["name"] = floorUpdateName
@@ -46,22 +53,30 @@ func runFloorUpdate(cmd *cobra.Command, args []string) error {
 		params["level"] = floorUpdateLevel
 	}
 
-	if len(params) == 0 {
-		return fmt.Errorf("no update parameters provided")
-	}
-
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	if err := checkUpdateParams(patch, params); err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
 
+	if patch != nil {
+		items, err := ws.FloorRegistryList()
+		if err != nil {
+			return err
+		}
+		current, err := findItem(items, "floor_id", floorID, "floor")
+		if err != nil {
+			return err
+		}
+		if params, err = patchParams(current, patch, "floor_id"); err != nil {
+			return err
+		}
+	}
+
 	result, err := ws.FloorRegistryUpdate(floorID, params)
 	if err != nil {
 		return err
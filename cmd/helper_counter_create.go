@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -22,7 +21,7 @@ var helperCounterCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new counter helper",
 	Long:  `Create a new counter helper that can be incremented/decremented.`,
-	Args:  cobra.ExactArgs(1),
+	Args:  bulkArgs(cobra.ExactArgs(1)),
 	RunE:  runHelperCounterCreate,
 }
 
@@ -34,21 +33,19 @@ func init() {
 	helperCounterCreateCmd.Flags().IntVar(&helperCounterCreateMaximum, "maximum", 0, "Maximum value (0 for no limit)")
 	helperCounterCreateCmd.Flags().IntVar(&helperCounterCreateStep, "step", 1, "Step value for increment/decrement")
 	helperCounterCreateCmd.Flags().BoolVar(&helperCounterCreateRestore, "restore", true, "Restore value after restart")
+	addBulkFlags(helperCounterCreateCmd, "counter helpers")
 }
 
 func runHelperCounterCreate(cmd *cobra.Command, args []string) error {
+	if bulkInput.Bulk {
+		return runHelperBulkCreate(cmd, "counter")
+	}
+
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
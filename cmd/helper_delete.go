@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -35,7 +34,6 @@ func init() {
 func runHelperDelete(cmd *cobra.Command, args []string) error {
 	entityID := args[0]
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	// Extract domain from entity_id
@@ -66,14 +64,8 @@ func runHelperDelete(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unsupported helper domain: %s", domain)
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
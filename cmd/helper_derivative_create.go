@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -47,7 +46,6 @@ func init() {
 
 func runHelperDerivativeCreate(cmd *cobra.Command, args []string) error {
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	// Validate time unit
@@ -67,14 +65,11 @@ func runHelperDerivativeCreate(cmd *cobra.Command, args []string) error {
 	// Parse time window (HH:MM:SS format)
 	timeWindow := parseDuration(helperDerivativeCreateTimeWindow)
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	rest, err := getRestClient(cmd)
+	if err != nil {
 		return err
 	}
 
-	rest := client.NewRestClient(creds.URL, creds.AccessToken)
-
 	// Start the config flow for derivative
 	flowResult, err := rest.ConfigFlowCreate("derivative")
 	if err != nil {
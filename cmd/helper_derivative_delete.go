@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -29,21 +28,17 @@ func init() {
 func runHelperDerivativeDelete(cmd *cobra.Command, args []string) error {
 	id := args[0]
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	rest, err := getRestClient(cmd)
+	if err != nil {
 		return err
 	}
 
-	rest := client.NewRestClient(creds.URL, creds.AccessToken)
-
 	entryID := id
 	if strings.Contains(id, ".") {
-		ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-		if err := ws.Connect(); err != nil {
+		ws, err := connectWebSocket(cmd)
+		if err != nil {
 			return err
 		}
 		defer ws.Close()
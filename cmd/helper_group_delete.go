@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -29,24 +28,20 @@ func init() {
 func runHelperGroupDelete(cmd *cobra.Command, args []string) error {
 	id := args[0]
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	// Use REST API for config entry operations
+	rest, err := getRestClient(cmd)
+	if err != nil {
 		return err
 	}
 
-	// Use REST API for config entry operations
-	rest := client.NewRestClient(creds.URL, creds.AccessToken)
-
 	// If it looks like an entity_id, we need to resolve it to config_entry_id
 	entryID := id
 	if strings.Contains(id, ".") {
 		// It's an entity_id, need to resolve to config entry
-		ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-		if err := ws.Connect(); err != nil {
+		ws, err := connectWebSocket(cmd)
+		if err != nil {
 			return err
 		}
 		defer ws.Close()
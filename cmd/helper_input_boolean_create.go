@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -18,7 +17,7 @@ var helperInputBooleanCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new input boolean helper",
 	Long:  `Create a new input boolean (toggle) helper.`,
-	Args:  cobra.ExactArgs(1),
+	Args:  bulkArgs(cobra.ExactArgs(1)),
 	RunE:  runHelperInputBooleanCreate,
 }
 
@@ -26,21 +25,19 @@ func init() {
 	helperInputBooleanParentCmd.AddCommand(helperInputBooleanCreateCmd)
 	helperInputBooleanCreateCmd.Flags().StringVarP(&helperInputBooleanCreateIcon, "icon", "i", "", "Icon for the helper (e.g., mdi:toggle-switch)")
 	helperInputBooleanCreateCmd.Flags().BoolVar(&helperInputBooleanCreateInitial, "initial", false, "Initial value (true/false)")
+	addBulkFlags(helperInputBooleanCreateCmd, "input boolean helpers")
 }
 
 func runHelperInputBooleanCreate(cmd *cobra.Command, args []string) error {
+	if bulkInput.Bulk {
+		return runHelperBulkCreate(cmd, "input_boolean")
+	}
+
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
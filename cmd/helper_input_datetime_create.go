@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -27,7 +26,7 @@ Examples:
   hab helper-input-datetime create "My Date" --has-date
   hab helper-input-datetime create "My Time" --has-time
   hab helper-input-datetime create "My DateTime" --has-date --has-time`,
-	Args: cobra.ExactArgs(1),
+	Args: bulkArgs(cobra.ExactArgs(1)),
 	RunE: runHelperInputDatetimeCreate,
 }
 
@@ -37,11 +36,15 @@ func init() {
 	helperInputDatetimeCreateCmd.Flags().BoolVar(&helperInputDatetimeCreateHasDate, "has-date", false, "Include date component")
 	helperInputDatetimeCreateCmd.Flags().BoolVar(&helperInputDatetimeCreateHasTime, "has-time", false, "Include time component")
 	helperInputDatetimeCreateCmd.Flags().StringVar(&helperInputDatetimeCreateInitial, "initial", "", "Initial value (format depends on has_date/has_time)")
+	addBulkFlags(helperInputDatetimeCreateCmd, "input datetime helpers")
 }
 
 func runHelperInputDatetimeCreate(cmd *cobra.Command, args []string) error {
+	if bulkInput.Bulk {
+		return runHelperBulkCreate(cmd, "input_datetime")
+	}
+
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	// Validate that at least one of has_date or has_time is true
@@ -49,14 +52,8 @@ func runHelperInputDatetimeCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("at least one of --has-date or --has-time must be specified")
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
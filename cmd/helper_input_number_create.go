@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -23,7 +22,7 @@ var helperInputNumberCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new input number helper",
 	Long:  `Create a new input number helper with min/max range.`,
-	Args:  cobra.ExactArgs(1),
+	Args:  bulkArgs(cobra.ExactArgs(1)),
 	RunE:  runHelperInputNumberCreate,
 }
 
@@ -36,23 +35,23 @@ func init() {
 	helperInputNumberCreateCmd.Flags().Float64Var(&helperInputNumberCreateInitial, "initial", 0, "Initial value")
 	helperInputNumberCreateCmd.Flags().StringVar(&helperInputNumberCreateMode, "mode", "slider", "Display mode (box or slider)")
 	helperInputNumberCreateCmd.Flags().StringVar(&helperInputNumberCreateUnitOfMeasurement, "unit", "", "Unit of measurement")
-	helperInputNumberCreateCmd.MarkFlagRequired("min")
-	helperInputNumberCreateCmd.MarkFlagRequired("max")
+	addBulkFlags(helperInputNumberCreateCmd, "input number helpers")
 }
 
 func runHelperInputNumberCreate(cmd *cobra.Command, args []string) error {
-	name := args[0]
-	configDir := viper.GetString("config")
-	textMode := viper.GetBool("text")
+	if bulkInput.Bulk {
+		return runHelperBulkCreate(cmd, "input_number")
+	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	if err := requireFlags(cmd, "min", "max"); err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	name := args[0]
+	textMode := viper.GetBool("text")
+
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -19,7 +18,7 @@ var helperInputSelectCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new input select helper",
 	Long:  `Create a new input select (dropdown) helper.`,
-	Args:  cobra.ExactArgs(1),
+	Args:  bulkArgs(cobra.ExactArgs(1)),
 	RunE:  runHelperInputSelectCreate,
 }
 
@@ -28,22 +27,23 @@ func init() {
 	helperInputSelectCreateCmd.Flags().StringVarP(&helperInputSelectCreateIcon, "icon", "i", "", "Icon for the helper")
 	helperInputSelectCreateCmd.Flags().StringSliceVarP(&helperInputSelectCreateOptions, "options", "o", nil, "Options for the dropdown (required)")
 	helperInputSelectCreateCmd.Flags().StringVar(&helperInputSelectCreateInitial, "initial", "", "Initial selected option")
-	helperInputSelectCreateCmd.MarkFlagRequired("options")
+	addBulkFlags(helperInputSelectCreateCmd, "input select helpers")
 }
 
 func runHelperInputSelectCreate(cmd *cobra.Command, args []string) error {
-	name := args[0]
-	configDir := viper.GetString("config")
-	textMode := viper.GetBool("text")
+	if bulkInput.Bulk {
+		return runHelperBulkCreate(cmd, "input_select")
+	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	if err := requireFlags(cmd, "options"); err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	name := args[0]
+	textMode := viper.GetBool("text")
+
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -22,7 +21,7 @@ var helperInputTextCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new input text helper",
 	Long:  `Create a new input text helper.`,
-	Args:  cobra.ExactArgs(1),
+	Args:  bulkArgs(cobra.ExactArgs(1)),
 	RunE:  runHelperInputTextCreate,
 }
 
@@ -34,21 +33,19 @@ func init() {
 	helperInputTextCreateCmd.Flags().IntVar(&helperInputTextCreateMax, "max", 100, "Maximum length")
 	helperInputTextCreateCmd.Flags().StringVar(&helperInputTextCreatePattern, "pattern", "", "Regex pattern for validation")
 	helperInputTextCreateCmd.Flags().StringVar(&helperInputTextCreateMode, "mode", "text", "Display mode (text or password)")
+	addBulkFlags(helperInputTextCreateCmd, "input text helpers")
 }
 
 func runHelperInputTextCreate(cmd *cobra.Command, args []string) error {
+	if bulkInput.Bulk {
+		return runHelperBulkCreate(cmd, "input_text")
+	}
+
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
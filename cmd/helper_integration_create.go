@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -48,7 +47,6 @@ func init() {
 
 func runHelperIntegrationCreate(cmd *cobra.Command, args []string) error {
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	// Validate time unit
@@ -71,14 +69,11 @@ func runHelperIntegrationCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	rest, err := getRestClient(cmd)
+	if err != nil {
 		return err
 	}
 
-	rest := client.NewRestClient(creds.URL, creds.AccessToken)
-
 	// Start the config flow for integration
 	flowResult, err := rest.ConfigFlowCreate("integration")
 	if err != nil {
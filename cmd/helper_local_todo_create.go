@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -31,18 +30,14 @@ func init() {
 
 func runHelperLocalTodoCreate(cmd *cobra.Command, args []string) error {
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	// Use REST API for config flows
+	rest, err := getRestClient(cmd)
+	if err != nil {
 		return err
 	}
 
-	// Use REST API for config flows
-	rest := client.NewRestClient(creds.URL, creds.AccessToken)
-
 	// Step 1: Start the config flow for local_todo
 	flowResult, err := rest.ConfigFlowCreate("local_todo")
 	if err != nil {
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -40,7 +39,6 @@ func init() {
 
 func runHelperMinMaxCreate(cmd *cobra.Command, args []string) error {
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	// Validate aggregation type
@@ -52,14 +50,11 @@ func runHelperMinMaxCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid aggregation type: %s. Valid types: min, max, mean, median, last, range, sum", helperMinMaxCreateType)
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	rest, err := getRestClient(cmd)
+	if err != nil {
 		return err
 	}
 
-	rest := client.NewRestClient(creds.URL, creds.AccessToken)
-
 	// Start the config flow for min_max
 	flowResult, err := rest.ConfigFlowCreate("min_max")
 	if err != nil {
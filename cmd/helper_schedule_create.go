@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -20,28 +19,26 @@ var helperScheduleCreateCmd = &cobra.Command{
 
 Schedule helpers allow you to define time blocks for each day of the week.
 After creation, use the Home Assistant UI to configure the schedule blocks.`,
-	Args: cobra.ExactArgs(1),
+	Args: bulkArgs(cobra.ExactArgs(1)),
 	RunE: runHelperScheduleCreate,
 }
 
 func init() {
 	helperScheduleParentCmd.AddCommand(helperScheduleCreateCmd)
 	helperScheduleCreateCmd.Flags().StringVarP(&helperScheduleCreateIcon, "icon", "i", "", "Icon for the helper (e.g., mdi:calendar-clock)")
+	addBulkFlags(helperScheduleCreateCmd, "schedule helpers")
 }
 
 func runHelperScheduleCreate(cmd *cobra.Command, args []string) error {
+	if bulkInput.Bulk {
+		return runHelperBulkCreate(cmd, "schedule")
+	}
+
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -194,7 +193,6 @@ func init() {
 
 func runHelperTemplateCreate(cmd *cobra.Command, args []string) error {
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	// Validate template type
@@ -213,15 +211,12 @@ func runHelperTemplateCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid template type: %s. Valid types: alarm_control_panel, binary_sensor, button, image, number, select, sensor, switch", helperTemplateCreateType)
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	// Use REST API for config flows
+	rest, err := getRestClient(cmd)
+	if err != nil {
 		return err
 	}
 
-	// Use REST API for config flows
-	rest := client.NewRestClient(creds.URL, creds.AccessToken)
-
 	// Step 1: Start the config flow for template
 	flowResult, err := rest.ConfigFlowCreate("template")
 	if err != nil {
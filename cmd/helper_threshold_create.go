@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -43,7 +42,6 @@ func init() {
 
 func runHelperThresholdCreate(cmd *cobra.Command, args []string) error {
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	// At least one threshold must be set
@@ -53,14 +51,11 @@ func runHelperThresholdCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("at least one of --lower or --upper must be specified")
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	rest, err := getRestClient(cmd)
+	if err != nil {
 		return err
 	}
 
-	rest := client.NewRestClient(creds.URL, creds.AccessToken)
-
 	// Start the config flow for threshold
 	flowResult, err := rest.ConfigFlowCreate("threshold")
 	if err != nil {
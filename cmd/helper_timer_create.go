@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -21,7 +20,7 @@ var helperTimerCreateCmd = &cobra.Command{
 	Long: `Create a new timer helper that counts down.
 
 Duration can be specified in format: HH:MM:SS or just seconds (e.g., "01:30:00" or "5400").`,
-	Args: cobra.ExactArgs(1),
+	Args: bulkArgs(cobra.ExactArgs(1)),
 	RunE: runHelperTimerCreate,
 }
 
@@ -30,21 +29,19 @@ func init() {
 	helperTimerCreateCmd.Flags().StringVarP(&helperTimerCreateIcon, "icon", "i", "", "Icon for the helper")
 	helperTimerCreateCmd.Flags().StringVarP(&helperTimerCreateDuration, "duration", "d", "", "Default duration (e.g., 00:05:00 for 5 minutes)")
 	helperTimerCreateCmd.Flags().BoolVar(&helperTimerCreateRestore, "restore", true, "Restore timer state after restart")
+	addBulkFlags(helperTimerCreateCmd, "timer helpers")
 }
 
 func runHelperTimerCreate(cmd *cobra.Command, args []string) error {
+	if bulkInput.Bulk {
+		return runHelperBulkCreate(cmd, "timer")
+	}
+
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
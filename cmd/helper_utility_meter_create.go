@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -50,7 +49,6 @@ func init() {
 
 func runHelperUtilityMeterCreate(cmd *cobra.Command, args []string) error {
 	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	// Map user-friendly cycle names to internal values
@@ -70,14 +68,11 @@ func runHelperUtilityMeterCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid cycle: %s. Valid cycles: none, quarter-hourly, hourly, daily, weekly, monthly, bimonthly, quarterly, yearly", helperUtilityMeterCreateCycle)
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	rest, err := getRestClient(cmd)
+	if err != nil {
 		return err
 	}
 
-	rest := client.NewRestClient(creds.URL, creds.AccessToken)
-
 	// Start the config flow for utility_meter
 	flowResult, err := rest.ConfigFlowCreate("utility_meter")
 	if err != nil {
@@ -3,8 +3,8 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
+	"github.com/home-assistant/hab/input"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -18,9 +18,12 @@ var (
 var labelCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new label",
-	Long:  `Create a new label in Home Assistant.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runLabelCreate,
+	Long: `Create a new label in Home Assistant.
+
+With --bulk, the input is a list of labels with a 'name' and any of icon,
+color and description.`,
+	Args: bulkArgs(cobra.ExactArgs(1)),
+	RunE: runLabelCreate,
 }
 
 func init() {
@@ -28,21 +31,30 @@ func init() {
 	labelCreateCmd.Flags().StringVar(&labelCreateIcon, "icon", "", "Icon for the label")
 	labelCreateCmd.Flags().StringVar(&labelCreateColor, "color", "", "Color for the label")
 	labelCreateCmd.Flags().StringVar(&labelCreateDescription, "description", "", "Description of the label")
+	addBulkFlags(labelCreateCmd, "labels")
 }
 
 func runLabelCreate(cmd *cobra.Command, args []string) error {
-	name := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
+	if bulkInput.Bulk {
+		items, err := input.ParseInputList(bulkInput.Data, bulkInput.File, bulkInput.Format)
+		if err != nil {
+			return err
+		}
+		ws, err := connectWebSocket(cmd)
+		if err != nil {
+			return err
+		}
+		defer ws.Close()
+		return runBulk(items, textMode, func(item map[string]interface{}) (string, interface{}, error) {
+			return bulkCreateNamed(item, ws.LabelRegistryCreate)
+		})
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	name := args[0]
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -6,7 +6,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -29,7 +28,6 @@ func init() {
 
 func runLabelDelete(cmd *cobra.Command, args []string) error {
 	labelID := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	if !labelDeleteForce && !textMode {
@@ -43,14 +41,8 @@ func runLabelDelete(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -32,17 +31,10 @@ func init() {
 }
 
 func runLabelList(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -68,10 +60,10 @@ func runLabelList(cmd *cobra.Command, args []string) error {
 
 	// Handle count mode
 	if labelListCount {
-		if textMode {
+		if customTextOutput(textMode) {
 			fmt.Printf("Count: %d\n", len(labels))
 		} else {
-			client.PrintOutput(map[string]interface{}{"count": len(labels)}, false, "")
+			client.PrintOutput(map[string]interface{}{"count": len(labels)}, textMode, "")
 		}
 		return nil
 	}
@@ -83,7 +75,7 @@ func runLabelList(cmd *cobra.Command, args []string) error {
 
 	// Handle brief mode
 	if labelListBrief {
-		if textMode {
+		if customTextOutput(textMode) {
 			for _, l := range labels {
 				if label, ok := l.(map[string]interface{}); ok {
 					name, _ := label["name"].(string)
@@ -101,13 +93,13 @@ func runLabelList(cmd *cobra.Command, args []string) error {
 					})
 				}
 			}
-			client.PrintOutput(brief, false, "")
+			client.PrintOutput(brief, textMode, "")
 		}
 		return nil
 	}
 
 	// Full output
-	if textMode {
+	if customTextOutput(textMode) {
 		if len(labels) == 0 {
 			fmt.Println("No labels.")
 			return nil
@@ -126,7 +118,7 @@ func runLabelList(cmd *cobra.Command, args []string) error {
 			}
 		}
 	} else {
-		client.PrintOutput(labels, false, "")
+		client.PrintOutput(labels, textMode, "")
 	}
 	return nil
 }
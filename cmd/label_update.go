@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -19,9 +18,12 @@ var (
 var labelUpdateCmd = &cobra.Command{
 	Use:   "update <label_id>",
 	Short: "Update a label",
-	Long:  `Update an existing label.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runLabelUpdate,
+	Long: `Update an existing label.
+
+With --patch (JSON Patch, RFC 6902) or --merge (JSON merge patch, RFC 7396)
+the changes are given as a patch of the label instead of field flags.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLabelUpdate,
 }
 
 func init() {
@@ -30,13 +32,18 @@ func init() {
 	labelUpdateCmd.Flags().StringVar(&labelUpdateIcon, "icon", "", "Icon for the label")
 	labelUpdateCmd.Flags().StringVar(&labelUpdateColor, "color", "", "Color for the label")
 	labelUpdateCmd.Flags().StringVar(&labelUpdateDescription, "description", "", "Description of the label")
+	addPatchInputFlags(labelUpdateCmd)
 }
 
 func runLabelUpdate(cmd *cobra.Command, args []string) error {
 	labelID := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
+	patch, err := readFlagsPatch(cmd)
+	if err != nil {
+		return err
+	}
+
 	params := make(map[string]interface{})
 	if labelUpdateName != "" {
 		params["name"] = labelUpdateName
@@ -51,22 +58,30 @@ func runLabelUpdate(cmd *cobra.Command, args []string) error {
 		params["description"] = labelUpdateDescription
 	}
 
-	if len(params) == 0 {
-		return fmt.Errorf("no update parameters provided")
-	}
-
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	if err := checkUpdateParams(patch, params); err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
 
+	if patch != nil {
+		items, err := ws.LabelRegistryList()
+		if err != nil {
+			return err
+		}
+		current, err := findItem(items, "label_id", labelID, "label")
+		if err != nil {
+			return err
+		}
+		if params, err = patchParams(current, patch, "label_id"); err != nil {
+			return err
+		}
+	}
+
 	result, err := ws.LabelRegistryUpdate(labelID, params)
 	if err != nil {
 		return err
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -23,23 +22,16 @@ func init() {
 }
 
 func runOverview(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
 	// Get REST client for config
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -168,10 +160,10 @@ func runOverview(cmd *cobra.Command, args []string) error {
 		result["helpers"] = helperCount
 	}
 
-	if textMode {
+	if customTextOutput(textMode) {
 		printOverviewText(result)
 	} else {
-		client.PrintOutput(result, false, "")
+		client.PrintOutput(result, textMode, "")
 	}
 	return nil
 }
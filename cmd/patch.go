@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/home-assistant/hab/input"
+	"github.com/spf13/cobra"
+)
+
+// addPatchFlags adds --patch and --merge to an update command. The input is
+// then a patch of the current configuration rather than a new one.
+func addPatchFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("patch", false, "Input is a JSON Patch (RFC 6902) applied to the current configuration")
+	cmd.Flags().Bool("merge", false, "Input is a JSON merge patch (RFC 7396) applied to the current configuration")
+	cmd.MarkFlagsMutuallyExclusive("patch", "merge")
+}
+
+// configPatch applies the --patch or --merge input to the current
+// configuration and returns the new one
+type configPatch func(current map[string]interface{}) (map[string]interface{}, error)
+
+// readConfigPatch reads the input of --patch or --merge. It returns nil if
+// neither is given, when the input is the whole new configuration.
+func readConfigPatch(cmd *cobra.Command, data, file, format string) (configPatch, error) {
+	var apply func(current map[string]interface{}) (interface{}, error)
+
+	if patch, _ := cmd.Flags().GetBool("patch"); patch {
+		ops, err := input.ParseInputList(data, file, format)
+		if err != nil {
+			return nil, err
+		}
+		apply = func(current map[string]interface{}) (interface{}, error) {
+			return input.ApplyPatch(current, ops)
+		}
+	} else if merge, _ := cmd.Flags().GetBool("merge"); merge {
+		patch, err := input.ParseInput(data, file, format)
+		if err != nil {
+			return nil, err
+		}
+		apply = func(current map[string]interface{}) (interface{}, error) {
+			return input.MergePatch(current, patch), nil
+		}
+	} else {
+		return nil, nil
+	}
+
+	return func(current map[string]interface{}) (map[string]interface{}, error) {
+		result, err := apply(current)
+		if err != nil {
+			return nil, err
+		}
+		config, ok := result.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("patched configuration is not an object")
+		}
+		return config, nil
+	}, nil
+}
+
+// addPatchInputFlags adds --patch and --merge to an update command that
+// takes its changes as field flags, with --data, --file and --format for
+// the patch itself
+func addPatchInputFlags(cmd *cobra.Command) {
+	addPatchFlags(cmd)
+	cmd.Flags().StringP("data", "d", "", "Patch as JSON or YAML, with --patch or --merge")
+	cmd.Flags().StringP("file", "f", "", "Path to the patch file, with --patch or --merge")
+	cmd.Flags().String("format", "", "Patch format (json, yaml)")
+	addTemplateFlags(cmd)
+}
+
+// readFlagsPatch reads the patch of a command set up with
+// addPatchInputFlags. It returns nil if neither --patch nor --merge is given.
+func readFlagsPatch(cmd *cobra.Command) (configPatch, error) {
+	data, _ := cmd.Flags().GetString("data")
+	file, _ := cmd.Flags().GetString("file")
+	format, _ := cmd.Flags().GetString("format")
+
+	patch, err := readConfigPatch(cmd, data, file, format)
+	if err != nil {
+		return nil, err
+	}
+	if patch == nil && (data != "" || file != "") {
+		return nil, fmt.Errorf("--data and --file need --patch or --merge")
+	}
+	return patch, nil
+}
+
+// checkUpdateParams checks that an update has either a patch or field flags
+func checkUpdateParams(patch configPatch, params map[string]interface{}) error {
+	switch {
+	case patch != nil && len(params) > 0:
+		return fmt.Errorf("--patch and --merge cannot be combined with field flags")
+	case patch == nil && len(params) == 0:
+		return fmt.Errorf("no update parameters provided")
+	}
+	return nil
+}
+
+// findItem returns the item of a registry list whose idField is id
+func findItem(items []interface{}, idField, id, kind string) (map[string]interface{}, error) {
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok && m[idField] == id {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("%s not found: %s", kind, id)
+}
+
+// patchParams applies patch to a registry item and returns the fields it
+// changed as update parameters. Removed fields are sent as null, which
+// clears them.
+func patchParams(current map[string]interface{}, patch configPatch, idField string) (map[string]interface{}, error) {
+	patched, err := patch(current)
+	if err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(patched[idField], current[idField]) {
+		return nil, fmt.Errorf("cannot change %s", idField)
+	}
+
+	params := make(map[string]interface{})
+	for k, v := range patched {
+		if old, ok := current[k]; k != idField && (!ok || !reflect.DeepEqual(old, v)) {
+			params[k] = v
+		}
+	}
+	for k := range current {
+		if _, ok := patched[k]; !ok {
+			params[k] = nil
+		}
+	}
+	return params, nil
+}
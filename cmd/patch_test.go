@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/home-assistant/hab/client/clienttest"
+)
+
+func registryData() *clienttest.Data {
+	data := clienttest.NewData()
+	data.Areas = []map[string]interface{}{
+		{"area_id": "kitchen", "name": "Kitchen", "floor_id": "ground", "icon": "mdi:silverware", "labels": []interface{}{}},
+	}
+	data.Dashboards = []map[string]interface{}{
+		{"id": "energy_view", "url_path": "energy-view", "title": "Energy", "icon": "mdi:flash", "show_in_sidebar": true, "require_admin": false, "mode": "storage"},
+	}
+	return data
+}
+
+func TestAreaUpdateMerge(t *testing.T) {
+	srv := newTestServer(t, registryData())
+
+	// null clears the icon
+	if _, err := runCommand(t, "area", "update", "kitchen", "--merge", "-d", `{"name": "Cooking", "icon": null}`); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	var area map[string]interface{}
+	srv.Update(func(d *clienttest.Data) { area = d.Areas[0] })
+	want := map[string]interface{}{"area_id": "kitchen", "name": "Cooking", "floor_id": "ground", "icon": nil, "labels": []interface{}{}}
+	if !reflect.DeepEqual(area, want) {
+		t.Errorf("area = %v, want %v", area, want)
+	}
+}
+
+func TestAreaUpdatePatchTestFails(t *testing.T) {
+	srv := newTestServer(t, registryData())
+
+	_, err := runCommand(t, "area", "update", "kitchen", "--patch", "-d", `
+- {op: test, path: /name, value: Galley}
+- {op: replace, path: /name, value: Cooking}
+`)
+	if err == nil || !strings.Contains(err.Error(), `patch operation 1 (test /name): test failed: the value is "Kitchen"`) {
+		t.Fatalf("err = %v, want a failed test operation", err)
+	}
+
+	var name interface{}
+	srv.Update(func(d *clienttest.Data) { name = d.Areas[0]["name"] })
+	if name != "Kitchen" {
+		t.Errorf("name = %v, the area was changed", name)
+	}
+}
+
+func TestAreaUpdatePatchRejectsFieldFlags(t *testing.T) {
+	newTestServer(t, registryData())
+
+	_, err := runCommand(t, "area", "update", "kitchen", "--name", "Cooking", "--merge", "-d", `{"icon": null}`)
+	if err == nil || err.Error() != "--patch and --merge cannot be combined with field flags" {
+		t.Errorf("err = %v", err)
+	}
+	_, err = runCommand(t, "area", "update", "kitchen", "-d", `{"icon": null}`)
+	if err == nil || err.Error() != "--data and --file need --patch or --merge" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestDashboardUpdatePatch(t *testing.T) {
+	srv := newTestServer(t, registryData())
+
+	if _, err := runCommand(t, "dashboard", "update", "energy_view", "--patch", "-d",
+		`[{"op": "replace", "path": "/title", "value": "Power"}, {"op": "replace", "path": "/show_in_sidebar", "value": false}]`); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	var dashboard map[string]interface{}
+	srv.Update(func(d *clienttest.Data) { dashboard = d.Dashboards[0] })
+	if dashboard["title"] != "Power" || dashboard["show_in_sidebar"] != false || dashboard["icon"] != "mdi:flash" {
+		t.Errorf("dashboard = %v", dashboard)
+	}
+
+	_, err := runCommand(t, "dashboard", "update", "energy_view", "--merge", "-d", `{"id": "other"}`)
+	if err == nil || err.Error() != "cannot change id" {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/home-assistant/hab/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestQuerySelectsOutput(t *testing.T) {
+	newTestServer(t, kitchenData())
+
+	out, err := runCommand(t, "entity", "get", "light.kitchen", "--query", "$.attributes.friendly_name")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if out != "Kitchen\n" {
+		t.Errorf("output = %q, want %q", out, "Kitchen\n")
+	}
+}
+
+func TestQueryFailsCommandWhenNotApplicable(t *testing.T) {
+	newTestServer(t, nil)
+
+	// No Home Assistant response fails to encode, so print one that does
+	unencodable := &cobra.Command{
+		Use: "unencodable",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client.PrintOutput(map[string]interface{}{"value": math.NaN()}, viper.GetBool("text"), "")
+			return nil
+		},
+	}
+	rootCmd.AddCommand(unencodable)
+	t.Cleanup(func() { rootCmd.RemoveCommand(unencodable) })
+
+	out, err := runCommand(t, "unencodable", "--query", "$.value")
+	if err == nil || !strings.HasPrefix(err.Error(), "cannot apply --query: ") {
+		t.Errorf("err = %v, want a query error", err)
+	}
+	if out != "" {
+		t.Errorf("output = %q, want nothing", out)
+	}
+
+	// The error does not carry over to the next command
+	if _, err := runCommand(t, "entity", "list", "--query", "$[*].entity_id"); err != nil {
+		t.Errorf("next command failed: %v", err)
+	}
+}
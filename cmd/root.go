@@ -1,14 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/home-assistant/hab/auth"
+	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/config"
+	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/query"
 	"github.com/home-assistant/hab/update"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -21,6 +28,23 @@ var (
 	jsonMode        bool
 	verbose         bool
 	skipUpdateCheck bool
+	commandTimeout  time.Duration
+	noCache         bool
+	cacheTTL        time.Duration
+	noDaemon        bool
+	retries         int
+	rateLimit       float64
+	caFile          string
+	clientCert      string
+	clientKey       string
+	insecure        bool
+	proxyURL        string
+	recordPath      string
+	replayPath      string
+	contextName     string
+	credentialStore string
+	secretsFile     string
+	outputQuery     string
 )
 
 // ExitWithError signals that the program should exit with a non-zero code
@@ -33,7 +57,7 @@ var rootCmd = &cobra.Command{
 to build and manage Home Assistant configurations.
 
 Output is human-readable text by default. Use --json for machine-parseable JSON output.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Handle --json flag: if set, override text mode to false
 		if viper.GetBool("json") {
 			viper.Set("text", false)
@@ -54,16 +78,69 @@ Output is human-readable text by default. Use --json for machine-parseable JSON
 			"config":  viper.GetString("config"),
 		}).Debug("Configuration")
 
+		// Bound the whole command by --timeout
+		if timeout := viper.GetDuration("timeout"); timeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			cancelTimeout = cancel
+			cmd.SetContext(ctx)
+		}
+
+		activeContext = ""
+		if err := resolveContext(cmd); err != nil {
+			return err
+		}
+
+		input.SecretsFile = viper.GetString("secrets")
+		if err := setupInputTemplate(cmd); err != nil {
+			return err
+		}
+
+		// Parse --query up front so a mistake fails before anything is changed
+		client.OutputQuery = nil
+		client.QueryError = nil
+		if expr := viper.GetString("query"); expr != "" {
+			q, err := query.Parse(expr)
+			if err != nil {
+				return fmt.Errorf("invalid --query: %w", err)
+			}
+			client.OutputQuery = q
+		}
+
 		// Check for updates (skip for update and version commands)
 		checkUpdateOnStartup(cmd)
+
+		if err := loadTransportConfig(cmd); err != nil {
+			return err
+		}
+		return loadCassette()
+	},
+	// A --query that could not be applied printed nothing, so fail
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		return client.QueryError
 	},
 }
 
-// Execute runs the root command
+// cancelTimeout releases the --timeout context once the command finished
+var cancelTimeout context.CancelFunc = func() {}
+
+// Execute runs the root command. The command context is cancelled on
+// SIGINT/SIGTERM so in-flight requests and connections are torn down.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		// Don't print auth errors again - warning was already shown
-		if !errors.Is(err, auth.ErrNotAuthenticated) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
+	cancelTimeout()
+	saveCassette()
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrNotAuthenticated):
+			// Don't print auth errors again - warning was already shown
+		case errors.Is(err, context.DeadlineExceeded):
+			fmt.Fprintf(os.Stderr, "timed out after %s\n", viper.GetDuration("timeout"))
+		case errors.Is(err, context.Canceled):
+			fmt.Fprintln(os.Stderr, "interrupted")
+		default:
 			fmt.Fprintln(os.Stderr, err)
 		}
 		ExitWithError = true
@@ -89,24 +166,69 @@ func init() {
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgDir, "config", "", "Path to config directory (default: ~/.config/home-assistant-builder)")
+	rootCmd.PersistentFlags().StringVar(&contextName, "context", "", "Use this named context (default: the current context)")
+	rootCmd.PersistentFlags().StringVar(&credentialStore, "credential-store", auth.StoreFile, "Where to keep credentials: file, passphrase, plaintext, env, or the name of a hab-credential-<name> helper")
 	rootCmd.PersistentFlags().BoolVar(&jsonMode, "json", false, "Use JSON output instead of human-readable text")
 	rootCmd.PersistentFlags().BoolVar(&textMode, "text", true, "Use human-readable text output (default)")
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Show verbose output")
 	rootCmd.PersistentFlags().BoolVar(&skipUpdateCheck, "skip-update-check", false, "Skip automatic update check on startup")
+	rootCmd.PersistentFlags().DurationVar(&commandTimeout, "timeout", 0, "Abort the command after this duration (e.g., 30s, 2m)")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", 0, "Cache registry data on disk for this long (e.g., 5m; 0 disables the cache)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Bypass the registry cache")
+	rootCmd.PersistentFlags().BoolVar(&noDaemon, "no-daemon", false, "Connect directly even if a hab daemon is running")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", client.DefaultRetries, "Retry safe requests this many times on transient errors (0 disables)")
+	rootCmd.PersistentFlags().Float64Var(&rateLimit, "rate-limit", 0, "Send at most this many requests per second (0 means unlimited)")
+	rootCmd.PersistentFlags().StringVar(&caFile, "ca-file", "", "Trust the CA certificates in this PEM file")
+	rootCmd.PersistentFlags().StringVar(&clientCert, "client-cert", "", "Client certificate PEM file for mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&clientKey, "client-key", "", "Client key PEM file for mutual TLS")
+	rootCmd.PersistentFlags().BoolVar(&insecure, "insecure", false, "Skip TLS certificate verification")
+	rootCmd.PersistentFlags().StringVar(&proxyURL, "proxy", "", "HTTP proxy URL (default: HTTPS_PROXY / HTTP_PROXY)")
+	rootCmd.PersistentFlags().StringVar(&recordPath, "record", "", "Record REST and WebSocket traffic to this cassette file")
+	rootCmd.PersistentFlags().StringVar(&replayPath, "replay", "", "Answer requests from this cassette file instead of Home Assistant")
+	rootCmd.PersistentFlags().StringVar(&outputQuery, "query", "", "Print only the output fields selected by this JSONPath or jq-style expression")
+	rootCmd.PersistentFlags().StringVar(&secretsFile, "secrets", "", "secrets.yaml for !secret in YAML input (default: nearest to the input file)")
 
 	// Bind flags to viper
 	viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
+	viper.BindPFlag("context", rootCmd.PersistentFlags().Lookup("context"))
+	viper.BindPFlag("credential-store", rootCmd.PersistentFlags().Lookup("credential-store"))
 	viper.BindPFlag("json", rootCmd.PersistentFlags().Lookup("json"))
 	viper.BindPFlag("text", rootCmd.PersistentFlags().Lookup("text"))
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
 	viper.BindPFlag("skip-update-check", rootCmd.PersistentFlags().Lookup("skip-update-check"))
+	viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	viper.BindPFlag("cache-ttl", rootCmd.PersistentFlags().Lookup("cache-ttl"))
+	viper.BindPFlag("no-cache", rootCmd.PersistentFlags().Lookup("no-cache"))
+	viper.BindPFlag("no-daemon", rootCmd.PersistentFlags().Lookup("no-daemon"))
+	viper.BindPFlag("retries", rootCmd.PersistentFlags().Lookup("retries"))
+	viper.BindPFlag("rate-limit", rootCmd.PersistentFlags().Lookup("rate-limit"))
+	viper.BindPFlag("ca-file", rootCmd.PersistentFlags().Lookup("ca-file"))
+	viper.BindPFlag("client-cert", rootCmd.PersistentFlags().Lookup("client-cert"))
+	viper.BindPFlag("client-key", rootCmd.PersistentFlags().Lookup("client-key"))
+	viper.BindPFlag("insecure", rootCmd.PersistentFlags().Lookup("insecure"))
+	viper.BindPFlag("proxy", rootCmd.PersistentFlags().Lookup("proxy"))
+	viper.BindPFlag("record", rootCmd.PersistentFlags().Lookup("record"))
+	viper.BindPFlag("replay", rootCmd.PersistentFlags().Lookup("replay"))
+	viper.BindPFlag("query", rootCmd.PersistentFlags().Lookup("query"))
+	viper.BindPFlag("secrets", rootCmd.PersistentFlags().Lookup("secrets"))
 
 	// Shell completions
 	rootCmd.RegisterFlagCompletionFunc("json", boolCompletions)
 	rootCmd.RegisterFlagCompletionFunc("text", boolCompletions)
 	rootCmd.RegisterFlagCompletionFunc("verbose", boolCompletions)
 	rootCmd.RegisterFlagCompletionFunc("skip-update-check", boolCompletions)
+	rootCmd.RegisterFlagCompletionFunc("no-cache", boolCompletions)
+	rootCmd.RegisterFlagCompletionFunc("no-daemon", boolCompletions)
+	rootCmd.RegisterFlagCompletionFunc("insecure", boolCompletions)
 	rootCmd.MarkPersistentFlagDirname("config")
+	rootCmd.RegisterFlagCompletionFunc("context", completeContextNames)
+	rootCmd.RegisterFlagCompletionFunc("credential-store", completeCredentialStores)
+	rootCmd.MarkPersistentFlagFilename("ca-file", "pem", "crt")
+	rootCmd.MarkPersistentFlagFilename("client-cert", "pem", "crt")
+	rootCmd.MarkPersistentFlagFilename("client-key", "pem", "key")
+	rootCmd.MarkPersistentFlagFilename("record", "json")
+	rootCmd.MarkPersistentFlagFilename("replay", "json")
+	rootCmd.MarkPersistentFlagFilename("secrets", "yaml")
 }
 
 func initConfig() {
@@ -120,6 +242,7 @@ func initConfig() {
 	viper.BindEnv("token", "HAB_TOKEN")
 	viper.BindEnv("refresh-token", "HAB_REFRESH_TOKEN")
 	viper.BindEnv("skip-update-check", "HAB_SKIP_UPDATE_CHECK")
+	viper.BindEnv("context", "HAB_CONTEXT")
 
 	// Set defaults
 	config.InitDefaults()
@@ -144,6 +267,10 @@ func boolCompletions(cmd *cobra.Command, args []string, toComplete string) ([]st
 	return []string{"true", "false"}, cobra.ShellCompDirectiveNoFileComp
 }
 
+func completeCredentialStores(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{auth.StoreFile, auth.StorePassphrase, auth.StorePlaintext, auth.StoreEnv}, cobra.ShellCompDirectiveNoFileComp
+}
+
 // checkUpdateOnStartup checks for updates once per day and prints a notice if available
 func checkUpdateOnStartup(cmd *cobra.Command) {
 	// Skip for certain commands
@@ -190,3 +317,10 @@ func checkUpdateOnStartup(cmd *cobra.Command) {
 		}
 	}()
 }
+
+// customTextOutput reports whether a command should print its own text
+// layout. With --query the text is what the query selects instead, printed
+// by client.PrintOutput.
+func customTextOutput(textMode bool) bool {
+	return textMode && client.OutputQuery == nil
+}
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -30,22 +30,25 @@ func init() {
 	scriptActionCreateCmd.Flags().StringVarP(&scriptActionCreateData, "data", "d", "", "Action configuration as JSON")
 	scriptActionCreateCmd.Flags().StringVarP(&scriptActionCreateFile, "file", "f", "", "Path to config file")
 	scriptActionCreateCmd.Flags().StringVar(&scriptActionCreateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(scriptActionCreateCmd)
+	addValidateFlag(scriptActionCreateCmd)
 }
 
 func runScriptActionCreate(cmd *cobra.Command, args []string) error {
 	scriptID := args[0]
 	scriptID = strings.TrimPrefix(scriptID, "script.")
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	actionConfig, err := input.ParseInput(scriptActionCreateData, scriptActionCreateFile, scriptActionCreateFormat)
 	if err != nil {
 		return err
 	}
+	if err := validateInput(cmd, schema.Action, actionConfig); err != nil {
+		return err
+	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -5,9 +5,9 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -31,6 +31,9 @@ func init() {
 	scriptActionUpdateCmd.Flags().StringVarP(&scriptActionUpdateData, "data", "d", "", "Action configuration as JSON (replaces entire action)")
 	scriptActionUpdateCmd.Flags().StringVarP(&scriptActionUpdateFile, "file", "f", "", "Path to config file")
 	scriptActionUpdateCmd.Flags().StringVar(&scriptActionUpdateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(scriptActionUpdateCmd)
+	addValidateFlag(scriptActionUpdateCmd)
+	addPatchFlags(scriptActionUpdateCmd)
 }
 
 func runScriptActionUpdate(cmd *cobra.Command, args []string) error {
@@ -41,16 +44,25 @@ func runScriptActionUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid action index: %s", args[1])
 	}
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	newAction, err := input.ParseInput(scriptActionUpdateData, scriptActionUpdateFile, scriptActionUpdateFormat)
+	patch, err := readConfigPatch(cmd, scriptActionUpdateData, scriptActionUpdateFile, scriptActionUpdateFormat)
 	if err != nil {
 		return err
 	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	var newAction map[string]interface{}
+	if patch == nil {
+		newAction, err = input.ParseInput(scriptActionUpdateData, scriptActionUpdateFile, scriptActionUpdateFormat)
+		if err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Action, newAction); err != nil {
+			return err
+		}
+	}
+
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -76,6 +88,19 @@ func runScriptActionUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("action index %d out of range (0-%d)", actionIndex, len(sequence)-1)
 	}
 
+	if patch != nil {
+		current, ok := sequence[actionIndex].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("action at index %d is not an object", actionIndex)
+		}
+		if newAction, err = patch(current); err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Action, newAction); err != nil {
+			return err
+		}
+	}
+
 	// Update the action
 	sequence[actionIndex] = newAction
 	config["sequence"] = sequence
@@ -3,9 +3,9 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -14,14 +14,18 @@ var (
 	scriptCreateData   string
 	scriptCreateFile   string
 	scriptCreateFormat string
+	scriptCreateBulk   bool
 )
 
 var scriptCreateCmd = &cobra.Command{
-	Use:     "create <id>",
-	Short:   "Create a new script",
-	Long:    `Create a new script from JSON or YAML. The ID is used to identify the script.`,
+	Use:   "create <id>",
+	Short: "Create a new script",
+	Long: `Create a new script from JSON or YAML. The ID is used to identify the script.
+
+With --bulk, the input is a list of scripts, each with an 'id' field that is
+not part of the saved configuration.`,
 	GroupID: scriptGroupCommands,
-	Args:    cobra.ExactArgs(1),
+	Args:    bulkArgs(cobra.ExactArgs(1)),
 	RunE:    runScriptCreate,
 }
 
@@ -30,29 +34,57 @@ func init() {
 	scriptCreateCmd.Flags().StringVarP(&scriptCreateData, "data", "d", "", "Script configuration as JSON")
 	scriptCreateCmd.Flags().StringVarP(&scriptCreateFile, "file", "f", "", "Path to config file")
 	scriptCreateCmd.Flags().StringVar(&scriptCreateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(scriptCreateCmd)
+	addValidateFlag(scriptCreateCmd)
+	addBulkFlag(scriptCreateCmd, &scriptCreateBulk, "scripts")
 }
 
 func runScriptCreate(cmd *cobra.Command, args []string) error {
-	scriptID := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
+	if scriptCreateBulk {
+		items, err := input.ParseInputList(scriptCreateData, scriptCreateFile, scriptCreateFormat)
+		if err != nil {
+			return err
+		}
+		restClient, err := getRestClient(cmd)
+		if err != nil {
+			return err
+		}
+		return runBulk(items, textMode, func(item map[string]interface{}) (string, interface{}, error) {
+			scriptID, err := bulkString(item, "id")
+			if err != nil {
+				return "", nil, err
+			}
+			config := make(map[string]interface{}, len(item))
+			for k, v := range item {
+				if k != "id" {
+					config[k] = v
+				}
+			}
+			if err := validateInput(cmd, schema.Script, config); err != nil {
+				return scriptID, nil, err
+			}
+			result, err := createScript(restClient, scriptID, config)
+			return scriptID, result, err
+		})
+	}
+
+	scriptID := args[0]
 	config, err := input.ParseInput(scriptCreateData, scriptCreateFile, scriptCreateFormat)
 	if err != nil {
 		return err
 	}
-
-	if _, ok := config["alias"]; !ok {
-		return fmt.Errorf("script must have an 'alias' field")
+	if err := validateInput(cmd, schema.Script, config); err != nil {
+		return err
 	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
 
-	result, err := restClient.Post(fmt.Sprintf("config/script/config/%s", scriptID), config)
+	result, err := createScript(restClient, scriptID, config)
 	if err != nil {
 		return err
 	}
@@ -60,3 +92,11 @@ func runScriptCreate(cmd *cobra.Command, args []string) error {
 	client.PrintSuccess(result, textMode, fmt.Sprintf("Script %s created successfully.", scriptID))
 	return nil
 }
+
+// createScript validates and saves a new script
+func createScript(restClient client.RestAPI, scriptID string, config map[string]interface{}) (interface{}, error) {
+	if _, ok := config["alias"]; !ok {
+		return nil, fmt.Errorf("script must have an 'alias' field")
+	}
+	return restClient.Post(fmt.Sprintf("config/script/config/%s", scriptID), config)
+}
@@ -6,7 +6,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -33,7 +32,6 @@ func runScriptDelete(cmd *cobra.Command, args []string) error {
 	// Strip "script." prefix if provided - API expects just the ID
 	scriptID = strings.TrimPrefix(scriptID, "script.")
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	if !scriptDeleteForce && !textMode {
@@ -47,8 +45,7 @@ func runScriptDelete(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -26,20 +25,13 @@ func init() {
 }
 
 func runScriptList(cmd *cobra.Command, args []string) error {
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 	listCount, _ := cmd.Flags().GetBool("count")
 	listBrief, _ := cmd.Flags().GetBool("brief")
 	listLimit, _ := cmd.Flags().GetInt("limit")
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
@@ -72,10 +64,10 @@ func runScriptList(cmd *cobra.Command, args []string) error {
 
 	// Handle count mode
 	if listCount {
-		if textMode {
+		if customTextOutput(textMode) {
 			fmt.Printf("Count: %d\n", len(result))
 		} else {
-			client.PrintOutput(map[string]interface{}{"count": len(result)}, false, "")
+			client.PrintOutput(map[string]interface{}{"count": len(result)}, textMode, "")
 		}
 		return nil
 	}
@@ -87,7 +79,7 @@ func runScriptList(cmd *cobra.Command, args []string) error {
 
 	// Handle brief mode
 	if listBrief {
-		if textMode {
+		if customTextOutput(textMode) {
 			for _, item := range result {
 				alias, _ := item["alias"].(string)
 				entityID, _ := item["entity_id"].(string)
@@ -101,13 +93,13 @@ func runScriptList(cmd *cobra.Command, args []string) error {
 					"alias":     item["alias"],
 				})
 			}
-			client.PrintOutput(brief, false, "")
+			client.PrintOutput(brief, textMode, "")
 		}
 		return nil
 	}
 
 	// Full output
-	if textMode {
+	if customTextOutput(textMode) {
 		if len(result) == 0 {
 			fmt.Println("No scripts.")
 			return nil
@@ -124,7 +116,7 @@ func runScriptList(cmd *cobra.Command, args []string) error {
 			}
 		}
 	} else {
-		client.PrintOutput(result, false, "")
+		client.PrintOutput(result, textMode, "")
 	}
 	return nil
 }
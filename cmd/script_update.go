@@ -1,11 +1,12 @@
 package cmd
 
 import (
+	"fmt"
 	"strings"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/home-assistant/hab/input"
+	"github.com/home-assistant/hab/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -17,9 +18,17 @@ var (
 )
 
 var scriptUpdateCmd = &cobra.Command{
-	Use:     "update <script_id>",
-	Short:   "Update an existing script",
-	Long:    `Update a script with new configuration.`,
+	Use:   "update <script_id>",
+	Short: "Update an existing script",
+	Long: `Update a script with new configuration.
+
+With --patch the input is a JSON Patch (RFC 6902), with --merge a JSON merge
+patch (RFC 7396), applied to the current configuration. A "test" operation
+makes the update fail if the script changed in the meantime.
+
+Examples:
+  hab script update goodnight --merge -d '{"icon": "mdi:weather-night"}'
+  hab script update goodnight --patch -d '[{"op": "remove", "path": "/sequence/2"}]'`,
 	GroupID: scriptGroupCommands,
 	Args:    cobra.ExactArgs(1),
 	RunE:    runScriptUpdate,
@@ -30,6 +39,9 @@ func init() {
 	scriptUpdateCmd.Flags().StringVarP(&scriptUpdateData, "data", "d", "", "Updated configuration as JSON")
 	scriptUpdateCmd.Flags().StringVarP(&scriptUpdateFile, "file", "f", "", "Path to config file")
 	scriptUpdateCmd.Flags().StringVar(&scriptUpdateFormat, "format", "", "Input format (json, yaml)")
+	addTemplateFlags(scriptUpdateCmd)
+	addValidateFlag(scriptUpdateCmd)
+	addPatchFlags(scriptUpdateCmd)
 }
 
 func runScriptUpdate(cmd *cobra.Command, args []string) error {
@@ -37,20 +49,46 @@ func runScriptUpdate(cmd *cobra.Command, args []string) error {
 	// Strip "script." prefix if provided - API expects just the ID
 	scriptID = strings.TrimPrefix(scriptID, "script.")
 
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
-	config, err := input.ParseInput(scriptUpdateData, scriptUpdateFile, scriptUpdateFormat)
+	patch, err := readConfigPatch(cmd, scriptUpdateData, scriptUpdateFile, scriptUpdateFormat)
 	if err != nil {
 		return err
 	}
 
-	manager := auth.NewManager(configDir)
-	restClient, err := manager.GetRestClient()
+	var config map[string]interface{}
+	if patch == nil {
+		config, err = input.ParseInput(scriptUpdateData, scriptUpdateFile, scriptUpdateFormat)
+		if err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Script, config); err != nil {
+			return err
+		}
+	}
+
+	restClient, err := getRestClient(cmd)
 	if err != nil {
 		return err
 	}
 
+	if patch != nil {
+		current, err := restClient.Get("config/script/config/" + scriptID)
+		if err != nil {
+			return err
+		}
+		currentConfig, ok := current.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid script config")
+		}
+		if config, err = patch(currentConfig); err != nil {
+			return err
+		}
+		if err := validateInput(cmd, schema.Script, config); err != nil {
+			return err
+		}
+	}
+
 	result, err := restClient.Post("config/script/config/"+scriptID, config)
 	if err != nil {
 		return err
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -57,7 +56,6 @@ func runSearchRelated(cmd *cobra.Command, args []string) error {
 	if itemID == "" {
 		return fmt.Errorf("item ID is required (use --id flag or second positional argument)")
 	}
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
 	// Validate item type
@@ -80,14 +78,8 @@ func runSearchRelated(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid item type '%s'. Valid types: entity, device, area, floor, label, automation, scene, script, config_entry, group", itemType)
 	}
 
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
-		return err
-	}
-
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
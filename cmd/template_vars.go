@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/home-assistant/hab/client"
+	"github.com/home-assistant/hab/input"
+	"github.com/spf13/cobra"
+)
+
+// addTemplateFlags adds --var and --vars-file to a command that reads input
+// with input.ParseInput. The flags are applied by the root command.
+func addTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("var", nil, "Set a template variable as key=value, used as <% .key %> in the input (repeatable)")
+	cmd.Flags().String("vars-file", "", "YAML or JSON file of template variables (--var takes precedence)")
+	cmd.MarkFlagFilename("vars-file", "yaml", "yml", "json")
+}
+
+// setupInputTemplate enables template rendering of the input when --var or
+// --vars-file was given to cmd
+func setupInputTemplate(cmd *cobra.Command) error {
+	input.Template = nil
+	if !cmd.Flags().Changed("var") && !cmd.Flags().Changed("vars-file") {
+		return nil
+	}
+
+	vars := make(map[string]interface{})
+	if file, _ := cmd.Flags().GetString("vars-file"); file != "" {
+		fileVars, err := input.ParseInput("", file, "")
+		if err != nil {
+			return fmt.Errorf("failed to read vars file: %w", err)
+		}
+		for k, v := range fileVars {
+			vars[k] = v
+		}
+	}
+
+	pairs, _ := cmd.Flags().GetStringArray("var")
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --var %q: expected key=value", pair)
+		}
+		vars[key] = value
+	}
+
+	registry := &templateRegistry{cmd: cmd, items: make(map[string][]interface{})}
+	input.Template = &input.TemplateOptions{
+		Vars:  vars,
+		Funcs: template.FuncMap{"lookup": registry.lookup},
+	}
+	return nil
+}
+
+// registryKind describes how the lookup template function finds the ID of a
+// registry entry by name
+type registryKind struct {
+	idKey    string
+	nameKeys []string
+	list     func(ws client.WebSocketAPI) ([]interface{}, error)
+}
+
+var registryKinds = map[string]registryKind{
+	"area":   {"area_id", []string{"name"}, client.WebSocketAPI.AreaRegistryList},
+	"floor":  {"floor_id", []string{"name"}, client.WebSocketAPI.FloorRegistryList},
+	"label":  {"label_id", []string{"name"}, client.WebSocketAPI.LabelRegistryList},
+	"device": {"id", []string{"name_by_user", "name"}, client.WebSocketAPI.DeviceRegistryList},
+	"entity": {"entity_id", []string{"name", "original_name"}, client.WebSocketAPI.EntityRegistryList},
+}
+
+// templateRegistry serves the lookup template function. Each registry is
+// fetched once, on first use.
+type templateRegistry struct {
+	cmd   *cobra.Command
+	items map[string][]interface{}
+}
+
+// lookup returns the ID of the area, floor, label, device or entity with the
+// given name (case-insensitive) or ID: <% lookup "area" "Living Room" %>
+func (r *templateRegistry) lookup(kind, name string) (string, error) {
+	k, ok := registryKinds[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown registry %q (use area, floor, label, device or entity)", kind)
+	}
+
+	items, ok := r.items[kind]
+	if !ok {
+		ws, err := connectWebSocket(r.cmd)
+		if err != nil {
+			return "", err
+		}
+		items, err = k.list(ws)
+		ws.Close()
+		if err != nil {
+			return "", err
+		}
+		r.items[kind] = items
+	}
+
+	var matches []string
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := item[k.idKey].(string)
+		if id == name {
+			return id, nil
+		}
+		for _, key := range k.nameKeys {
+			if n, _ := item[key].(string); n != "" && strings.EqualFold(n, name) {
+				matches = append(matches, id)
+				break
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%s %q not found", kind, name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%s name %q is ambiguous (%s)", kind, name, strings.Join(matches, ", "))
+	}
+}
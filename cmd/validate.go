@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/home-assistant/hab/schema"
+	"github.com/spf13/cobra"
+)
+
+// addValidateFlag adds --validate to a command that sends a configuration
+// of one of the kinds with a bundled schema
+func addValidateFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("validate", true, "Check the configuration against the bundled schema before sending it")
+}
+
+// validateInput checks config against the bundled schema of kind, unless
+// the command was run with --validate=false
+func validateInput(cmd *cobra.Command, kind string, config interface{}) error {
+	if validate, _ := cmd.Flags().GetBool("validate"); !validate {
+		return nil
+	}
+	return schema.Validate(kind, config)
+}
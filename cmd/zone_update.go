@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/home-assistant/hab/auth"
 	"github.com/home-assistant/hab/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -21,9 +20,12 @@ var (
 var zoneUpdateCmd = &cobra.Command{
 	Use:   "update <zone_id>",
 	Short: "Update a zone",
-	Long:  `Update an existing zone.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runZoneUpdate,
+	Long: `Update an existing zone.
+
+With --patch (JSON Patch, RFC 6902) or --merge (JSON merge patch, RFC 7396)
+the changes are given as a patch of the zone instead of field flags.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runZoneUpdate,
 }
 
 func init() {
@@ -34,13 +36,18 @@ func init() {
 	zoneUpdateCmd.Flags().Float64Var(&zoneUpdateRadius, "radius", 0, "New radius in meters")
 	zoneUpdateCmd.Flags().StringVar(&zoneUpdateIcon, "icon", "", "New icon")
 	zoneUpdateCmd.Flags().BoolVar(&zoneUpdatePassive, "passive", false, "Set passive mode")
+	addPatchInputFlags(zoneUpdateCmd)
 }
 
 func runZoneUpdate(cmd *cobra.Command, args []string) error {
 	zoneID := args[0]
-	configDir := viper.GetString("config")
 	textMode := viper.GetBool("text")
 
+	patch, err := readFlagsPatch(cmd)
+	if err != nil {
+		return err
+	}
+
 	params := make(map[string]interface{})
 	if zoneUpdateName != "" {
 		params["name"] = zoneUpdateName
@@ -61,22 +68,30 @@ func runZoneUpdate(cmd *cobra.Command, args []string) error {
 		params["passive"] = zoneUpdatePassive
 	}
 
-	if len(params) == 0 {
-		return fmt.Errorf("no update parameters provided")
-	}
-
-	manager := auth.NewManager(configDir)
-	creds, err := manager.GetCredentials()
-	if err != nil || creds == nil {
+	if err := checkUpdateParams(patch, params); err != nil {
 		return err
 	}
 
-	ws := client.NewWebSocketClient(creds.URL, creds.AccessToken)
-	if err := ws.Connect(); err != nil {
+	ws, err := connectWebSocket(cmd)
+	if err != nil {
 		return err
 	}
 	defer ws.Close()
 
+	if patch != nil {
+		items, err := ws.ZoneList()
+		if err != nil {
+			return err
+		}
+		current, err := findItem(items, "id", zoneID, "zone")
+		if err != nil {
+			return err
+		}
+		if params, err = patchParams(current, patch, "id"); err != nil {
+			return err
+		}
+	}
+
 	result, err := ws.ZoneUpdate(zoneID, params)
 	if err != nil {
 		return err
@@ -10,8 +10,20 @@ const (
 	DefaultConfigDir = "home-assistant-builder"
 	// CredentialsFile is the encrypted credentials file name
 	CredentialsFile = "credentials.json"
+	// PlaintextCredentialsFile is the unencrypted credentials file name
+	PlaintextCredentialsFile = "credentials.plain.json"
+	// CredentialsLockFile serializes token refreshes across processes
+	CredentialsLockFile = "credentials.lock"
 	// ConfigFile is the configuration file name
 	ConfigFile = "config.json"
+	// DaemonSocket is the Unix socket name of the connection daemon
+	DaemonSocket = "hab.sock"
+	// ContextsFile lists the named contexts and the one in use
+	ContextsFile = "contexts.json"
+	// ContextsDir holds a directory per named context
+	ContextsDir = "contexts"
+	// DefaultContext is the context whose files live directly in the config directory
+	DefaultContext = "default"
 )
 
 // GetConfigDir returns the configuration directory path.
@@ -44,6 +56,40 @@ func GetConfigPath(configDir string) string {
 	return filepath.Join(GetConfigDir(configDir), ConfigFile)
 }
 
+// GetContextsPath returns the path to the contexts file.
+func GetContextsPath(configDir string) string {
+	return filepath.Join(GetConfigDir(configDir), ContextsFile)
+}
+
+// GetContextDir returns the directory holding the files of a context.
+// The default context (or an empty name) uses the config directory itself.
+func GetContextDir(configDir, context string) string {
+	if context == "" || context == DefaultContext {
+		return GetConfigDir(configDir)
+	}
+	return filepath.Join(GetConfigDir(configDir), ContextsDir, context)
+}
+
+// GetContextCredentialsPath returns the path to the credentials file of a context.
+func GetContextCredentialsPath(configDir, context string) string {
+	return filepath.Join(GetContextDir(configDir, context), CredentialsFile)
+}
+
+// GetContextPlaintextCredentialsPath returns the path to the unencrypted credentials file of a context.
+func GetContextPlaintextCredentialsPath(configDir, context string) string {
+	return filepath.Join(GetContextDir(configDir, context), PlaintextCredentialsFile)
+}
+
+// GetContextLockPath returns the path to the credentials lock file of a context.
+func GetContextLockPath(configDir, context string) string {
+	return filepath.Join(GetContextDir(configDir, context), CredentialsLockFile)
+}
+
+// GetDaemonSocketPath returns the path to the connection daemon socket of a context.
+func GetDaemonSocketPath(configDir, context string) string {
+	return filepath.Join(GetContextDir(configDir, context), DaemonSocket)
+}
+
 // EnsureConfigDir creates the config directory if it doesn't exist.
 func EnsureConfigDir(configDir string) error {
 	dir := GetConfigDir(configDir)
@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// probeTimeout bounds how long a client waits for a daemon to answer.
+// A live daemon answers from memory, so this only matters for a hung one.
+const probeTimeout = 500 * time.Millisecond
+
+// Dialer returns a dial function that connects to the daemon socket,
+// whatever network address it is asked for
+func Dialer(socketPath string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socketPath)
+	}
+}
+
+// Probe returns the Info of the daemon listening on socketPath.
+// It fails fast when no daemon is running.
+func Probe(ctx context.Context, socketPath string) (*Info, error) {
+	if _, err := os.Stat(socketPath); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	resp, err := do(ctx, socketPath, http.MethodGet, "/hab/info")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var info Info
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("invalid daemon response: %w", err)
+	}
+	return &info, nil
+}
+
+// Stop asks the daemon listening on socketPath to shut down
+func Stop(ctx context.Context, socketPath string) error {
+	resp, err := do(ctx, socketPath, http.MethodPost, "/hab/stop")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a control request to the daemon
+func do(ctx context.Context, socketPath, method, path string) (*http.Response, error) {
+	hc := &http.Client{
+		Transport: &http.Transport{DialContext: Dialer(socketPath)},
+	}
+	req, err := http.NewRequestWithContext(ctx, method, BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("daemon not reachable: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("daemon returned status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,406 @@
+// Package daemon keeps one authenticated connection to Home Assistant open
+// and shares it with hab invocations over a Unix socket.
+//
+// The daemon is a local proxy: WebSocket commands sent to /api/websocket are
+// forwarded over a single upstream WebSocket connection, and REST requests
+// to /api/ are forwarded with the daemon's access token over kept-alive HTTP
+// connections. Clients reach it by dialing the socket with Dialer.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/home-assistant/hab/client"
+	log "github.com/sirupsen/logrus"
+)
+
+// BaseURL is the instance URL clients use when forwarding through the
+// daemon. The host is ignored; connections go to the socket.
+const BaseURL = "http://hab-daemon"
+
+// Info describes a running daemon
+type Info struct {
+	URL       string    `json:"url"`
+	Version   string    `json:"version"`
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Server is a connection daemon for one Home Assistant instance
+type Server struct {
+	SocketPath string
+	URL        string
+	Version    string
+
+	// Token returns the access token for upstream connections. It is called
+	// for every forwarded REST request so refreshed credentials are used.
+	Token func() (string, error)
+
+	// Retry, when set, retries safe commands on the upstream connection
+	// while it reconnects
+	Retry *client.RetryPolicy
+
+	// Transport, when set, applies TLS and proxy settings upstream
+	Transport *client.TransportConfig
+
+	// OnReady, when set, is called once the socket accepts clients
+	OnReady func()
+
+	ctx       context.Context
+	stop      context.CancelFunc
+	ws        *client.WebSocketClient
+	upgrader  websocket.Upgrader
+	startedAt time.Time
+}
+
+// NewServer creates a daemon for the instance at baseURL listening on socketPath
+func NewServer(socketPath, baseURL string, token func() (string, error)) *Server {
+	return &Server{
+		SocketPath: socketPath,
+		URL:        strings.TrimRight(baseURL, "/"),
+		Token:      token,
+	}
+}
+
+// Run connects to Home Assistant, listens on the socket and serves clients
+// until ctx is done or a client asks the daemon to stop
+func (s *Server) Run(ctx context.Context) error {
+	if info, err := Probe(ctx, s.SocketPath); err == nil {
+		return fmt.Errorf("daemon already running for %s (pid %d)", info.URL, info.PID)
+	}
+	// A socket left behind by a daemon that did not shut down cleanly
+	if err := os.Remove(s.SocketPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+
+	s.ctx, s.stop = context.WithCancel(ctx)
+	defer s.stop()
+	s.startedAt = time.Now()
+
+	token, err := s.Token()
+	if err != nil {
+		return err
+	}
+	s.ws = client.NewWebSocketClient(s.URL, token)
+	// Reconnects authenticate with a current token
+	s.ws.TokenSource = s.Token
+	s.ws.Retry = s.Retry
+	s.ws.Transport = s.Transport
+	if err := s.ws.ConnectContext(s.ctx); err != nil {
+		return fmt.Errorf("failed to connect to Home Assistant: %w", err)
+	}
+	defer s.ws.Close()
+
+	// The socket grants the daemon's credentials, keep it private
+	ln, err := listen(s.SocketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.SocketPath, err)
+	}
+
+	proxy, err := s.restProxy()
+	if err != nil {
+		ln.Close()
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hab/info", s.handleInfo)
+	mux.HandleFunc("/hab/stop", s.handleStop)
+	mux.HandleFunc("/api/websocket", s.handleWebSocket)
+	mux.Handle("/api/", proxy)
+
+	srv := &http.Server{Handler: mux}
+	go func() {
+		<-s.ctx.Done()
+		srv.Close()
+	}()
+
+	log.WithFields(log.Fields{
+		"socket": s.SocketPath,
+		"url":    s.URL,
+	}).Debug("Daemon listening")
+	if s.OnReady != nil {
+		s.OnReady()
+	}
+
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// info returns the description served on /hab/info
+func (s *Server) info() *Info {
+	return &Info{
+		URL:       s.URL,
+		Version:   s.Version,
+		PID:       os.Getpid(),
+		StartedAt: s.startedAt,
+	}
+}
+
+func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.info())
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	log.Debug("Daemon stop requested")
+	// Let the response go out before the server closes
+	go s.stop()
+}
+
+// restProxy forwards /api/ requests to Home Assistant with the daemon's token
+func (s *Server) restProxy() (http.Handler, error) {
+	target, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Transport: client.NewHTTPTransport(s.Transport),
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.WithError(err).Debug("Daemon REST forward failed")
+			writeJSONError(w, http.StatusBadGateway, err)
+		},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := s.Token()
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, err)
+			return
+		}
+		r.Header.Set("Authorization", "Bearer "+token)
+		proxy.ServeHTTP(w, r)
+	}), nil
+}
+
+// writeJSONError writes an error body in Home Assistant's {"message": ...} format
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+}
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.WithError(err).Debug("Daemon WebSocket upgrade failed")
+		return
+	}
+	sess := &session{
+		server: s,
+		conn:   conn,
+		subs:   make(map[int]func() error),
+	}
+	sess.serve()
+}
+
+// isSubscription reports whether a command keeps sending events after its result
+func isSubscription(cmdType string) bool {
+	return strings.HasPrefix(cmdType, "subscribe_") ||
+		cmdType == "system_health/info" ||
+		cmdType == "render_template"
+}
+
+// session is one client WebSocket connection multiplexed onto the upstream connection
+type session struct {
+	server  *Server
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	subsMu  sync.Mutex
+	subs    map[int]func() error
+	closed  bool
+}
+
+// serve runs the auth handshake and dispatches commands until the client disconnects
+func (ss *session) serve() {
+	defer ss.close()
+	stop := context.AfterFunc(ss.server.ctx, func() {
+		ss.conn.Close()
+	})
+	defer stop()
+
+	// Being able to open the socket is the authentication; the token the
+	// client sends is not checked
+	if err := ss.write(map[string]interface{}{"type": "auth_required"}); err != nil {
+		return
+	}
+	var auth map[string]interface{}
+	if err := ss.conn.ReadJSON(&auth); err != nil || auth["type"] != "auth" {
+		return
+	}
+	if err := ss.write(map[string]interface{}{"type": "auth_ok"}); err != nil {
+		return
+	}
+
+	// Commands are forwarded in the order the client sent them; only the
+	// events of subscriptions are relayed in the background
+	for {
+		var msg map[string]interface{}
+		if err := ss.conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		ss.handle(msg)
+	}
+}
+
+// close drops the client connection and ends its subscriptions upstream
+func (ss *session) close() {
+	ss.subsMu.Lock()
+	ss.closed = true
+	subs := ss.subs
+	ss.subs = make(map[int]func() error)
+	ss.subsMu.Unlock()
+
+	for _, unsubscribe := range subs {
+		unsubscribe()
+	}
+	ss.conn.Close()
+}
+
+func (ss *session) write(v interface{}) error {
+	ss.writeMu.Lock()
+	defer ss.writeMu.Unlock()
+	return ss.conn.WriteJSON(v)
+}
+
+func (ss *session) writeResult(id int, result interface{}) {
+	ss.write(map[string]interface{}{
+		"id":      id,
+		"type":    "result",
+		"success": true,
+		"result":  result,
+	})
+}
+
+// writeCommandError writes the error of a forwarded command. Errors from
+// Home Assistant are passed through unchanged, so clients see its codes.
+func (ss *session) writeCommandError(id int, err error) {
+	var haErr *client.WSError
+	if !errors.As(err, &haErr) {
+		ss.writeError(id, "unknown_error", err)
+		return
+	}
+	ss.write(map[string]interface{}{
+		"id":      id,
+		"type":    "result",
+		"success": false,
+		"error":   haErr,
+	})
+}
+
+func (ss *session) writeError(id int, code string, err error) {
+	ss.write(map[string]interface{}{
+		"id":      id,
+		"type":    "result",
+		"success": false,
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": err.Error(),
+		},
+	})
+}
+
+// handle forwards one client command upstream and writes its reply
+func (ss *session) handle(msg map[string]interface{}) {
+	idValue, _ := msg["id"].(float64)
+	id := int(idValue)
+	cmdType, _ := msg["type"].(string)
+
+	params := make(map[string]interface{})
+	for k, v := range msg {
+		if k != "id" && k != "type" {
+			params[k] = v
+		}
+	}
+
+	ws := ss.server.ws
+	switch {
+	case cmdType == "ping":
+		if err := ws.Ping(); err != nil {
+			ss.writeCommandError(id, err)
+			return
+		}
+		ss.write(map[string]interface{}{"id": id, "type": "pong"})
+
+	case cmdType == "unsubscribe_events":
+		subID, _ := params["subscription"].(float64)
+		ss.subsMu.Lock()
+		unsubscribe, ok := ss.subs[int(subID)]
+		delete(ss.subs, int(subID))
+		ss.subsMu.Unlock()
+		if !ok {
+			ss.writeError(id, "not_found", fmt.Errorf("subscription not found"))
+			return
+		}
+		if err := unsubscribe(); err != nil {
+			ss.writeCommandError(id, err)
+			return
+		}
+		ss.writeResult(id, nil)
+
+	case isSubscription(cmdType):
+		ss.subscribe(id, cmdType, params)
+
+	default:
+		result, err := ws.SendCommandContext(ss.server.ctx, cmdType, params)
+		if err != nil {
+			ss.writeCommandError(id, err)
+			return
+		}
+		ss.writeResult(id, result)
+	}
+}
+
+// subscribe starts an upstream subscription and relays its events under the
+// client's ID in the background
+func (ss *session) subscribe(id int, cmdType string, params map[string]interface{}) {
+	events, unsubscribe, err := ss.server.ws.Subscribe(cmdType, params)
+	if err != nil {
+		ss.writeCommandError(id, err)
+		return
+	}
+
+	ss.subsMu.Lock()
+	if ss.closed {
+		ss.subsMu.Unlock()
+		unsubscribe()
+		return
+	}
+	ss.subs[id] = unsubscribe
+	ss.subsMu.Unlock()
+
+	ss.writeResult(id, nil)
+	go func() {
+		for event := range events {
+			if err := ss.write(map[string]interface{}{
+				"id":    id,
+				"type":  "event",
+				"event": event,
+			}); err != nil {
+				return
+			}
+		}
+	}()
+}
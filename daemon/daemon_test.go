@@ -0,0 +1,158 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/home-assistant/hab/client"
+	"github.com/home-assistant/hab/client/clienttest"
+)
+
+// startDaemon runs a daemon for upstream until the test ends and returns its socket
+func startDaemon(t *testing.T, upstream *clienttest.Server) string {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+	srv := NewServer(socketPath, upstream.URL, func() (string, error) { return upstream.Token, nil })
+	ready := make(chan struct{})
+	srv.OnReady = func() { close(ready) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("daemon failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not start")
+	}
+	return socketPath
+}
+
+// dialSession opens an authenticated WebSocket session on the daemon
+func dialSession(t *testing.T, socketPath string) *websocket.Conn {
+	t.Helper()
+	dialer := websocket.Dialer{NetDialContext: Dialer(socketPath)}
+	conn, _, err := dialer.Dial("ws://hab-daemon/api/websocket", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var msg map[string]interface{}
+	if err := conn.ReadJSON(&msg); err != nil || msg["type"] != "auth_required" {
+		t.Fatalf("handshake: %v, %v", msg, err)
+	}
+	if err := conn.WriteJSON(map[string]interface{}{"type": "auth", "access_token": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.ReadJSON(&msg); err != nil || msg["type"] != "auth_ok" {
+		t.Fatalf("handshake: %v, %v", msg, err)
+	}
+	return conn
+}
+
+func TestDaemonPassesErrorCodesThrough(t *testing.T) {
+	upstream := clienttest.NewServer(nil)
+	defer upstream.Close()
+	socketPath := startDaemon(t, upstream)
+
+	ha := client.NewInstance(context.Background(), BaseURL, "")
+	ha.Dial = Dialer(socketPath)
+	ws, err := ha.WebSocket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	_, err = ws.SendCommand("config/area_registry/delete", map[string]interface{}{"area_id": "nope"})
+	var haErr *client.WSError
+	if !errors.As(err, &haErr) {
+		t.Fatalf("err = %v (%T), want a *client.WSError", err, err)
+	}
+	if haErr.Code != "not_found" {
+		t.Errorf("code = %q, want not_found", haErr.Code)
+	}
+}
+
+func TestDaemonKeepsCommandOrder(t *testing.T) {
+	upstream := clienttest.NewServer(nil)
+	defer upstream.Close()
+
+	var mu sync.Mutex
+	var handled []string
+	record := func(name string, delay time.Duration) clienttest.CommandHandler {
+		return func(params map[string]interface{}) (interface{}, error) {
+			time.Sleep(delay)
+			mu.Lock()
+			handled = append(handled, name)
+			mu.Unlock()
+			return name, nil
+		}
+	}
+	upstream.Handle("test/slow", record("slow", 100*time.Millisecond))
+	upstream.Handle("test/fast", record("fast", 0))
+
+	conn := dialSession(t, startDaemon(t, upstream))
+	if err := conn.WriteJSON(map[string]interface{}{"id": 1, "type": "test/slow"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.WriteJSON(map[string]interface{}{"id": 2, "type": "test/fast"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []float64
+	for i := 0; i < 2; i++ {
+		var msg map[string]interface{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, msg["id"].(float64))
+	}
+	if ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("results arrived for ids %v, want [1 2]", ids)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 2 || handled[0] != "slow" || handled[1] != "fast" {
+		t.Errorf("upstream handled %v, want [slow fast]", handled)
+	}
+}
+
+func TestDaemonRelaysEventsWithoutBlocking(t *testing.T) {
+	upstream := clienttest.NewServer(nil)
+	defer upstream.Close()
+	conn := dialSession(t, startDaemon(t, upstream))
+
+	if err := conn.WriteJSON(map[string]interface{}{"id": 1, "type": "subscribe_events", "event_type": "test_event"}); err != nil {
+		t.Fatal(err)
+	}
+	var msg map[string]interface{}
+	if err := conn.ReadJSON(&msg); err != nil || msg["id"] != 1.0 || msg["success"] != true {
+		t.Fatalf("subscribe result = %v, %v", msg, err)
+	}
+
+	// The session still answers commands while the subscription is open
+	if err := conn.WriteJSON(map[string]interface{}{"id": 2, "type": "config/area_registry/list"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.ReadJSON(&msg); err != nil || msg["id"] != 2.0 || msg["success"] != true {
+		t.Fatalf("list result = %v, %v", msg, err)
+	}
+
+	upstream.FireEvent("test_event", map[string]interface{}{"n": 1})
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.ReadJSON(&msg); err != nil || msg["id"] != 1.0 || msg["type"] != "event" {
+		t.Fatalf("event = %v, %v", msg, err)
+	}
+}
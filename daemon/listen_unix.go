@@ -0,0 +1,17 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"net"
+	"syscall"
+)
+
+// listen creates the socket readable and writable by the owner only. The
+// umask applies while the socket file is created, so there is no moment in
+// which other users could connect.
+func listen(socketPath string) (net.Listener, error) {
+	old := syscall.Umask(0077)
+	defer syscall.Umask(old)
+	return net.Listen("unix", socketPath)
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package daemon
+
+import (
+	"net"
+	"os"
+)
+
+// listen creates the socket readable and writable by the owner only
+func listen(socketPath string) (net.Listener, error) {
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
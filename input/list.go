@@ -0,0 +1,75 @@
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ParseInputList reads a list of items from the same sources as ParseInput.
+// It accepts a JSON array, NDJSON or concatenated JSON objects, and YAML with
+// one or more documents separated by "---". Every document or JSON value
+// may be a single object or a list of objects.
+func ParseInputList(data, file, format string) ([]map[string]interface{}, error) {
+	inputData, source, format, err := readInput(data, file, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []interface{}
+
+	switch format {
+	case "json", "ndjson":
+		decoder := json.NewDecoder(bytes.NewReader(inputData))
+		for {
+			var v interface{}
+			if err := decoder.Decode(&v); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("invalid JSON: %s: value %d: %w", source, len(values)+1, err)
+			}
+			values = append(values, v)
+		}
+	case "yaml":
+		docs, err := resolveYAMLDocuments(inputData, source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		for i, doc := range docs {
+			jsonData, err := yamlToJSON(doc)
+			if err != nil {
+				return nil, fmt.Errorf("invalid YAML: %s: document %d: %w", source, i+1, err)
+			}
+			var v interface{}
+			if err := json.Unmarshal(jsonData, &v); err != nil {
+				return nil, fmt.Errorf("failed to parse YAML: %w", err)
+			}
+			values = append(values, v)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	var items []map[string]interface{}
+	for _, v := range values {
+		list, ok := v.([]interface{})
+		if !ok {
+			list = []interface{}{v}
+		}
+		for _, item := range list {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: item %d is not an object", source, len(items)+1)
+			}
+			items = append(items, m)
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no items in input")
+	}
+	return items, nil
+}
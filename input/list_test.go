@@ -0,0 +1,85 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseInputList(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		format  string
+		want    []string
+		wantErr string
+	}{
+		{name: "JSON object", data: `{"name": "a"}`, want: []string{"a"}},
+		{name: "JSON array", data: `[{"name": "a"}, {"name": "b"}]`, want: []string{"a", "b"}},
+		{name: "NDJSON", data: "{\"name\": \"a\"}\n{\"name\": \"b\"}\n\n{\"name\": \"c\"}\n", want: []string{"a", "b", "c"}},
+		{name: "concatenated JSON", data: `{"name": "a"}{"name": "b"} [{"name": "c"}]`, want: []string{"a", "b", "c"}},
+		{name: "explicit ndjson format", data: "{\"name\": \"a\"}\n{\"name\": \"b\"}", format: "ndjson", want: []string{"a", "b"}},
+		{name: "YAML document", data: "name: a\n", want: []string{"a"}},
+		{name: "YAML list", data: "- name: a\n- name: b\n", want: []string{"a", "b"}},
+		{name: "multi-document YAML", data: "name: a\n---\n- name: b\n- name: c\n---\nname: d\n", want: []string{"a", "b", "c", "d"}},
+		{name: "empty YAML documents", data: "---\nname: a\n---\n---\n# only a comment\n---\nname: b\n", want: []string{"a", "b"}},
+		{name: "YAML with explicit format", data: `{"name": "a"}`, format: "yaml", want: []string{"a"}},
+		{name: "invalid JSON value", data: "{\"name\": \"a\"}\n{\"name\": }\n", wantErr: "invalid JSON: <data>: value 2"},
+		{name: "invalid YAML document", data: "name: a\n---\nname: [b\n", wantErr: "invalid YAML: <data>"},
+		{name: "JSON item that is not an object", data: `[{"name": "a"}, 1]`, wantErr: "<data>: item 2 is not an object"},
+		{name: "YAML item that is not an object", data: "name: a\n---\nb\n", wantErr: "<data>: item 2 is not an object"},
+		{name: "only empty documents", data: "---\n---\n", wantErr: "no items in input"},
+		{name: "empty JSON array", data: "[]", wantErr: "no items in input"},
+		{name: "unsupported format", data: "a", format: "toml", wantErr: "unsupported format: toml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := ParseInputList(tt.data, "", tt.format)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, item := range items {
+				names = append(names, item["name"].(string))
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("items = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInputListFileFormat(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		file string
+		data string
+	}{
+		{file: "items.ndjson", data: "{\"name\": \"a\"}\n{\"name\": \"b\"}\n"},
+		{file: "items.jsonl", data: "{\"name\": \"a\"}\n{\"name\": \"b\"}\n"},
+		{file: "items.yaml", data: "name: a\n---\nname: b\n"},
+		{file: "items.json", data: `[{"name": "a"}, {"name": "b"}]`},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.file)
+		if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		items, err := ParseInputList("", path, "")
+		if err != nil {
+			t.Errorf("%s: %v", tt.file, err)
+			continue
+		}
+		if len(items) != 2 || items[1]["name"] != "b" {
+			t.Errorf("%s: items = %v", tt.file, items)
+		}
+	}
+}
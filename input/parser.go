@@ -6,20 +6,61 @@ import (
 	"io"
 	"os"
 	"strings"
-
-	"github.com/ghodss/yaml"
 )
 
 // ParseInput reads and parses input from various sources
 func ParseInput(data, file, format string) (map[string]interface{}, error) {
+	inputData, source, format, err := readInput(data, file, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+
+	switch format {
+	case "json":
+		if err := json.Unmarshal(inputData, &result); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case "ndjson":
+		// A .ndjson or .jsonl file holding a single object
+		if err := json.Unmarshal(inputData, &result); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %s: expected a single object (use --bulk for several): %w", source, err)
+		}
+	case "yaml":
+		// Resolve Home Assistant tags (!secret, !include, ...) first
+		inputData, err = resolveYAMLTags(inputData, source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		// Convert YAML to JSON, then to map
+		jsonData, err := yamlToJSON(inputData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %s: %w", source, err)
+		}
+		if err := json.Unmarshal(jsonData, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	return result, nil
+}
+
+// readInput reads the input of --data, --file or stdin, renders it with
+// Template if set, and detects its format. It returns the data, a name for the input in error messages, and
+// the format.
+func readInput(data, file, format string) ([]byte, string, string, error) {
 	var inputData []byte
 	var err error
+	source := file
 
 	if file != "" {
 		// Read from file
 		inputData, err = os.ReadFile(file)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read file: %w", err)
+			return nil, "", "", fmt.Errorf("failed to read file: %w", err)
 		}
 		// Auto-detect format from extension
 		if format == "" {
@@ -27,21 +68,33 @@ func ParseInput(data, file, format string) (map[string]interface{}, error) {
 				format = "yaml"
 			} else if strings.HasSuffix(file, ".json") {
 				format = "json"
+			} else if strings.HasSuffix(file, ".ndjson") || strings.HasSuffix(file, ".jsonl") {
+				format = "ndjson"
 			}
 		}
 	} else if data != "" {
 		// Use provided data string
 		inputData = []byte(data)
+		source = "<data>"
 	} else {
 		// Read from stdin
 		inputData, err = io.ReadAll(os.Stdin)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read from stdin: %w", err)
+			return nil, "", "", fmt.Errorf("failed to read from stdin: %w", err)
 		}
+		source = "<stdin>"
 	}
 
 	if len(inputData) == 0 {
-		return nil, fmt.Errorf("no input data provided")
+		return nil, "", "", fmt.Errorf("no input data provided")
+	}
+
+	// Render --var templating before the content is looked at
+	if Template != nil {
+		inputData, err = renderTemplate(inputData, source, Template)
+		if err != nil {
+			return nil, "", "", err
+		}
 	}
 
 	// Auto-detect format if not specified
@@ -54,25 +107,5 @@ func ParseInput(data, file, format string) (map[string]interface{}, error) {
 		}
 	}
 
-	var result map[string]interface{}
-
-	switch format {
-	case "json":
-		if err := json.Unmarshal(inputData, &result); err != nil {
-			return nil, fmt.Errorf("invalid JSON: %w", err)
-		}
-	case "yaml":
-		// Convert YAML to JSON first, then to map
-		jsonData, err := yaml.YAMLToJSON(inputData)
-		if err != nil {
-			return nil, fmt.Errorf("invalid YAML: %w", err)
-		}
-		if err := json.Unmarshal(jsonData, &result); err != nil {
-			return nil, fmt.Errorf("failed to parse YAML: %w", err)
-		}
-	default:
-		return nil, fmt.Errorf("unsupported format: %s", format)
-	}
-
-	return result, nil
+	return inputData, source, format, nil
 }
@@ -0,0 +1,330 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ApplyPatch applies a JSON Patch (RFC 6902) to doc and returns the result.
+// doc itself is not modified, so a failed patch leaves it unchanged. A
+// "test" operation that fails stops the patch, which guards against changes
+// made since doc was read.
+func ApplyPatch(doc interface{}, ops []map[string]interface{}) (interface{}, error) {
+	doc = deepCopy(doc)
+	for i, op := range ops {
+		var err error
+		doc, err = applyOperation(doc, op)
+		if err != nil {
+			name, _ := op["op"].(string)
+			path, _ := op["path"].(string)
+			return nil, fmt.Errorf("patch operation %d (%s %s): %w", i+1, name, path, err)
+		}
+	}
+	return doc, nil
+}
+
+// applyOperation applies one JSON Patch operation to doc
+func applyOperation(doc interface{}, op map[string]interface{}) (interface{}, error) {
+	name, ok := op["op"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing 'op'")
+	}
+	path, ok := op["path"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing 'path'")
+	}
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value, hasValue := op["value"]
+	switch name {
+	case "add", "replace", "test":
+		if !hasValue {
+			return nil, fmt.Errorf("missing 'value'")
+		}
+	case "move", "copy":
+		from, ok := op["from"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing 'from'")
+		}
+		fromTokens, err := parsePointer(from)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = getPointer(doc, fromTokens); err != nil {
+			return nil, err
+		}
+		if name == "move" {
+			if strings.HasPrefix(path+"/", from+"/") && path != from {
+				return nil, fmt.Errorf("cannot move %s into itself", from)
+			}
+			if doc, err = removePointer(doc, fromTokens); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("unknown operation %q", name)
+	}
+
+	switch name {
+	case "add", "move", "copy":
+		return addPointer(doc, tokens, value)
+	case "remove":
+		return removePointer(doc, tokens)
+	case "replace":
+		if _, err := getPointer(doc, tokens); err != nil {
+			return nil, err
+		}
+		if len(tokens) == 0 {
+			return value, nil
+		}
+		if doc, err = removePointer(doc, tokens); err != nil {
+			return nil, err
+		}
+		return addPointer(doc, tokens, value)
+	default: // test
+		current, err := getPointer(doc, tokens)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(current, value) {
+			return nil, fmt.Errorf("test failed: the value is %s", compactJSON(current))
+		}
+		return doc, nil
+	}
+}
+
+// MergePatch applies a JSON merge patch (RFC 7396) to doc and returns the
+// result: objects are merged recursively, null removes a key, and any other
+// value replaces the one in doc. doc itself is not modified.
+func MergePatch(doc, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return deepCopy(patch)
+	}
+	target, ok := doc.(map[string]interface{})
+	if !ok {
+		target = map[string]interface{}{}
+	}
+
+	result := make(map[string]interface{}, len(target))
+	for k, v := range target {
+		result[k] = deepCopy(v)
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(result, k)
+		} else {
+			result[k] = MergePatch(result[k], v)
+		}
+	}
+	return result
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q: must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// getPointer returns the value at tokens in doc
+func getPointer(doc interface{}, tokens []string) (interface{}, error) {
+	for i, token := range tokens {
+		switch v := doc.(type) {
+		case map[string]interface{}:
+			child, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", formatPointer(tokens[:i+1]))
+			}
+			doc = child
+		case []interface{}:
+			index, err := arrayIndex(token, len(v)-1)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", formatPointer(tokens[:i+1]), err)
+			}
+			doc = v[index]
+		default:
+			return nil, fmt.Errorf("%s not found", formatPointer(tokens[:i+1]))
+		}
+	}
+	return doc, nil
+}
+
+// addPointer adds value at tokens in doc: it sets an object member or
+// inserts into an array, where "-" appends
+func addPointer(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := getPointer(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+		return doc, nil
+	case []interface{}:
+		index := len(p)
+		if last != "-" {
+			if index, err = arrayIndex(last, len(p)); err != nil {
+				return nil, fmt.Errorf("%s: %w", formatPointer(tokens), err)
+			}
+		}
+		p = append(p, nil)
+		copy(p[index+1:], p[index:])
+		p[index] = value
+		return setPointer(doc, tokens[:len(tokens)-1], p)
+	default:
+		return nil, fmt.Errorf("%s is not an object or array", formatPointer(tokens[:len(tokens)-1]))
+	}
+}
+
+// removePointer removes the value at tokens from doc
+func removePointer(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	parent, err := getPointer(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := p[last]; !ok {
+			return nil, fmt.Errorf("%s not found", formatPointer(tokens))
+		}
+		delete(p, last)
+		return doc, nil
+	case []interface{}:
+		index, err := arrayIndex(last, len(p)-1)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", formatPointer(tokens), err)
+		}
+		p = append(p[:index:index], p[index+1:]...)
+		return setPointer(doc, tokens[:len(tokens)-1], p)
+	default:
+		return nil, fmt.Errorf("%s not found", formatPointer(tokens))
+	}
+}
+
+// setPointer replaces the existing value at tokens in doc, for arrays whose
+// length changed
+func setPointer(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := getPointer(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+	case []interface{}:
+		index, _ := arrayIndex(last, len(p)-1)
+		p[index] = value
+	}
+	return doc, nil
+}
+
+// arrayIndex parses an array index token, which must be at most max. RFC
+// 6901 allows only digits without leading zeros, so no sign either.
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || strings.TrimLeft(token, "0123456789") != "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index > max {
+		return 0, fmt.Errorf("index %d out of range", index)
+	}
+	return index, nil
+}
+
+func formatPointer(tokens []string) string {
+	escaped := make([]string, len(tokens))
+	for i, t := range tokens {
+		escaped[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1")
+	}
+	return "/" + strings.Join(escaped, "/")
+}
+
+// compactJSON formats a value for an error message
+func compactJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// deepCopy copies the maps and slices of a decoded JSON value
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			c[k] = deepCopy(child)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, child := range v {
+			c[i] = deepCopy(child)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// jsonEqual compares decoded JSON values. Numbers are compared by value,
+// whatever their Go type.
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeNumbers(a), normalizeNumbers(b))
+}
+
+func normalizeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			c[k] = normalizeNumbers(child)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, child := range v {
+			c[i] = normalizeNumbers(child)
+		}
+		return c
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,225 @@
+package input
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decodeJSON decodes a JSON test value
+func decodeJSON(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", s, err)
+	}
+	return v
+}
+
+func TestApplyPatch(t *testing.T) {
+	const doc = `{"name": "Kitchen", "lights": ["a", "b", "c"], "config": {"brightness": 100, "a/b": 1, "m~n": 2}}`
+
+	tests := []struct {
+		name    string
+		patch   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "add member",
+			patch: `[{"op": "add", "path": "/icon", "value": "mdi:lamp"}]`,
+			want:  `{"name": "Kitchen", "icon": "mdi:lamp", "lights": ["a", "b", "c"], "config": {"brightness": 100, "a/b": 1, "m~n": 2}}`,
+		},
+		{
+			name:  "add replaces an existing member",
+			patch: `[{"op": "add", "path": "/name", "value": null}]`,
+			want:  `{"name": null, "lights": ["a", "b", "c"], "config": {"brightness": 100, "a/b": 1, "m~n": 2}}`,
+		},
+		{
+			name:  "add inserts into an array",
+			patch: `[{"op": "add", "path": "/lights/1", "value": "x"}, {"op": "add", "path": "/lights/0", "value": "y"}]`,
+			want:  `{"name": "Kitchen", "lights": ["y", "a", "x", "b", "c"], "config": {"brightness": 100, "a/b": 1, "m~n": 2}}`,
+		},
+		{
+			name:  "add appends with -",
+			patch: `[{"op": "add", "path": "/lights/-", "value": "d"}, {"op": "add", "path": "/lights/4", "value": "e"}]`,
+			want:  `{"name": "Kitchen", "lights": ["a", "b", "c", "d", "e"], "config": {"brightness": 100, "a/b": 1, "m~n": 2}}`,
+		},
+		{
+			name:    "add past the end",
+			patch:   `[{"op": "add", "path": "/lights/4", "value": "x"}]`,
+			wantErr: "/lights/4: index 4 out of range",
+		},
+		{
+			name:    "add with a leading zero",
+			patch:   `[{"op": "add", "path": "/lights/01", "value": "x"}]`,
+			wantErr: `invalid array index "01"`,
+		},
+		{
+			name:    "add with a sign",
+			patch:   `[{"op": "add", "path": "/lights/+1", "value": "x"}]`,
+			wantErr: `invalid array index "+1"`,
+		},
+		{
+			name:    "add to a missing parent",
+			patch:   `[{"op": "add", "path": "/missing/key", "value": 1}]`,
+			wantErr: "/missing not found",
+		},
+		{
+			name:    "add below a scalar",
+			patch:   `[{"op": "add", "path": "/name/key", "value": 1}]`,
+			wantErr: "/name is not an object or array",
+		},
+		{
+			name:  "escaped pointers",
+			patch: `[{"op": "replace", "path": "/config/a~1b", "value": 10}, {"op": "remove", "path": "/config/m~0n"}]`,
+			want:  `{"name": "Kitchen", "lights": ["a", "b", "c"], "config": {"brightness": 100, "a/b": 10}}`,
+		},
+		{
+			name:  "remove",
+			patch: `[{"op": "remove", "path": "/lights/0"}, {"op": "remove", "path": "/config"}]`,
+			want:  `{"name": "Kitchen", "lights": ["b", "c"]}`,
+		},
+		{
+			name:    "remove a missing member",
+			patch:   `[{"op": "remove", "path": "/icon"}]`,
+			wantErr: "/icon not found",
+		},
+		{
+			name:    "remove with -",
+			patch:   `[{"op": "remove", "path": "/lights/-"}]`,
+			wantErr: `invalid array index "-"`,
+		},
+		{
+			name:    "remove the document",
+			patch:   `[{"op": "remove", "path": ""}]`,
+			wantErr: "cannot remove the whole document",
+		},
+		{
+			name:  "replace",
+			patch: `[{"op": "replace", "path": "/lights/2", "value": "z"}, {"op": "replace", "path": "/config/brightness", "value": 50}]`,
+			want:  `{"name": "Kitchen", "lights": ["a", "b", "z"], "config": {"brightness": 50, "a/b": 1, "m~n": 2}}`,
+		},
+		{
+			name:  "replace the document",
+			patch: `[{"op": "replace", "path": "", "value": [1]}]`,
+			want:  `[1]`,
+		},
+		{
+			name:    "replace a missing member",
+			patch:   `[{"op": "replace", "path": "/icon", "value": "x"}]`,
+			wantErr: "/icon not found",
+		},
+		{
+			name:  "move within an array",
+			patch: `[{"op": "move", "from": "/lights/0", "path": "/lights/2"}]`,
+			want:  `{"name": "Kitchen", "lights": ["b", "c", "a"], "config": {"brightness": 100, "a/b": 1, "m~n": 2}}`,
+		},
+		{
+			name:  "move to the same path",
+			patch: `[{"op": "move", "from": "/config", "path": "/config"}]`,
+			want:  doc,
+		},
+		{
+			name:  "move to a sibling with a common prefix",
+			patch: `[{"op": "move", "from": "/config", "path": "/configuration"}]`,
+			want:  `{"name": "Kitchen", "lights": ["a", "b", "c"], "configuration": {"brightness": 100, "a/b": 1, "m~n": 2}}`,
+		},
+		{
+			name:    "move into its own child",
+			patch:   `[{"op": "move", "from": "/config", "path": "/config/nested"}]`,
+			wantErr: "cannot move /config into itself",
+		},
+		{
+			name:    "move a missing value",
+			patch:   `[{"op": "move", "from": "/icon", "path": "/name"}]`,
+			wantErr: "/icon not found",
+		},
+		{
+			name:  "copy",
+			patch: `[{"op": "copy", "from": "/config", "path": "/copy"}, {"op": "replace", "path": "/copy/brightness", "value": 1}]`,
+			want:  `{"name": "Kitchen", "lights": ["a", "b", "c"], "config": {"brightness": 100, "a/b": 1, "m~n": 2}, "copy": {"brightness": 1, "a/b": 1, "m~n": 2}}`,
+		},
+		{
+			name:  "test",
+			patch: `[{"op": "test", "path": "/config/brightness", "value": 100}, {"op": "test", "path": "/lights", "value": ["a", "b", "c"]}]`,
+			want:  doc,
+		},
+		{
+			name:    "failed test",
+			patch:   `[{"op": "test", "path": "/name", "value": "Hall"}, {"op": "remove", "path": "/name"}]`,
+			wantErr: `patch operation 1 (test /name): test failed: the value is "Kitchen"`,
+		},
+		{
+			name:    "unknown operation",
+			patch:   `[{"op": "merge", "path": "/name"}]`,
+			wantErr: `unknown operation "merge"`,
+		},
+		{
+			name:    "missing value",
+			patch:   `[{"op": "add", "path": "/name"}]`,
+			wantErr: "missing 'value'",
+		},
+		{
+			name:    "missing from",
+			patch:   `[{"op": "copy", "path": "/name"}]`,
+			wantErr: "missing 'from'",
+		},
+		{
+			name:    "relative path",
+			patch:   `[{"op": "remove", "path": "name"}]`,
+			wantErr: "must start with '/'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := decodeJSON(t, doc)
+			var ops []map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ApplyPatch(original, ops)
+			if !reflect.DeepEqual(original, decodeJSON(t, doc)) {
+				t.Errorf("the document was modified: %v", original)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := decodeJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %s, want %s", compactJSON(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{doc: `{"a": 1, "b": 2}`, patch: `{"b": 3, "c": 4}`, want: `{"a": 1, "b": 3, "c": 4}`},
+		{doc: `{"a": 1, "b": 2}`, patch: `{"a": null}`, want: `{"b": 2}`},
+		{doc: `{"a": {"x": 1, "y": 2}}`, patch: `{"a": {"y": null, "z": 3}}`, want: `{"a": {"x": 1, "z": 3}}`},
+		{doc: `{"a": [1, 2]}`, patch: `{"a": [3]}`, want: `{"a": [3]}`},
+		{doc: `{"a": "x"}`, patch: `{"a": {"b": 1}}`, want: `{"a": {"b": 1}}`},
+		{doc: `[1, 2]`, patch: `{"a": 1}`, want: `{"a": 1}`},
+		{doc: `{"a": 1}`, patch: `[1]`, want: `[1]`},
+	}
+	for _, tt := range tests {
+		doc := decodeJSON(t, tt.doc)
+		got := MergePatch(doc, decodeJSON(t, tt.patch))
+		if want := decodeJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+			t.Errorf("MergePatch(%s, %s) = %s, want %s", tt.doc, tt.patch, compactJSON(got), tt.want)
+		}
+		if !reflect.DeepEqual(doc, decodeJSON(t, tt.doc)) {
+			t.Errorf("MergePatch(%s, %s) modified the document", tt.doc, tt.patch)
+		}
+	}
+}
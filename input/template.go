@@ -0,0 +1,107 @@
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TemplateLeftDelim and TemplateRightDelim enclose template actions. They
+// differ from the Go defaults so the {{ }} of Home Assistant's Jinja
+// templates pass through unchanged, and cannot occur in JSON or YAML
+// outside of strings, unlike nested lists such as [[1, 2]]. A literal <%
+// in a string is written <% "<%" %>.
+const (
+	TemplateLeftDelim  = "<%"
+	TemplateRightDelim = "%>"
+)
+
+// TemplateOptions configures the rendering of input as a text/template
+type TemplateOptions struct {
+	// Vars is the data of the template, available as <% .name %>
+	Vars map[string]interface{}
+	// Funcs are added to the built-in helpers
+	Funcs template.FuncMap
+}
+
+// Template, when set, renders all input as a template before it is parsed
+var Template *TemplateOptions
+
+// renderTemplate renders data from source with opts. Errors name the
+// template line, and the column for execution errors.
+func renderTemplate(data []byte, source string, opts *TemplateOptions) ([]byte, error) {
+	tmpl := template.New(source).
+		Delims(TemplateLeftDelim, TemplateRightDelim).
+		Option("missingkey=error").
+		Funcs(templateFuncs())
+	if opts.Funcs != nil {
+		tmpl = tmpl.Funcs(opts.Funcs)
+	}
+
+	tmpl, err := tmpl.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %s", strings.TrimPrefix(err.Error(), "template: "))
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, opts.Vars); err != nil {
+		return nil, fmt.Errorf("failed to render template: %s", strings.TrimPrefix(err.Error(), "template: "))
+	}
+	return out.Bytes(), nil
+}
+
+// templateFuncs returns the built-in template helpers
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"slugify": Slugify,
+		"lower":   strings.ToLower,
+		"upper":   strings.ToUpper,
+		"trim":    strings.TrimSpace,
+		"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"join": func(sep string, list []interface{}) string {
+			parts := make([]string, len(list))
+			for i, v := range list {
+				parts[i] = fmt.Sprint(v)
+			}
+			return strings.Join(parts, sep)
+		},
+		// default returns def when value is empty: <% .icon | default "mdi:home" %>
+		"default": func(def, value interface{}) interface{} {
+			if value == nil || value == "" {
+				return def
+			}
+			return value
+		},
+		// quote returns a value as a JSON literal, which is also valid YAML
+		"quote": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}
+}
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify turns text into an ID the way Home Assistant does: lowercase
+// ASCII letters and digits separated by single underscores
+func Slugify(text string) string {
+	// Drop accents: decompose, then remove the combining marks
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(text) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	slug := slugSeparators.ReplaceAllString(strings.ToLower(b.String()), "_")
+	slug = strings.Trim(slug, "_")
+	if slug == "" {
+		return "unknown"
+	}
+	return slug
+}
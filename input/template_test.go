@@ -0,0 +1,57 @@
+package input
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	vars := map[string]interface{}{"room": "Living Room", "icon": ""}
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr string
+	}{
+		{name: "variable", data: `alias: <% .room %> light`, want: `alias: Living Room light`},
+		{name: "functions", data: `id: <% slugify .room %>, icon: <% .icon | default "mdi:home" %>`, want: `id: living_room, icon: mdi:home`},
+		{name: "quote", data: `{"alias": <% quote .room %>}`, want: `{"alias": "Living Room"}`},
+		{name: "nested JSON arrays", data: `{"x": [[1,2]], "y": [[[3]]], "room": "<% .room %>"}`, want: `{"x": [[1,2]], "y": [[[3]]], "room": "Living Room"}`},
+		{name: "nested flow sequence", data: "pairs: [[a, b], [c, d]]\nlast: [[e]]", want: "pairs: [[a, b], [c, d]]\nlast: [[e]]"},
+		{name: "jinja", data: `value_template: "{{ states('sensor.t') }} {% if x %}y{% endif %}"`, want: `value_template: "{{ states('sensor.t') }} {% if x %}y{% endif %}"`},
+		{name: "literal delimiter", data: `note: <% "<%" %> kept`, want: `note: <% kept`},
+		{name: "missing variable", data: `<% .nope %>`, wantErr: `map has no entry for key "nope"`},
+		{name: "unclosed action", data: "a: 1\nb: <% .room", wantErr: "invalid template: input:2: unclosed action"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderTemplate([]byte(tt.data), "input", &TemplateOptions{Vars: vars})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Living Room":     "living_room",
+		"  Café--Ñandú! ": "cafe_nandu",
+		"Ground Floor 2":  "ground_floor_2",
+		"!!!":             "unknown",
+	}
+	for text, want := range tests {
+		if got := Slugify(text); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package input
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// SecretsFile is the secrets.yaml used for !secret. When empty, the nearest
+// secrets.yaml in the directory of the input file or one of its parents is
+// used, stopping at the Home Assistant config directory (the one holding
+// configuration.yaml).
+var SecretsFile string
+
+const secretsFileName = "secrets.yaml"
+
+// tagResolver resolves the Home Assistant YAML tags of one input
+type tagResolver struct {
+	// including is the stack of files being resolved, to detect cycles
+	including []string
+	// secrets caches the loaded secrets files by path
+	secrets map[string]map[string]*yamlv3.Node
+	// changed is set once a tag was replaced
+	changed bool
+}
+
+// resolveYAMLTags parses YAML from source and replaces !secret, !include,
+// !include_dir_* and !env_var with their values. !input tags are kept intact
+// for blueprints. The result is YAML again, so the usual YAML 1.1 scalar
+// rules of Home Assistant apply when it is parsed. source is the file name,
+// or a label for inline data and stdin.
+func resolveYAMLTags(data []byte, source string) ([]byte, error) {
+	if !bytes.Contains(data, []byte("!")) {
+		return data, nil
+	}
+	r := &tagResolver{secrets: make(map[string]map[string]*yamlv3.Node)}
+	node, err := r.parse(data, source)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil || !r.changed {
+		return data, nil
+	}
+	out, err := yamlv3.Marshal(node)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	return out, nil
+}
+
+// resolveYAMLDocuments splits a multi-document YAML stream and resolves the
+// tags of each document like resolveYAMLTags. Empty documents are skipped.
+func resolveYAMLDocuments(data []byte, source string) ([][]byte, error) {
+	r := &tagResolver{secrets: make(map[string]map[string]*yamlv3.Node)}
+	r.including = append(r.including, source)
+
+	var docs [][]byte
+	decoder := yamlv3.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yamlv3.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, fmt.Errorf("%s: %s", source, strings.TrimPrefix(err.Error(), "yaml: "))
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		node := doc.Content[0]
+		if node.Tag == "!!null" {
+			continue
+		}
+		if err := r.resolve(node, source); err != nil {
+			return nil, err
+		}
+		out, err := yamlv3.Marshal(node)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", source, node.Line, err)
+		}
+		docs = append(docs, out)
+	}
+}
+
+// parse parses data from source and resolves its tags. An empty document gives nil.
+func (r *tagResolver) parse(data []byte, source string) (*yamlv3.Node, error) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %s", source, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	node := doc.Content[0]
+
+	r.including = append(r.including, source)
+	defer func() { r.including = r.including[:len(r.including)-1] }()
+
+	if err := r.resolve(node, source); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// resolve replaces tagged nodes below and including n
+func (r *tagResolver) resolve(n *yamlv3.Node, source string) error {
+	switch n.Kind {
+	case yamlv3.DocumentNode, yamlv3.SequenceNode, yamlv3.MappingNode:
+		for _, child := range n.Content {
+			if err := r.resolve(child, source); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yamlv3.ScalarNode:
+	default:
+		// Aliases share the node of their anchor, which is resolved in place
+		return nil
+	}
+
+	// Standard tags (!!str, !!int, ...) and untagged scalars need nothing
+	if !strings.HasPrefix(n.Tag, "!") || strings.HasPrefix(n.Tag, "!!") {
+		return nil
+	}
+
+	// Blueprint inputs are substituted by Home Assistant, keep their tags
+	if n.Tag == "!input" {
+		return nil
+	}
+
+	var (
+		resolved *yamlv3.Node
+		err      error
+	)
+	switch n.Tag {
+	case "!secret":
+		resolved, err = r.secret(n.Value, source)
+	case "!env_var":
+		resolved, err = envVar(n.Value)
+	case "!include":
+		resolved, err = r.include(n.Value, source)
+	case "!include_dir_list", "!include_dir_named", "!include_dir_merge_list", "!include_dir_merge_named":
+		resolved, err = r.includeDir(n.Tag, n.Value, source)
+	default:
+		err = fmt.Errorf("unknown tag %s", n.Tag)
+	}
+	if err != nil {
+		return fmt.Errorf("%s:%d: %w", source, n.Line, err)
+	}
+
+	anchor := n.Anchor
+	*n = *resolved
+	n.Anchor = anchor
+	r.changed = true
+	return nil
+}
+
+// yamlToJSON converts YAML with resolved tags to JSON. JSON has no tags, so
+// an !input tag becomes the string "!input name" there.
+func yamlToJSON(data []byte) ([]byte, error) {
+	if bytes.Contains(data, []byte("!input")) {
+		var doc yamlv3.Node
+		if err := yamlv3.Unmarshal(data, &doc); err != nil {
+			return nil, errors.New(strings.TrimPrefix(err.Error(), "yaml: "))
+		}
+		if inputTagsToStrings(&doc) {
+			out, err := yamlv3.Marshal(&doc)
+			if err != nil {
+				return nil, err
+			}
+			data = out
+		}
+	}
+	return yaml.YAMLToJSON(data)
+}
+
+// inputTagsToStrings replaces the !input scalars below n with strings and
+// reports whether there were any
+func inputTagsToStrings(n *yamlv3.Node) bool {
+	if n.Kind == yamlv3.ScalarNode && n.Tag == "!input" {
+		*n = *stringNode("!input " + n.Value)
+		return true
+	}
+	found := false
+	for _, child := range n.Content {
+		if inputTagsToStrings(child) {
+			found = true
+		}
+	}
+	return found
+}
+
+// stringNode returns a scalar that always reads back as a string
+func stringNode(value string) *yamlv3.Node {
+	return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: value, Style: yamlv3.DoubleQuotedStyle}
+}
+
+// nullNode returns the value of an empty included file
+func nullNode() *yamlv3.Node {
+	return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null", Value: "null"}
+}
+
+// envVar resolves "!env_var NAME [default]"
+func envVar(value string) (*yamlv3.Node, error) {
+	name, def, hasDefault := strings.Cut(strings.TrimSpace(value), " ")
+	if v, ok := os.LookupEnv(name); ok {
+		return stringNode(v), nil
+	}
+	if hasDefault {
+		return stringNode(strings.TrimSpace(def)), nil
+	}
+	return nil, fmt.Errorf("environment variable %s is not set", name)
+}
+
+// secret resolves "!secret name" from SecretsFile, or the secrets.yaml
+// nearest to source
+func (r *tagResolver) secret(name, source string) (*yamlv3.Node, error) {
+	path := SecretsFile
+	if path == "" {
+		path = findSecretsFile(baseDir(source))
+		if path == "" {
+			return nil, fmt.Errorf("no %s found for secret %s (use --secrets to point to one)", secretsFileName, name)
+		}
+	}
+
+	secrets, ok := r.secrets[path]
+	if !ok {
+		var err error
+		if secrets, err = loadSecrets(path); err != nil {
+			return nil, err
+		}
+		r.secrets[path] = secrets
+	}
+
+	value, ok := secrets[name]
+	if !ok {
+		return nil, fmt.Errorf("secret %s not found in %s", name, path)
+	}
+	return value, nil
+}
+
+// loadSecrets reads a secrets file
+func loadSecrets(path string) (map[string]*yamlv3.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+
+	secrets := make(map[string]*yamlv3.Node)
+	if len(doc.Content) == 0 {
+		return secrets, nil
+	}
+	m := doc.Content[0]
+	if m.Kind != yamlv3.MappingNode {
+		return nil, fmt.Errorf("%s: secrets must be a mapping", path)
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		secrets[m.Content[i].Value] = m.Content[i+1]
+	}
+	return secrets, nil
+}
+
+// findSecretsFile returns the nearest secrets.yaml in dir or its parents
+func findSecretsFile(dir string) string {
+	for {
+		path := filepath.Join(dir, secretsFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		// Home Assistant does not look above its config directory
+		if _, err := os.Stat(filepath.Join(dir, "configuration.yaml")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// baseDir returns the directory relative paths in source are resolved against:
+// the directory of a file, or the working directory for inline data and stdin
+func baseDir(source string) string {
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		dir, _ := filepath.Abs(filepath.Dir(source))
+		return dir
+	}
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// includePath returns the path named by an include tag in source
+func includePath(name, source string) string {
+	name = strings.TrimSpace(name)
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(baseDir(source), name)
+}
+
+// include resolves "!include file.yaml"
+func (r *tagResolver) include(name, source string) (*yamlv3.Node, error) {
+	path := includePath(name, source)
+	for _, f := range r.including {
+		if abs, _ := filepath.Abs(f); abs == path {
+			return nil, fmt.Errorf("include cycle through %s", name)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to include %s: %w", name, err)
+	}
+	node, err := r.parse(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return nullNode(), nil
+	}
+	return node, nil
+}
+
+// includeDir resolves the !include_dir_* tags, which read every .yaml file
+// below a directory in name order
+func (r *tagResolver) includeDir(tag, name, source string) (*yamlv3.Node, error) {
+	dir := includePath(name, source)
+	files, err := yamlFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to include %s: %w", name, err)
+	}
+
+	merged := &yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map"}
+	if tag == "!include_dir_list" || tag == "!include_dir_merge_list" {
+		merged = &yamlv3.Node{Kind: yamlv3.SequenceNode, Tag: "!!seq"}
+	}
+
+	for _, file := range files {
+		node, err := r.include(file, source)
+		if err != nil {
+			return nil, err
+		}
+		if node.Tag == "!!null" {
+			continue
+		}
+
+		switch tag {
+		case "!include_dir_list":
+			merged.Content = append(merged.Content, node)
+		case "!include_dir_named":
+			key := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			merged.Content = append(merged.Content, stringNode(key), node)
+		case "!include_dir_merge_list":
+			if node.Kind != yamlv3.SequenceNode {
+				return nil, fmt.Errorf("%s: expected a list for %s", file, tag)
+			}
+			merged.Content = append(merged.Content, node.Content...)
+		case "!include_dir_merge_named":
+			if node.Kind != yamlv3.MappingNode {
+				return nil, fmt.Errorf("%s: expected a mapping for %s", file, tag)
+			}
+			merged.Content = append(merged.Content, node.Content...)
+		}
+	}
+	return merged, nil
+}
+
+// yamlFiles returns the .yaml files below dir, skipping hidden files and
+// directories and secrets.yaml as Home Assistant does
+func yamlFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".yaml") && d.Name() != secretsFileName {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
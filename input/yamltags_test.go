@@ -0,0 +1,262 @@
+package input
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// findNode returns the value of key in the mapping m, following a path of keys
+func findNode(t *testing.T, m *yamlv3.Node, keys ...string) *yamlv3.Node {
+	t.Helper()
+	for _, key := range keys {
+		var next *yamlv3.Node
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			if m.Content[i].Value == key {
+				next = m.Content[i+1]
+			}
+		}
+		if next == nil {
+			t.Fatalf("key %s not found", key)
+		}
+		m = next
+	}
+	return m
+}
+
+func TestResolveYAMLTagsKeepsInputTags(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "secrets.yaml"), []byte("notify_target: mobile_app_phone\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "blueprint.yaml")
+	blueprint := `blueprint:
+  name: Motion light
+  domain: automation
+  input:
+    motion_entity:
+      name: Motion sensor
+triggers:
+  - trigger: state
+    entity_id: !input motion_entity
+actions:
+  - action: !secret notify_target
+`
+	if err := os.WriteFile(file, []byte(blueprint), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// !secret forces the document to be encoded again
+	out, err := resolveYAMLTags([]byte(blueprint), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid YAML %s: %v", out, err)
+	}
+	root := doc.Content[0]
+
+	entity := findNode(t, root, "triggers").Content[0]
+	entity = findNode(t, entity, "entity_id")
+	if entity.Tag != "!input" || entity.Value != "motion_entity" {
+		t.Errorf("entity_id = %s %q, want !input motion_entity\n%s", entity.Tag, entity.Value, out)
+	}
+	action := findNode(t, findNode(t, root, "actions").Content[0], "action")
+	if action.Value != "mobile_app_phone" {
+		t.Errorf("action = %q, want the secret", action.Value)
+	}
+
+	// JSON has no tags, so the input becomes a string there
+	config, err := ParseInput("", file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trigger := config["triggers"].([]interface{})[0].(map[string]interface{})
+	if trigger["entity_id"] != "!input motion_entity" {
+		t.Errorf("entity_id = %v, want the string !input motion_entity", trigger["entity_id"])
+	}
+}
+
+func TestResolveYAMLTags(t *testing.T) {
+	t.Setenv("HAB_TEST_VAR", "from env")
+
+	tests := []struct {
+		name string
+		// files are written below a temporary directory; main.yaml is resolved
+		files   map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "secret",
+			files: map[string]string{
+				"secrets.yaml": "password: hunter2\nport: 8123\n",
+				"main.yaml":    "password: !secret password\nport: !secret port\n",
+			},
+			want: `{"password": "hunter2", "port": 8123}`,
+		},
+		{
+			name: "secret in a parent directory",
+			files: map[string]string{
+				"secrets.yaml":    "password: hunter2\n",
+				"main.yaml":       "sub: !include sub/config.yaml\n",
+				"sub/config.yaml": "password: !secret password\n",
+			},
+			want: `{"sub": {"password": "hunter2"}}`,
+		},
+		{
+			name: "missing secret",
+			files: map[string]string{
+				"secrets.yaml": "password: hunter2\n",
+				"main.yaml":    "name: x\ntoken: !secret token\n",
+			},
+			wantErr: "main.yaml:2: secret token not found",
+		},
+		{
+			name: "no secrets file",
+			files: map[string]string{
+				"configuration.yaml": "",
+				"main.yaml":          "token: !secret token\n",
+			},
+			wantErr: "main.yaml:1: no secrets.yaml found for secret token",
+		},
+		{
+			name: "include",
+			files: map[string]string{
+				"main.yaml":  "light: !include light.yaml\nempty: !include empty.yaml\n",
+				"light.yaml": "- platform: group\n  name: !env_var HAB_TEST_VAR\n",
+				"empty.yaml": "",
+			},
+			want: `{"light": [{"platform": "group", "name": "from env"}], "empty": null}`,
+		},
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"main.yaml": "a: !include a.yaml\n",
+				"a.yaml":    "b: !include b.yaml\n",
+				"b.yaml":    "main: !include main.yaml\n",
+			},
+			wantErr: "b.yaml:1: include cycle through main.yaml",
+		},
+		{
+			name: "include of itself",
+			files: map[string]string{
+				"main.yaml": "self: !include main.yaml\n",
+			},
+			wantErr: "main.yaml:1: include cycle through main.yaml",
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				"main.yaml": "a: 1\n\nb: !include missing.yaml\n",
+			},
+			wantErr: "main.yaml:3: failed to include missing.yaml",
+		},
+		{
+			name: "include_dir_list and include_dir_named",
+			files: map[string]string{
+				"main.yaml":          "list: !include_dir_list items\nnamed: !include_dir_named items\n",
+				"items/b.yaml":       "2\n",
+				"items/a.yaml":       "1\n",
+				"items/.hidden.yaml": "3\n",
+				"items/empty.yaml":   "",
+			},
+			want: `{"list": [1, 2], "named": {"a": 1, "b": 2}}`,
+		},
+		{
+			name: "include_dir_merge_list and include_dir_merge_named",
+			files: map[string]string{
+				"main.yaml":         "list: !include_dir_merge_list lists\nnamed: !include_dir_merge_named maps\n",
+				"lists/a.yaml":      "- 1\n- 2\n",
+				"lists/sub/b.yaml":  "- 3\n",
+				"maps/a.yaml":       "one: 1\n",
+				"maps/b.yaml":       "two: 2\n",
+				"maps/secrets.yaml": "three: 3\n",
+			},
+			want: `{"list": [1, 2, 3], "named": {"one": 1, "two": 2}}`,
+		},
+		{
+			name: "include_dir_merge_list of a mapping",
+			files: map[string]string{
+				"main.yaml":    "list: !include_dir_merge_list lists\n",
+				"lists/a.yaml": "x: 1\n",
+			},
+			wantErr: "expected a list for !include_dir_merge_list",
+		},
+		{
+			name: "env_var default",
+			files: map[string]string{
+				"main.yaml": "set: !env_var HAB_TEST_VAR fallback\nunset: !env_var HAB_TEST_UNSET fallback value\n",
+			},
+			want: `{"set": "from env", "unset": "fallback value"}`,
+		},
+		{
+			name: "env_var unset",
+			files: map[string]string{
+				"main.yaml": "unset: !env_var HAB_TEST_UNSET\n",
+			},
+			wantErr: "main.yaml:1: environment variable HAB_TEST_UNSET is not set",
+		},
+		{
+			name: "unknown tag",
+			files: map[string]string{
+				"main.yaml": "a: 1\nb: !lambda x\n",
+			},
+			wantErr: "main.yaml:2: unknown tag !lambda",
+		},
+		{
+			name: "anchors and standard tags",
+			files: map[string]string{
+				"secrets.yaml": "password: hunter2\n",
+				"main.yaml":    "a: &pw !secret password\nb: *pw\nc: !!str 123\n",
+			},
+			want: `{"a": "hunter2", "b": "hunter2", "c": "123"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			file := filepath.Join(dir, "main.yaml")
+
+			out, err := resolveYAMLTags([]byte(tt.files["main.yaml"]), file)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := yamlToJSON(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
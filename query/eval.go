@@ -0,0 +1,498 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// Eval evaluates the query against decoded JSON data and returns the
+// selected values, in document order. Keys that are not there select
+// nothing rather than failing.
+func (q *Query) Eval(data interface{}) []interface{} {
+	nodes := []interface{}{data}
+	for _, s := range q.stages {
+		var next []interface{}
+		for _, n := range nodes {
+			next = s.apply(data, n, next)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// Apply evaluates the query against any value that encodes to JSON. A
+// singular query returns the value it selects, or nil; any other query
+// returns the list of selected values.
+func (q *Query) Apply(data interface{}) (interface{}, error) {
+	decoded, err := normalize(data)
+	if err != nil {
+		return nil, err
+	}
+	results := q.Eval(decoded)
+	if q.Singular() {
+		if len(results) == 0 {
+			return nil, nil
+		}
+		return results[0], nil
+	}
+	if results == nil {
+		results = []interface{}{}
+	}
+	return results, nil
+}
+
+// normalize converts structs and typed maps and slices to the generic form
+// the query works on, keeping numbers as they were written
+func normalize(data interface{}) (interface{}, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := decodeJSON(b, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func decodeJSON(b []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+type stage interface {
+	apply(root, node interface{}, out []interface{}) []interface{}
+	singular() bool
+}
+
+type segment interface {
+	apply(root, node interface{}, out []interface{}) []interface{}
+	singular() bool
+}
+
+// path is a sequence of segments, starting at the root or the current value
+type path struct {
+	fromRoot bool
+	segments []segment
+}
+
+func (p *path) apply(root, node interface{}, out []interface{}) []interface{} {
+	if p.fromRoot {
+		node = root
+	}
+	nodes := []interface{}{node}
+	for _, seg := range p.segments {
+		var next []interface{}
+		for _, n := range nodes {
+			next = seg.apply(root, n, next)
+		}
+		nodes = next
+	}
+	return append(out, nodes...)
+}
+
+func (p *path) singular() bool {
+	for _, seg := range p.segments {
+		if !seg.singular() {
+			return false
+		}
+	}
+	return true
+}
+
+// childSegment selects object members by name
+type childSegment struct {
+	names []string
+}
+
+func (s childSegment) apply(_, node interface{}, out []interface{}) []interface{} {
+	if m, ok := node.(map[string]interface{}); ok {
+		for _, name := range s.names {
+			if v, ok := m[name]; ok {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+func (s childSegment) singular() bool { return len(s.names) == 1 }
+
+// indexSegment selects array elements; negative indexes count from the end
+type indexSegment struct {
+	indexes []int
+}
+
+func (s indexSegment) apply(_, node interface{}, out []interface{}) []interface{} {
+	if a, ok := node.([]interface{}); ok {
+		for _, i := range s.indexes {
+			if i < 0 {
+				i += len(a)
+			}
+			if i >= 0 && i < len(a) {
+				out = append(out, a[i])
+			}
+		}
+	}
+	return out
+}
+
+func (s indexSegment) singular() bool { return len(s.indexes) == 1 }
+
+// sliceSegment selects array elements from start up to end, by step, as in
+// Python
+type sliceSegment struct {
+	start, end *int
+	step       int
+}
+
+func (s sliceSegment) apply(_, node interface{}, out []interface{}) []interface{} {
+	a, ok := node.([]interface{})
+	if !ok {
+		return out
+	}
+	step := s.step
+	if step == 0 {
+		step = 1
+	}
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += len(a)
+		}
+		if step > 0 {
+			return clamp(i, 0, len(a))
+		}
+		return clamp(i, -1, len(a)-1)
+	}
+	if step > 0 {
+		for i := bound(s.start, 0); i < bound(s.end, len(a)); i += step {
+			out = append(out, a[i])
+		}
+	} else {
+		for i := bound(s.start, len(a)-1); i > bound(s.end, -1); i += step {
+			out = append(out, a[i])
+		}
+	}
+	return out
+}
+
+func (s sliceSegment) singular() bool { return false }
+
+func clamp(i, lo, hi int) int {
+	if i < lo {
+		return lo
+	}
+	if i > hi {
+		return hi
+	}
+	return i
+}
+
+// wildcardSegment selects all array elements or object values
+type wildcardSegment struct{}
+
+func (wildcardSegment) apply(_, node interface{}, out []interface{}) []interface{} {
+	return append(out, children(node)...)
+}
+
+func (wildcardSegment) singular() bool { return false }
+
+// descendantSegment selects the value and everything below it, for ..
+type descendantSegment struct{}
+
+func (descendantSegment) apply(_, node interface{}, out []interface{}) []interface{} {
+	out = append(out, node)
+	for _, c := range children(node) {
+		out = descendantSegment{}.apply(nil, c, out)
+	}
+	return out
+}
+
+func (descendantSegment) singular() bool { return false }
+
+// filterSegment selects the array elements or object values for which the
+// expression is true
+type filterSegment struct {
+	expr expr
+}
+
+func (s filterSegment) apply(root, node interface{}, out []interface{}) []interface{} {
+	for _, c := range children(node) {
+		if s.expr.test(root, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (filterSegment) singular() bool { return false }
+
+// children returns the elements of an array or the values of an object,
+// ordered by key
+func children(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			values[i] = v[k]
+		}
+		return values
+	}
+	return nil
+}
+
+// selectStage keeps the values for which the expression is true, as jq's
+// select()
+type selectStage struct {
+	expr expr
+}
+
+func (s *selectStage) apply(root, node interface{}, out []interface{}) []interface{} {
+	if s.expr.test(root, node) {
+		out = append(out, node)
+	}
+	return out
+}
+
+func (*selectStage) singular() bool { return false }
+
+// projectStage builds an object from paths relative to each value, as jq's
+// {a, b: .c}. Paths that select nothing give null.
+type projectStage struct {
+	fields []projectField
+}
+
+type projectField struct {
+	key  string
+	path *path
+}
+
+func (s *projectStage) apply(root, node interface{}, out []interface{}) []interface{} {
+	obj := make(map[string]interface{}, len(s.fields))
+	for _, f := range s.fields {
+		values := f.path.apply(root, node, nil)
+		switch {
+		case len(values) == 0:
+			obj[f.key] = nil
+		case f.path.singular():
+			obj[f.key] = values[0]
+		default:
+			obj[f.key] = values
+		}
+	}
+	return append(out, obj)
+}
+
+func (*projectStage) singular() bool { return true }
+
+// expr is a filter expression. values returns what an operand evaluates to;
+// test whether the expression holds.
+type expr interface {
+	values(root, node interface{}) []interface{}
+	test(root, node interface{}) bool
+}
+
+type literal struct {
+	value interface{}
+}
+
+func (l literal) values(_, _ interface{}) []interface{} { return []interface{}{l.value} }
+func (l literal) test(_, _ interface{}) bool            { return truthy(l.value) }
+
+// pathExpr is a path used as an operand. On its own it tests that the path
+// exists and is not null or false.
+type pathExpr struct {
+	path *path
+}
+
+func (e pathExpr) values(root, node interface{}) []interface{} {
+	return e.path.apply(root, node, nil)
+}
+
+func (e pathExpr) test(root, node interface{}) bool {
+	for _, v := range e.values(root, node) {
+		if truthy(v) {
+			return true
+		}
+	}
+	return false
+}
+
+type notExpr struct{ e expr }
+
+func (e notExpr) values(root, node interface{}) []interface{} {
+	return []interface{}{e.test(root, node)}
+}
+func (e notExpr) test(root, node interface{}) bool { return !e.e.test(root, node) }
+
+type andExpr struct{ left, right expr }
+
+func (e andExpr) values(root, node interface{}) []interface{} {
+	return []interface{}{e.test(root, node)}
+}
+func (e andExpr) test(root, node interface{}) bool {
+	return e.left.test(root, node) && e.right.test(root, node)
+}
+
+type orExpr struct{ left, right expr }
+
+func (e orExpr) values(root, node interface{}) []interface{} {
+	return []interface{}{e.test(root, node)}
+}
+func (e orExpr) test(root, node interface{}) bool {
+	return e.left.test(root, node) || e.right.test(root, node)
+}
+
+// comparison compares two operands. When an operand selects several values
+// it holds if any pair of them compares true.
+type comparison struct {
+	op          string
+	left, right expr
+	re          *regexp.Regexp
+}
+
+func (c comparison) values(root, node interface{}) []interface{} {
+	return []interface{}{c.test(root, node)}
+}
+
+func (c comparison) test(root, node interface{}) bool {
+	left := c.left.values(root, node)
+	if c.re != nil {
+		for _, l := range left {
+			if s, ok := l.(string); ok && c.re.MatchString(s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	right := c.right.values(root, node)
+	if c.op == "!=" {
+		// Not equal to everything, so a missing key is != any value
+		for _, l := range left {
+			for _, r := range right {
+				if equal(l, r) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	for _, l := range left {
+		for _, r := range right {
+			if compare(c.op, l, r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func compare(op string, a, b interface{}) bool {
+	if op == "==" {
+		return equal(a, b)
+	}
+	var cmp int
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		if !ok {
+			return false
+		}
+		cmp = compareOrdered(x, y)
+	} else if x, ok := a.(string); ok {
+		y, ok := b.(string)
+		if !ok {
+			return false
+		}
+		cmp = compareOrdered(x, y)
+	} else {
+		return false
+	}
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default: // >=
+		return cmp >= 0
+	}
+}
+
+func compareOrdered[T float64 | string](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// equal compares decoded JSON values, numbers by value
+func equal(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func truthy(v interface{}) bool {
+	return v != nil && v != false
+}
@@ -0,0 +1,554 @@
+// Package query selects parts of command output with JSONPath or jq-style
+// expressions, such as $[?(@.state == "on")].entity_id or
+// .[] | select(.state == "on") | {entity_id, name}.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed expression. It is a pipeline of stages separated by |,
+// each a path, a select(...) filter or a {...} projection.
+type Query struct {
+	src    string
+	stages []stage
+}
+
+// String returns the source of the query
+func (q *Query) String() string {
+	return q.src
+}
+
+// Singular reports whether the query selects at most one value: it has no
+// wildcards, slices, filters or recursive descent
+func (q *Query) Singular() bool {
+	for _, s := range q.stages {
+		if !s.singular() {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse parses a query
+func Parse(src string) (*Query, error) {
+	p := &parser{src: src}
+	q := &Query{src: src}
+	for {
+		s, err := p.parseStage()
+		if err != nil {
+			return nil, err
+		}
+		q.stages = append(q.stages, s)
+		p.skipSpace()
+		if !p.consume("|") {
+			break
+		}
+	}
+	if p.skipSpace(); p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return q, nil
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("position %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+func (p *parser) consume(s string) bool {
+	if p.hasPrefix(s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	p.skipSpace()
+	if !p.consume(s) {
+		if p.pos >= len(p.src) {
+			return p.errorf("expected %q, got end of query", s)
+		}
+		return p.errorf("expected %q", s)
+	}
+	return nil
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func (p *parser) parseName() string {
+	start := p.pos
+	for p.pos < len(p.src) && isNameChar(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// parseStage parses one stage of a pipeline
+func (p *parser) parseStage() (stage, error) {
+	p.skipSpace()
+	switch {
+	case p.consume("select("):
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &selectStage{expr: e}, nil
+	case p.peek() == '{':
+		return p.parseProjection()
+	}
+	return p.parsePath(true)
+}
+
+// parsePath parses a path. It starts at the root with $, at the current
+// value with @ or a leading dot, or, at the top level, with a bare name.
+func (p *parser) parsePath(topLevel bool) (*path, error) {
+	p.skipSpace()
+	start := p.pos
+	pa := &path{}
+	switch {
+	case p.consume("$"):
+		pa.fromRoot = true
+	case p.consume("@"):
+	case p.peek() == '.' || p.peek() == '[':
+	case topLevel && isNameChar(p.peek()):
+		pa.segments = append(pa.segments, childSegment{names: []string{p.parseName()}})
+	default:
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("expected a path, got end of query")
+		}
+		return nil, p.errorf("expected a path")
+	}
+
+	for {
+		switch {
+		case p.consume(".."):
+			seg, err := p.parseAfterDot()
+			if err != nil {
+				return nil, err
+			}
+			if seg == nil {
+				return nil, p.errorf("expected a name, * or [ after ..")
+			}
+			pa.segments = append(pa.segments, descendantSegment{}, seg)
+		case p.consume("."):
+			seg, err := p.parseAfterDot()
+			if err != nil {
+				return nil, err
+			}
+			if seg == nil {
+				// A lone "." is the current value, as in jq
+				if p.pos-1 != start {
+					return nil, p.errorf("expected a name, * or [ after .")
+				}
+				continue
+			}
+			pa.segments = append(pa.segments, seg)
+		case p.peek() == '[':
+			seg, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			pa.segments = append(pa.segments, seg)
+		default:
+			return pa, nil
+		}
+	}
+}
+
+// parseAfterDot parses the name, * or bracket following . or .., or returns
+// nil if there is none
+func (p *parser) parseAfterDot() (segment, error) {
+	switch {
+	case p.consume("*"):
+		return wildcardSegment{}, nil
+	case p.peek() == '[':
+		return p.parseBracket()
+	case isNameChar(p.peek()):
+		return childSegment{names: []string{p.parseName()}}, nil
+	}
+	return nil, nil
+}
+
+// parseBracket parses [*], [] (jq), [?filter], ['name', ...], [1, 2] and
+// [start:end:step]
+func (p *parser) parseBracket() (segment, error) {
+	p.consume("[")
+	p.skipSpace()
+	switch {
+	case p.consume("]"):
+		return wildcardSegment{}, nil
+	case p.consume("*"):
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return wildcardSegment{}, nil
+	case p.consume("?"):
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return filterSegment{expr: e}, nil
+	case p.peek() == '\'' || p.peek() == '"':
+		var names []string
+		for {
+			p.skipSpace()
+			name, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+			p.skipSpace()
+			if !p.consume(",") {
+				break
+			}
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return childSegment{names: names}, nil
+	}
+
+	// Indexes or a slice
+	first, hasFirst, err := p.parseOptionalInt()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.consume(":") {
+		s := sliceSegment{}
+		if hasFirst {
+			s.start = &first
+		}
+		if end, ok, err := p.parseOptionalInt(); err != nil {
+			return nil, err
+		} else if ok {
+			s.end = &end
+		}
+		p.skipSpace()
+		if p.consume(":") {
+			if step, ok, err := p.parseOptionalInt(); err != nil {
+				return nil, err
+			} else if ok {
+				s.step = step
+			}
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	if !hasFirst {
+		return nil, p.errorf("expected an index, slice, name, * or ? filter")
+	}
+	indexes := []int{first}
+	for p.skipSpace(); p.consume(","); p.skipSpace() {
+		i, ok, err := p.parseOptionalInt()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, p.errorf("expected an index")
+		}
+		indexes = append(indexes, i)
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return indexSegment{indexes: indexes}, nil
+}
+
+func (p *parser) parseOptionalInt() (int, bool, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, false, nil
+	}
+	text := p.src[start:p.pos]
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		p.pos = start
+		return 0, false, p.errorf("invalid index %q", text)
+	}
+	return n, true, nil
+}
+
+// parseString parses a single or double quoted string
+func (p *parser) parseString() (string, error) {
+	quote := p.peek()
+	if quote != '\'' && quote != '"' {
+		return "", p.errorf("expected a quoted string")
+	}
+	start := p.pos
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			switch e := p.src[p.pos]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+		p.pos++
+	}
+	p.pos = start
+	return "", p.errorf("unterminated string")
+}
+
+// parseProjection parses {key, key: path, "key": path}
+func (p *parser) parseProjection() (stage, error) {
+	p.consume("{")
+	proj := &projectStage{}
+	for {
+		p.skipSpace()
+		var key string
+		if p.peek() == '\'' || p.peek() == '"' {
+			var err error
+			if key, err = p.parseString(); err != nil {
+				return nil, err
+			}
+		} else if key = p.parseName(); key == "" {
+			return nil, p.errorf("expected a key")
+		}
+
+		field := projectField{key: key, path: &path{segments: []segment{childSegment{names: []string{key}}}}}
+		p.skipSpace()
+		if p.consume(":") {
+			pa, err := p.parsePath(false)
+			if err != nil {
+				return nil, err
+			}
+			field.path = pa
+		}
+		proj.fields = append(proj.fields, field)
+
+		p.skipSpace()
+		if p.consume("}") {
+			return proj, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected \",\" or \"}\"")
+		}
+	}
+}
+
+// parseExpr parses a filter expression: comparisons of paths and literals
+// combined with &&, || and !
+func (p *parser) parseExpr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); p.consume("||"); p.skipSpace() {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); p.consume("&&"); p.skipSpace() {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	p.skipSpace()
+	if p.hasPrefix("!") && !p.hasPrefix("!=") {
+		p.pos++
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{e}, nil
+	}
+	return p.parseComparison()
+}
+
+var comparisonOps = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range comparisonOps {
+		if !p.consume(op) {
+			continue
+		}
+		p.skipSpace()
+		opPos := p.pos
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		c := comparison{op: op, left: left, right: right}
+		if op == "=~" {
+			lit, ok := right.(literal)
+			pattern, isString := lit.value.(string)
+			if !ok || !isString {
+				p.pos = opPos
+				return nil, p.errorf("=~ needs a /regex/ or string pattern")
+			}
+			if c.re, err = regexp.Compile(pattern); err != nil {
+				p.pos = opPos
+				return nil, p.errorf("invalid regex: %v", err)
+			}
+		}
+		return c, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseOperand() (expr, error) {
+	p.skipSpace()
+	c := p.peek()
+	switch {
+	case c == '(':
+		p.pos++
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literal{s}, nil
+	case c == '/':
+		return p.parseRegex()
+	case c == '-' || c >= '0' && c <= '9':
+		return p.parseNumber()
+	case c == '@' || c == '$' || c == '.':
+		pa, err := p.parsePath(false)
+		if err != nil {
+			return nil, err
+		}
+		return pathExpr{pa}, nil
+	}
+	for word, value := range map[string]interface{}{"true": true, "false": false, "null": nil} {
+		if p.hasPrefix(word) && !isNameChar(p.peekAt(len(word))) {
+			p.pos += len(word)
+			return literal{value}, nil
+		}
+	}
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("expected a value, got end of query")
+	}
+	return nil, p.errorf("expected a path, string, number, true, false or null")
+}
+
+func (p *parser) peekAt(offset int) byte {
+	if p.pos+offset < len(p.src) {
+		return p.src[p.pos+offset]
+	}
+	return 0
+}
+
+func (p *parser) parseNumber() (expr, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	n, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	return literal{n}, nil
+}
+
+// parseRegex parses /pattern/ with an optional i flag
+func (p *parser) parseRegex() (expr, error) {
+	start := p.pos
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.src) && p.src[p.pos] != '/' {
+		if p.src[p.pos] == '\\' && p.peekAt(1) == '/' {
+			p.pos++
+		}
+		b.WriteByte(p.src[p.pos])
+		p.pos++
+	}
+	if !p.consume("/") {
+		p.pos = start
+		return nil, p.errorf("unterminated regex")
+	}
+	pattern := b.String()
+	if p.consume("i") {
+		pattern = "(?i)" + pattern
+	}
+	return literal{pattern}, nil
+}
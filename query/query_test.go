@@ -0,0 +1,191 @@
+package query
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDoc = `{
+	"entities": [
+		{"entity_id": "light.a", "state": "on", "attributes": {"brightness": 255}},
+		{"entity_id": "light.b", "state": "off", "attributes": {"brightness": 0}},
+		{"entity_id": "sensor.t", "state": "21.5", "attributes": {"unit": "°C"}}
+	],
+	"nums": [0, 1, 2, 3, 4, 5]
+}`
+
+func TestEval(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(testDoc), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		// Indexes and slices
+		{query: "$.nums[-1]", want: `[5]`},
+		{query: "$.nums[0, -1, 9]", want: `[0, 5]`},
+		{query: "$.nums[1:3]", want: `[1, 2]`},
+		{query: "$.nums[-2:]", want: `[4, 5]`},
+		{query: "$.nums[:-4]", want: `[0, 1]`},
+		{query: "$.nums[::2]", want: `[0, 2, 4]`},
+		{query: "$.nums[10:]", want: `[]`},
+		{query: "$.nums[3:1]", want: `[]`},
+		{query: "$.nums[::-1]", want: `[5, 4, 3, 2, 1, 0]`},
+		{query: "$.nums[4:1:-1]", want: `[4, 3, 2]`},
+		{query: "$.nums[-1::-2]", want: `[5, 3, 1]`},
+		{query: "$.nums[100:-100:-1]", want: `[5, 4, 3, 2, 1, 0]`},
+		{query: "$.nums[1:4:-1]", want: `[]`},
+		{query: "$.nums[1:2].missing", want: `[]`},
+
+		// Names, wildcards and descendants
+		{query: "$.entities[*].entity_id", want: `["light.a", "light.b", "sensor.t"]`},
+		{query: ".entities[].entity_id", want: `["light.a", "light.b", "sensor.t"]`},
+		{query: "entities[0].state", want: `["on"]`},
+		{query: "$.entities[0]['entity_id', \"state\"]", want: `["light.a", "on"]`},
+		{query: "$..brightness", want: `[255, 0]`},
+		{query: "$.entities[0].attributes.*", want: `[255]`},
+		{query: "$.missing.key", want: `[]`},
+
+		// Filters
+		{query: "$.entities[?(@.state == 'on')].entity_id", want: `["light.a"]`},
+		{query: "$.entities[?(@.attributes.brightness > 100 || @.state =~ /^2/)].entity_id", want: `["light.a", "sensor.t"]`},
+		{query: "$.entities[?(@.entity_id =~ /LIGHT/i && @.state != 'on')].entity_id", want: `["light.b"]`},
+		{query: "$.entities[?(!@.attributes.brightness)].entity_id", want: `["sensor.t"]`},
+		{query: "$.entities[?(@.missing != 'x')].entity_id", want: `["light.a", "light.b", "sensor.t"]`},
+		{query: "$.entities[?(@.attributes.unit == \"°C\")].state", want: `["21.5"]`},
+		{query: "$.entities[?(@.attributes.brightness <= 0)].entity_id", want: `["light.b"]`},
+		{query: "$.entities[?(@.state >= 'on')].state", want: `["on"]`},
+		{query: "$.entities[?(@.entity_id == $.entities[2].entity_id)].state", want: `["21.5"]`},
+		{query: "$.entities[0].attributes[?(@ > 100)]", want: `[255]`},
+		{query: "$.nums[?(@ > 1 && (@ < 3 || @ == 5))]", want: `[2, 5]`},
+
+		// jq-style pipelines
+		{query: ".entities[] | select(.state == \"on\") | {entity_id, b: .attributes.brightness}", want: `[{"entity_id": "light.a", "b": 255}]`},
+		{query: "{ids: .entities[*].entity_id, first: .nums[0], none: .missing}", want: `[{"ids": ["light.a", "light.b", "sensor.t"], "first": 0, "none": null}]`},
+		{query: ".nums[] | select(. > 3)", want: `[4, 5]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results := q.Eval(doc)
+			if results == nil {
+				results = []interface{}{}
+			}
+			var want interface{}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(results, want) {
+				got, _ := json.Marshal(results)
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{query: "", wantErr: "position 1: expected a path, got end of query"},
+		{query: "$.a b", wantErr: `position 5: unexpected "b"`},
+		{query: "$.a.", wantErr: "expected a name, * or [ after ."},
+		{query: "$..", wantErr: "expected a name, * or [ after .."},
+		{query: "$.nums[", wantErr: "expected an index, slice, name, * or ? filter"},
+		{query: "$.nums[1", wantErr: `expected "]", got end of query`},
+		{query: "$.nums[-]", wantErr: `invalid index "-"`},
+		{query: "$.nums[1,]", wantErr: "expected an index"},
+		{query: "$.a['b]", wantErr: "unterminated string"},
+		{query: "$.a[?(@.x =~ 5)]", wantErr: "=~ needs a /regex/ or string pattern"},
+		{query: "$.a[?(@.x =~ /(/)]", wantErr: "invalid regex"},
+		{query: "$.a[?(@.x =~ /abc)]", wantErr: "unterminated regex"},
+		{query: "$.a[?(@.x == )]", wantErr: "expected a path, string, number, true, false or null"},
+		{query: "$.a[?(@.x == 1e)]", wantErr: "invalid number"},
+		{query: "select(.a", wantErr: `expected ")", got end of query`},
+		{query: "{a b}", wantErr: `expected "," or "}"`},
+		{query: "{}", wantErr: "expected a key"},
+		{query: ".a |", wantErr: "expected a path, got end of query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSingular(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "$.a.b", want: true},
+		{query: "a.b[0]", want: true},
+		{query: ".", want: true},
+		{query: ".a | {a, b: .c[*]}", want: true},
+		{query: "$['a']", want: true},
+		{query: "$['a', 'b']", want: false},
+		{query: "$.a[0, 1]", want: false},
+		{query: "$.a[*]", want: false},
+		{query: "$.a[]", want: false},
+		{query: "$.a[1:]", want: false},
+		{query: "$..a", want: false},
+		{query: "$.a[?(@.b)]", want: false},
+		{query: ".a | select(.b)", want: false},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if got := q.Singular(); got != tt.want {
+			t.Errorf("%s: Singular() = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	type item struct {
+		Name  string `json:"name"`
+		Items []int  `json:"items"`
+		Big   uint64 `json:"big"`
+	}
+	data := []item{{Name: "x", Items: []int{1, 7}, Big: 9007199254740993}}
+
+	tests := []struct {
+		query string
+		want  interface{}
+	}{
+		{query: "[0].name", want: "x"},
+		{query: "$[0].missing", want: nil},
+		// Numbers keep the digits they were written with
+		{query: "$[0].big", want: json.Number("9007199254740993")},
+		{query: "$[0].items[*]", want: []interface{}{json.Number("1"), json.Number("7")}},
+		{query: "$[0].items[?(@ > 5)]", want: []interface{}{json.Number("7")}},
+		{query: "$[0].items[?(@ > 10)]", want: []interface{}{}},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		got, err := q.Apply(data)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Apply() = %#v, want %#v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+// Package schema validates configurations offline against the JSON Schemas
+// bundled with hab, before they are sent to Home Assistant.
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Kinds of configuration with a bundled schema
+const (
+	Automation = "automation"
+	Script     = "script"
+	Trigger    = "trigger"
+	Condition  = "condition"
+	Action     = "action"
+	Dashboard  = "dashboard"
+	View       = "view"
+	Section    = "section"
+	Card       = "card"
+)
+
+//go:embed *.json
+var files embed.FS
+
+// baseURL is where the bundled schemas are registered, so their relative
+// $refs resolve to each other
+const baseURL = "hab://schema/"
+
+var (
+	compileOnce sync.Once
+	compiled    map[string]*jsonschema.Schema
+	compileErr  error
+)
+
+// Issue is one problem found in a configuration
+type Issue struct {
+	// Path locates the value, e.g. triggers[1].entity_id. It is empty for
+	// the configuration itself.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return i.Path + ": " + i.Message
+}
+
+// Error reports the issues of a configuration that is not valid
+type Error struct {
+	Kind   string
+	Issues []Issue
+}
+
+func (e *Error) Error() string {
+	if len(e.Issues) == 1 {
+		return fmt.Sprintf("invalid %s configuration: %s", e.Kind, e.Issues[0])
+	}
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		lines[i] = "  " + issue.String()
+	}
+	return fmt.Sprintf("invalid %s configuration:\n%s", e.Kind, strings.Join(lines, "\n"))
+}
+
+// Validate checks config, as decoded from JSON or YAML, against the schema
+// of kind. It returns an *Error listing every issue found.
+func Validate(kind string, config interface{}) error {
+	compileOnce.Do(compile)
+	if compileErr != nil {
+		return compileErr
+	}
+	s, ok := compiled[kind]
+	if !ok {
+		return fmt.Errorf("no schema for %s", kind)
+	}
+
+	err := s.Validate(config)
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+
+	issues := dedupe(collect(ve))
+	for i := range issues {
+		issues[i].Path = formatPath(config, issues[i].Path)
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return &Error{Kind: kind, Issues: issues}
+}
+
+// compile compiles the schema of every kind
+func compile() {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		compileErr = err
+		return
+	}
+	for _, entry := range entries {
+		data, err := files.ReadFile(entry.Name())
+		if err != nil {
+			compileErr = err
+			return
+		}
+		if err := compiler.AddResource(baseURL+entry.Name(), bytes.NewReader(data)); err != nil {
+			compileErr = fmt.Errorf("invalid bundled schema %s: %w", entry.Name(), err)
+			return
+		}
+	}
+
+	compiled = make(map[string]*jsonschema.Schema)
+	for _, kind := range []string{Automation, Script, Trigger, Condition, Action, Dashboard, View, Section, Card} {
+		s, err := compiler.Compile(baseURL + kind + ".json")
+		if err != nil {
+			compileErr = fmt.Errorf("invalid bundled schema %s: %w", kind, err)
+			return
+		}
+		compiled[kind] = s
+	}
+}
+
+// issue is an Issue while the validation error is walked. Path is still a
+// JSON pointer, and the details of type and required errors are kept so
+// that alternatives can be combined.
+type issue struct {
+	Issue
+	expected []string // of a type error
+	got      string   // of a type error
+	missing  []string // of a required error
+}
+
+// collect turns the tree of a validation error into issues
+func collect(ve *jsonschema.ValidationError) []issue {
+	if len(ve.Causes) == 0 {
+		return describe(ve)
+	}
+	if keyword := lastSegment(ve.KeywordLocation); keyword == "anyOf" || keyword == "oneOf" {
+		return alternatives(ve)
+	}
+	var issues []issue
+	for _, cause := range ve.Causes {
+		issues = append(issues, collect(cause)...)
+	}
+	return issues
+}
+
+// alternatives reports a value that matches none of the schemas of anyOf or
+// oneOf. Listing why each alternative failed is rarely helpful: if one got
+// further into the value than the others, its issues are reported, otherwise
+// the alternatives are summarised.
+func alternatives(ve *jsonschema.ValidationError) []issue {
+	var best []issue
+	bestDepth := -1
+	var expected, missing []string
+	got := ""
+	allType, allRequired := true, true
+
+	for _, cause := range ve.Causes {
+		issues := collect(cause)
+		deepest := 0
+		for _, i := range issues {
+			if d := depth(i.Path); d > deepest {
+				deepest = d
+			}
+			allType = allType && i.expected != nil
+			allRequired = allRequired && i.missing != nil
+			expected = append(expected, i.expected...)
+			missing = append(missing, i.missing...)
+			if i.got != "" {
+				got = i.got
+			}
+		}
+		if deepest > bestDepth || (deepest == bestDepth && len(issues) < len(best)) {
+			best, bestDepth = issues, deepest
+		}
+	}
+
+	if bestDepth > depth(ve.InstanceLocation) {
+		return best
+	}
+	switch {
+	case allType:
+		expected = unique(expected)
+		return []issue{{
+			Issue:    Issue{Path: ve.InstanceLocation, Message: fmt.Sprintf("expected %s, got %s", joinOr(expected), got)},
+			expected: expected,
+			got:      got,
+		}}
+	case allRequired:
+		missing = unique(missing)
+		return []issue{{
+			Issue:   Issue{Path: ve.InstanceLocation, Message: "missing one of the keys " + strings.Join(quoteAll(missing), ", ")},
+			missing: missing,
+		}}
+	}
+	return best
+}
+
+// describe turns a validation error without causes into issues, in the
+// terms of Home Assistant configurations
+func describe(ve *jsonschema.ValidationError) []issue {
+	msg := ve.Message
+	switch lastSegment(ve.KeywordLocation) {
+	case "type":
+		// expected string or array, but got number
+		want, got, ok := strings.Cut(strings.TrimPrefix(msg, "expected "), ", but got ")
+		if !ok {
+			break
+		}
+		expected := strings.Split(want, " or ")
+		for i, t := range expected {
+			expected[i] = typeName(t)
+		}
+		return []issue{{
+			Issue:    Issue{Path: ve.InstanceLocation, Message: fmt.Sprintf("expected %s, got %s", joinOr(expected), typeName(got))},
+			expected: expected,
+			got:      typeName(got),
+		}}
+	case "required":
+		// missing properties: 'a', 'b'
+		missing := splitQuoted(strings.TrimPrefix(msg, "missing properties: "))
+		msg := "missing required key " + strings.Join(quoteAll(missing), ", ")
+		if len(missing) > 1 {
+			msg = "missing required keys " + strings.Join(quoteAll(missing), ", ")
+		}
+		return []issue{{Issue: Issue{Path: ve.InstanceLocation, Message: msg}, missing: missing}}
+	case "additionalProperties":
+		// additionalProperties 'a', 'b' not allowed
+		names := splitQuoted(strings.TrimSuffix(strings.TrimPrefix(msg, "additionalProperties "), " not allowed"))
+		issues := make([]issue, len(names))
+		for i, name := range names {
+			issues[i] = issue{Issue: Issue{Path: ve.InstanceLocation + "/" + escapePointer(name), Message: "unknown key"}}
+		}
+		return issues
+	case "minLength":
+		if strings.HasPrefix(msg, "length must be >= 1") {
+			msg = "must not be empty"
+		}
+	}
+	return []issue{{Issue: Issue{Path: ve.InstanceLocation, Message: msg}}}
+}
+
+// typeName returns the name Home Assistant uses for a JSON type
+func typeName(t string) string {
+	switch t {
+	case "array":
+		return "list"
+	case "object":
+		return "mapping"
+	}
+	return t
+}
+
+// formatPath turns the JSON pointer of a value in config into a path such as
+// triggers[1].entity_id
+func formatPath(config interface{}, pointer string) string {
+	if pointer == "" {
+		return ""
+	}
+	var b strings.Builder
+	v := config
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch value := v.(type) {
+		case []interface{}:
+			b.WriteString("[" + token + "]")
+			if i, err := strconv.Atoi(token); err == nil && i < len(value) {
+				v = value[i]
+			} else {
+				v = nil
+			}
+		default:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(token)
+			m, _ := value.(map[string]interface{})
+			v = m[token]
+		}
+	}
+	return b.String()
+}
+
+// dedupe removes issues reported more than once, e.g. by two alternatives
+func dedupe(issues []issue) []Issue {
+	seen := make(map[Issue]bool)
+	var result []Issue
+	for _, i := range issues {
+		if !seen[i.Issue] {
+			seen[i.Issue] = true
+			result = append(result, i.Issue)
+		}
+	}
+	return result
+}
+
+// depth returns the number of tokens of a JSON pointer
+func depth(pointer string) int {
+	return strings.Count(pointer, "/")
+}
+
+// lastSegment returns the last token of a JSON pointer
+func lastSegment(pointer string) string {
+	return pointer[strings.LastIndex(pointer, "/")+1:]
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// splitQuoted splits a list of the form 'a', 'b' into its names
+func splitQuoted(list string) []string {
+	parts := strings.Split(list, ", ")
+	for i, p := range parts {
+		parts[i] = strings.Trim(p, "'")
+	}
+	return parts
+}
+
+func quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = "'" + n + "'"
+	}
+	return quoted
+}
+
+// joinOr joins a, b and c as "a, b or c"
+func joinOr(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
+
+func unique(items []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}